@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"app/src/service"
+	"app/src/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CalendarEventController struct {
+	CalendarEventService service.CalendarEventService
+}
+
+func NewCalendarEventController(calendarEventService service.CalendarEventService) *CalendarEventController {
+	return &CalendarEventController{
+		CalendarEventService: calendarEventService,
+	}
+}
+
+func (e *CalendarEventController) GetAll(c *fiber.Ctx) error {
+	params := new(validation.QueryCalendarEvent)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	events, total, err := e.CalendarEventService.GetEvents(c, params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Calendar events retrieved successfully",
+		"data":    events,
+		"total":   total,
+	})
+}
+
+func (e *CalendarEventController) Create(c *fiber.Ctx) error {
+	var req validation.CreateCalendarEvent
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	event, err := e.CalendarEventService.CreateEvent(c, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Calendar event created successfully",
+		"data":    event,
+	})
+}
+
+func (e *CalendarEventController) Update(c *fiber.Ctx) error {
+	var req validation.UpdateCalendarEvent
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	event, err := e.CalendarEventService.UpdateEvent(c, c.Params("id"), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Calendar event updated successfully",
+		"data":    event,
+	})
+}
+
+func (e *CalendarEventController) Delete(c *fiber.Ctx) error {
+	if err := e.CalendarEventService.DeleteEvent(c, c.Params("id")); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Calendar event deleted successfully",
+	})
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"app/src/service"
+	"app/src/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CookRecordController struct {
+	CookRecordService service.CookRecordService
+}
+
+func NewCookRecordController(cookRecordService service.CookRecordService) *CookRecordController {
+	return &CookRecordController{
+		CookRecordService: cookRecordService,
+	}
+}
+
+func (r *CookRecordController) GetAll(c *fiber.Ctx) error {
+	params := new(validation.QueryCookRecord)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	records, total, err := r.CookRecordService.GetCookRecords(c, params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Cook history retrieved successfully",
+		"data":    records,
+		"total":   total,
+	})
+}
+
+func (r *CookRecordController) GetByID(c *fiber.Ctx) error {
+	record, err := r.CookRecordService.GetCookRecordByID(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Cook record retrieved successfully",
+		"data":    record,
+	})
+}
+
+func (r *CookRecordController) Reverse(c *fiber.Ctx) error {
+	var req validation.ReverseCookRecord
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	record, err := r.CookRecordService.ReverseCookRecord(c, c.Params("id"), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Cook reversed successfully",
+		"data":    record,
+	})
+}
@@ -0,0 +1,212 @@
+package controller
+
+import (
+	"time"
+
+	"app/src/response"
+	"app/src/service"
+	"app/src/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ForecastController struct {
+	ForecastService service.ForecastService
+}
+
+func NewForecastController(forecastService service.ForecastService) *ForecastController {
+	return &ForecastController{
+		ForecastService: forecastService,
+	}
+}
+
+func (f *ForecastController) CreateRun(c *fiber.Ctx) error {
+	var req validation.CreateForecastRun
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	run, err := f.ForecastService.CreateRun(c, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Forecast run created successfully",
+		"data":    run,
+	})
+}
+
+func (f *ForecastController) GetRuns(c *fiber.Ctx) error {
+	params := new(validation.QueryForecastRun)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	runs, total, err := f.ForecastService.GetRuns(c, params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Forecast runs retrieved successfully",
+		"data":    runs,
+		"total":   total,
+	})
+}
+
+func (f *ForecastController) GetRunByID(c *fiber.Ctx) error {
+	run, err := f.ForecastService.GetRunByID(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Forecast run retrieved successfully",
+		"data":    run,
+	})
+}
+
+func (f *ForecastController) GetProcessed(c *fiber.Ctx) error {
+	params := new(validation.QueryProcessedForecast)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	data, err := f.ForecastService.GetProcessed(c, params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.ForecastProcessed{
+		Code:        fiber.StatusOK,
+		Status:      "success",
+		Message:     "Forecast retrieved successfully",
+		Data:        data,
+		RetrievedAt: time.Now(),
+	})
+}
+
+func (f *ForecastController) GetRunAccuracy(c *fiber.Ctx) error {
+	report, err := f.ForecastService.GetRunAccuracy(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Forecast accuracy retrieved successfully",
+		"data":    report,
+	})
+}
+
+func (f *ForecastController) GetAccuracy(c *fiber.Ctx) error {
+	params := new(validation.QueryForecastAccuracy)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	report, err := f.ForecastService.GetAccuracy(c, params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Forecast accuracy retrieved successfully",
+		"data":    report,
+	})
+}
+
+func (f *ForecastController) Backtest(c *fiber.Ctx) error {
+	var req validation.ForecastBacktest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	report, err := f.ForecastService.Backtest(c, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Back-test completed successfully",
+		"data":    report,
+	})
+}
+
+func (f *ForecastController) SetOverride(c *fiber.Ctx) error {
+	var req validation.SetForecastOverride
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	override, err := f.ForecastService.SetOverride(c, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Forecast override saved successfully",
+		"data":    override,
+	})
+}
+
+func (f *ForecastController) GetOverrides(c *fiber.Ctx) error {
+	params := new(validation.QueryForecastOverride)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	overrides, total, err := f.ForecastService.GetOverrides(c, params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Forecast overrides retrieved successfully",
+		"data":    overrides,
+		"total":   total,
+	})
+}
+
+func (f *ForecastController) RemoveOverride(c *fiber.Ctx) error {
+	params := new(validation.RemoveForecastOverride)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	if err := f.ForecastService.RemoveOverride(c, c.Params("id"), params); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Forecast override removed successfully",
+	})
+}
+
+func (f *ForecastController) GetOverrideAudits(c *fiber.Ctx) error {
+	params := new(validation.QueryForecastOverride)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	audits, total, err := f.ForecastService.GetOverrideAudits(c, params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Forecast override history retrieved successfully",
+		"data":    audits,
+		"total":   total,
+	})
+}
@@ -1,6 +1,8 @@
 package controller
 
 import (
+	"errors"
+
 	"app/src/service"
 	"app/src/validation"
 
@@ -97,7 +99,24 @@ func (i *ItemController) Delete(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "ID is required")
 	}
 
-	if err := i.ItemService.DeleteItem(c, id); err != nil {
+	params := new(validation.DeleteItem)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	if err := i.ItemService.DeleteItem(c, id, params); err != nil {
+		var inUse *service.ItemInUseError
+		if errors.As(err, &inUse) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message": err.Error(),
+				"data":    inUse.Usages,
+			})
+		}
+
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			return err
+		}
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 
@@ -131,4 +150,16 @@ func (i *ItemController) ImportCSV(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{
 		"message": "Items imported successfully",
 	})
-}
\ No newline at end of file
+}
+
+func (i *ItemController) GetWhereUsed(c *fiber.Ctx) error {
+	usages, err := i.ItemService.GetWhereUsed(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Item usage retrieved successfully",
+		"data":    usages,
+	})
+}
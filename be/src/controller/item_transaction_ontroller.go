@@ -26,7 +26,11 @@ func (t *ItemTransactionController) CreateTransaction(ctx *fiber.Ctx) error {
 
 	transaction, err := t.ItemTransactionService.CreateTransaction(ctx, req)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		statusCode := fiber.StatusInternalServerError
+		if e, ok := err.(*fiber.Error); ok {
+			statusCode = e.Code
+		}
+		return fiber.NewError(statusCode, err.Error())
 	}
 
 	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
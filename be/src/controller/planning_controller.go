@@ -0,0 +1,36 @@
+package controller
+
+import (
+	"app/src/service"
+	"app/src/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PlanningController struct {
+	PlanningService service.PlanningService
+}
+
+func NewPlanningController(planningService service.PlanningService) *PlanningController {
+	return &PlanningController{
+		PlanningService: planningService,
+	}
+}
+
+func (p *PlanningController) Requirements(c *fiber.Ctx) error {
+	params := new(validation.QueryRequirementsPlan)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	plan, err := p.PlanningService.GetRequirements(c, params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Ingredient requirements retrieved successfully",
+		"data":    plan,
+	})
+}
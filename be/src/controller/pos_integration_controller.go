@@ -0,0 +1,175 @@
+package controller
+
+import (
+	"app/src/service"
+	"app/src/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PosIntegrationController struct {
+	PosIntegrationService service.PosIntegrationService
+	PosWebhookService     service.PosWebhookService
+}
+
+func NewPosIntegrationController(posIntegrationService service.PosIntegrationService, posWebhookService service.PosWebhookService) *PosIntegrationController {
+	return &PosIntegrationController{
+		PosIntegrationService: posIntegrationService,
+		PosWebhookService:     posWebhookService,
+	}
+}
+
+func (p *PosIntegrationController) Create(c *fiber.Ctx) error {
+	var req validation.CreatePosIntegration
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	integration, secret, err := p.PosIntegrationService.CreateIntegration(c, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "POS integration created successfully, store the secret now: it is not shown again",
+		"data":    integration,
+		"secret":  secret,
+	})
+}
+
+func (p *PosIntegrationController) GetAll(c *fiber.Ctx) error {
+	params := new(validation.QueryPosIntegration)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	integrations, err := p.PosIntegrationService.GetIntegrations(c, params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "POS integrations retrieved successfully",
+		"data":    integrations,
+	})
+}
+
+func (p *PosIntegrationController) Update(c *fiber.Ctx) error {
+	var req validation.UpdatePosIntegration
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	integration, err := p.PosIntegrationService.UpdateIntegration(c, c.Params("id"), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "POS integration updated successfully",
+		"data":    integration,
+	})
+}
+
+func (p *PosIntegrationController) RotateSecret(c *fiber.Ctx) error {
+	integration, secret, err := p.PosIntegrationService.RotateSecret(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "POS integration secret rotated successfully, store the secret now: it is not shown again",
+		"data":    integration,
+		"secret":  secret,
+	})
+}
+
+// Receive accepts a sale pushed by a POS. New events are recorded
+// asynchronously and answered with 202; known events with their status.
+func (p *PosIntegrationController) Receive(c *fiber.Ctx) error {
+	event, duplicate, err := p.PosWebhookService.ReceiveEvent(c, c.Params("integrationId"))
+	if err != nil {
+		return err
+	}
+
+	status, message := fiber.StatusAccepted, "Event accepted"
+	if duplicate {
+		status, message = fiber.StatusOK, "Event already received"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"success":   true,
+		"message":   message,
+		"duplicate": duplicate,
+		"data":      event,
+	})
+}
+
+func (p *PosIntegrationController) GetEvents(c *fiber.Ctx) error {
+	params := new(validation.QueryPosWebhookEvent)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	events, total, err := p.PosWebhookService.GetEvents(c, c.Params("integrationId"), params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Events retrieved successfully",
+		"data":    events,
+		"total":   total,
+	})
+}
+
+func (p *PosIntegrationController) GetEvent(c *fiber.Ctx) error {
+	event, err := p.PosWebhookService.GetEvent(c, c.Params("integrationId"), c.Params("eventId"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Event retrieved successfully",
+		"data":    event,
+	})
+}
+
+// GetIntegrationEvents lists deliveries for signed-in users.
+func (p *PosIntegrationController) GetIntegrationEvents(c *fiber.Ctx) error {
+	params := new(validation.QueryPosWebhookEvent)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	events, total, err := p.PosWebhookService.GetIntegrationEvents(c, c.Params("id"), params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Events retrieved successfully",
+		"data":    events,
+		"total":   total,
+	})
+}
+
+func (p *PosIntegrationController) GetIntegrationEvent(c *fiber.Ctx) error {
+	event, err := p.PosWebhookService.GetIntegrationEvent(c, c.Params("id"), c.Params("eventId"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Event retrieved successfully",
+		"data":    event,
+	})
+}
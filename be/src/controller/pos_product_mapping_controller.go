@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"app/src/service"
+	"app/src/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PosProductMappingController struct {
+	PosProductMappingService service.PosProductMappingService
+}
+
+func NewPosProductMappingController(posProductMappingService service.PosProductMappingService) *PosProductMappingController {
+	return &PosProductMappingController{
+		PosProductMappingService: posProductMappingService,
+	}
+}
+
+func (p *PosProductMappingController) GetAll(c *fiber.Ctx) error {
+	params := new(validation.QueryPosProductMapping)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	mappings, total, err := p.PosProductMappingService.GetMappings(c, params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "POS product mappings retrieved successfully",
+		"data":    mappings,
+		"total":   total,
+	})
+}
+
+func (p *PosProductMappingController) Save(c *fiber.Ctx) error {
+	var req validation.SavePosProductMappings
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	mappings, err := p.PosProductMappingService.SaveMappings(c, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "POS product mappings saved successfully",
+		"data":    mappings,
+	})
+}
+
+func (p *PosProductMappingController) Delete(c *fiber.Ctx) error {
+	if err := p.PosProductMappingService.DeleteMapping(c, c.Params("id")); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "POS product mapping deleted successfully",
+	})
+}
@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"app/src/service"
+	"app/src/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ProductionOrderController struct {
+	ProductionOrderService service.ProductionOrderService
+}
+
+func NewProductionOrderController(productionOrderService service.ProductionOrderService) *ProductionOrderController {
+	return &ProductionOrderController{
+		ProductionOrderService: productionOrderService,
+	}
+}
+
+func (p *ProductionOrderController) Create(c *fiber.Ctx) error {
+	var req validation.CreateProductionOrder
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	order, err := p.ProductionOrderService.CreateProductionOrder(c, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Production order created successfully",
+		"data":    order,
+	})
+}
+
+func (p *ProductionOrderController) GetAll(c *fiber.Ctx) error {
+	params := new(validation.QueryProductionOrder)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	orders, total, err := p.ProductionOrderService.GetProductionOrders(c, params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Production orders retrieved successfully",
+		"data":    orders,
+		"total":   total,
+	})
+}
+
+func (p *ProductionOrderController) GetByID(c *fiber.Ctx) error {
+	order, err := p.ProductionOrderService.GetProductionOrderByID(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Production order retrieved successfully",
+		"data":    order,
+	})
+}
+
+func (p *ProductionOrderController) Release(c *fiber.Ctx) error {
+	order, err := p.ProductionOrderService.ReleaseProductionOrder(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Production order released successfully",
+		"data":    order,
+	})
+}
+
+func (p *ProductionOrderController) Complete(c *fiber.Ctx) error {
+	var req validation.CompleteProductionOrder
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	}
+
+	order, err := p.ProductionOrderService.CompleteProductionOrder(c, c.Params("id"), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Production order completed successfully",
+		"data":    order,
+	})
+}
+
+func (p *ProductionOrderController) Cancel(c *fiber.Ctx) error {
+	order, err := p.ProductionOrderService.CancelProductionOrder(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Production order cancelled successfully",
+		"data":    order,
+	})
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"app/src/service"
+	"app/src/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PurchaseOrderController struct {
+	PurchaseOrderService service.PurchaseOrderService
+}
+
+func NewPurchaseOrderController(purchaseOrderService service.PurchaseOrderService) *PurchaseOrderController {
+	return &PurchaseOrderController{
+		PurchaseOrderService: purchaseOrderService,
+	}
+}
+
+func (p *PurchaseOrderController) Create(c *fiber.Ctx) error {
+	var req validation.CreatePurchaseOrder
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	order, err := p.PurchaseOrderService.CreatePurchaseOrder(c, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Purchase order created successfully",
+		"data":    order,
+	})
+}
+
+func (p *PurchaseOrderController) GetAll(c *fiber.Ctx) error {
+	params := new(validation.QueryPurchaseOrder)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	orders, total, err := p.PurchaseOrderService.GetPurchaseOrders(c, params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Purchase orders retrieved successfully",
+		"data":    orders,
+		"total":   total,
+	})
+}
+
+func (p *PurchaseOrderController) GetByID(c *fiber.Ctx) error {
+	order, err := p.PurchaseOrderService.GetPurchaseOrderByID(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Purchase order retrieved successfully",
+		"data":    order,
+	})
+}
+
+func (p *PurchaseOrderController) Receive(c *fiber.Ctx) error {
+	var req validation.ReceivePurchaseOrder
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	}
+
+	order, err := p.PurchaseOrderService.ReceivePurchaseOrder(c, c.Params("id"), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Purchase order received successfully",
+		"data":    order,
+	})
+}
+
+func (p *PurchaseOrderController) Cancel(c *fiber.Ctx) error {
+	order, err := p.PurchaseOrderService.CancelPurchaseOrder(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Purchase order cancelled successfully",
+		"data":    order,
+	})
+}
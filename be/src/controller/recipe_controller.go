@@ -1,6 +1,8 @@
 package controller
 
 import (
+	"strings"
+
 	"app/src/service"
 	"app/src/validation"
 	
@@ -9,12 +11,26 @@ import (
 )
 
 type RecipeController struct {
-	RecipeService service.RecipeService
+	RecipeService           service.RecipeService
+	RecipeCostService       service.RecipeCostService
+	RecipeVersionService    service.RecipeVersionService
+	RecipeImportService     service.RecipeImportService
+	RecipeSubstituteService service.RecipeSubstituteService
 }
 
-func NewRecipeController(recipeService service.RecipeService) *RecipeController {
+func NewRecipeController(
+	recipeService service.RecipeService,
+	recipeCostService service.RecipeCostService,
+	recipeVersionService service.RecipeVersionService,
+	recipeImportService service.RecipeImportService,
+	recipeSubstituteService service.RecipeSubstituteService,
+) *RecipeController {
 	return &RecipeController{
-		RecipeService: recipeService,
+		RecipeService:           recipeService,
+		RecipeCostService:       recipeCostService,
+		RecipeVersionService:    recipeVersionService,
+		RecipeImportService:     recipeImportService,
+		RecipeSubstituteService: recipeSubstituteService,
 	}
 }
 
@@ -36,6 +52,24 @@ func (r *RecipeController) Create(c *fiber.Ctx) error {
 	})
 }
 
+func (r *RecipeController) Clone(c *fiber.Ctx) error {
+	var req validation.CloneRecipes
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	report, err := r.RecipeService.CloneRecipes(c, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Recipes cloned successfully",
+		"data":    report,
+	})
+}
+
 func (r *RecipeController) GetAll(c *fiber.Ctx) error {
 	params := new(validation.QueryRecipe)
 	// Parse query
@@ -129,4 +163,295 @@ func (r *RecipeController) Cook(c *fiber.Ctx) error {
 		"message": "Recipe cooked successfully",
 		"data":    result,
 	})
-}
\ No newline at end of file
+}
+
+func (r *RecipeController) CheckCook(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req validation.CookRecipe
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// validation errors are bad requests and service errors carry their own
+	// status; anything else is a server error
+	result, err := r.RecipeService.CheckCookRecipe(c, id, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Recipe cook check completed",
+		"data":    result,
+	})
+}
+
+func (r *RecipeController) GetMaxServings(c *fiber.Ctx) error {
+	params := new(validation.QueryMaxServings)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	results, err := r.RecipeService.GetMaxServings(c, params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Maximum servings calculated successfully",
+		"data":    results,
+	})
+}
+
+func (r *RecipeController) GetCost(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	params := new(validation.QueryRecipeCost)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	cost, err := r.RecipeCostService.GetRecipeCost(c, id, params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Recipe cost retrieved successfully",
+		"data":    cost,
+	})
+}
+
+func (r *RecipeController) SetPrice(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req validation.SetRecipePrice
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	cost, err := r.RecipeCostService.SetSellingPrice(c, id, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Recipe price updated successfully",
+		"data":    cost,
+	})
+}
+
+func (r *RecipeController) GetCostHistory(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	params := new(validation.QueryRecipeCostHistory)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	histories, err := r.RecipeCostService.GetCostHistory(c, id, params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Recipe cost history retrieved successfully",
+		"data":    histories,
+	})
+}
+
+func (r *RecipeController) GetVersions(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	versions, err := r.RecipeVersionService.GetVersions(c, id)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Recipe versions retrieved successfully",
+		"data":    versions,
+	})
+}
+
+func (r *RecipeController) GetVersion(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	version, err := c.ParamsInt("version")
+	if err != nil || version < 1 {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid version")
+	}
+
+	recipeVersion, err := r.RecipeVersionService.GetVersion(c, id, version)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Recipe version retrieved successfully",
+		"data":    recipeVersion,
+	})
+}
+
+func (r *RecipeController) DiffVersions(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	params := new(validation.QueryRecipeVersionDiff)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	diff, err := r.RecipeVersionService.DiffVersions(c, id, params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Recipe versions compared successfully",
+		"data":    diff,
+	})
+}
+
+func (r *RecipeController) RestoreVersion(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	version, err := c.ParamsInt("version")
+	if err != nil || version < 1 {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid version")
+	}
+
+	var req validation.RestoreRecipeVersion
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	}
+
+	recipe, err := r.RecipeVersionService.RestoreVersion(c, id, version, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Recipe version restored successfully",
+		"data":    recipe,
+	})
+}
+
+func (r *RecipeController) Export(c *fiber.Ctx) error {
+	params := new(validation.QueryRecipeExport)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	if params.Format == "csv" {
+		data, err := r.RecipeImportService.ExportRecipesCSV(c, params)
+		if err != nil {
+			return err
+		}
+
+		c.Set(fiber.HeaderContentType, "text/csv")
+		c.Set(fiber.HeaderContentDisposition, `attachment; filename="recipes.csv"`)
+		return c.Status(fiber.StatusOK).Send(data)
+	}
+
+	recipes, err := r.RecipeImportService.ExportRecipes(c, params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Recipes exported successfully",
+		"data":    recipes,
+	})
+}
+
+func (r *RecipeController) Import(c *fiber.Ctx) error {
+	params := new(validation.ImportRecipes)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "file is required")
+	}
+
+	if params.Format == "" {
+		params.Format = "csv"
+		if strings.HasSuffix(strings.ToLower(file.Filename), ".json") {
+			params.Format = "json"
+		}
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to open file")
+	}
+	defer f.Close()
+
+	report, err := r.RecipeImportService.ImportRecipes(c, params, f)
+	if err != nil {
+		return err
+	}
+
+	if len(report.Errors) > 0 && !report.DryRun {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Recipe import has errors, nothing was imported",
+			"data":    report,
+		})
+	}
+
+	message := "Recipes imported successfully"
+	if report.DryRun {
+		message = "Recipe import checked, nothing was imported"
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": message,
+		"data":    report,
+	})
+}
+
+func (r *RecipeController) GetSubstitutes(c *fiber.Ctx) error {
+	substitutes, err := r.RecipeSubstituteService.GetSubstitutes(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Recipe substitutes retrieved successfully",
+		"data":    substitutes,
+	})
+}
+
+func (r *RecipeController) SetSubstitutes(c *fiber.Ctx) error {
+	var req validation.SetRecipeSubstitutes
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	substitutes, err := r.RecipeSubstituteService.SetSubstitutes(c, c.Params("id"), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Recipe substitutes updated successfully",
+		"data":    substitutes,
+	})
+}
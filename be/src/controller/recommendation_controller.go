@@ -0,0 +1,38 @@
+package controller
+
+import (
+	"app/src/service"
+	"app/src/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type RecommendationController struct {
+	RecommendationService service.RecommendationService
+}
+
+func NewRecommendationController(recommendationService service.RecommendationService) *RecommendationController {
+	return &RecommendationController{
+		RecommendationService: recommendationService,
+	}
+}
+
+func (r *RecommendationController) Ingredients(c *fiber.Ctx) error {
+	var req validation.IngredientRecommendation
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	}
+
+	recommendation, err := r.RecommendationService.GetIngredientRecommendations(c, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Recommendations generated successfully",
+		"data":    recommendation,
+	})
+}
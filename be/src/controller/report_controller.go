@@ -0,0 +1,36 @@
+package controller
+
+import (
+	"app/src/service"
+	"app/src/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReportController struct {
+	VarianceReportService service.VarianceReportService
+}
+
+func NewReportController(varianceReportService service.VarianceReportService) *ReportController {
+	return &ReportController{
+		VarianceReportService: varianceReportService,
+	}
+}
+
+func (r *ReportController) UsageVariance(c *fiber.Ctx) error {
+	params := new(validation.QueryUsageVariance)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	report, err := r.VarianceReportService.GetUsageVariance(c, params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Usage variance report retrieved successfully",
+		"data":    report,
+	})
+}
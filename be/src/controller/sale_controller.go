@@ -0,0 +1,345 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"app/src/service"
+	"app/src/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SaleController struct {
+	SaleService       service.SaleService
+	SaleImportService service.SaleImportService
+}
+
+func NewSaleController(saleService service.SaleService, saleImportService service.SaleImportService) *SaleController {
+	return &SaleController{
+		SaleService:       saleService,
+		SaleImportService: saleImportService,
+	}
+}
+
+// Create accepts either a JSON array of sales lines or a single line.
+func (s *SaleController) Create(c *fiber.Ctx) error {
+	var req validation.CreateSales
+	if bytes.HasPrefix(bytes.TrimSpace(c.Body()), []byte("[")) {
+		if err := c.BodyParser(&req.Sales); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else {
+		var line validation.CreateSale
+		if err := c.BodyParser(&line); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		req.Sales = []validation.CreateSale{line}
+	}
+
+	sales, err := s.SaleService.CreateSales(c, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Sales created successfully",
+		"data":    sales,
+	})
+}
+
+func (s *SaleController) GetAll(c *fiber.Ctx) error {
+	params := new(validation.QuerySale)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	sales, total, err := s.SaleService.GetSales(c, params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Sales retrieved successfully",
+		"sales":   sales,
+		"total":   total,
+		"page":    params.Page,
+		"limit":   params.Limit,
+	})
+}
+
+func (s *SaleController) GetByID(c *fiber.Ctx) error {
+	sale, err := s.SaleService.GetSaleByID(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Sale retrieved successfully",
+		"data":    sale,
+	})
+}
+
+func (s *SaleController) GetByDateRange(c *fiber.Ctx) error {
+	params := new(validation.QuerySale)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	sales, err := s.SaleService.GetSalesByDateRange(c, params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Sales retrieved successfully",
+		"data":    sales,
+	})
+}
+
+func (s *SaleController) GetByRecipe(c *fiber.Ctx) error {
+	params := new(validation.QuerySale)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	sales, err := s.SaleService.GetSalesByRecipe(c, c.Params("recipeId"), params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Sales retrieved successfully",
+		"data":    sales,
+	})
+}
+
+func (s *SaleController) Update(c *fiber.Ctx) error {
+	var req validation.UpdateSale
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	sale, err := s.SaleService.UpdateSale(c, c.Params("id"), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Sale updated successfully",
+		"data":    sale,
+	})
+}
+
+func (s *SaleController) Void(c *fiber.Ctx) error {
+	var req validation.VoidSale
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	sale, err := s.SaleService.VoidSale(c, c.Params("id"), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Sale voided successfully",
+		"data":    sale,
+	})
+}
+
+func (s *SaleController) ImportQuinos(c *fiber.Ctx) error {
+	params := new(validation.ImportQuinosSales)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "file is required")
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to open file")
+	}
+	defer f.Close()
+
+	report, err := s.SaleImportService.ImportQuinos(c, params, file.Filename, f)
+	if err != nil {
+		return importError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": fmt.Sprintf("Imported %d sales lines from Quinos", report.Imported),
+		"data":    report,
+	})
+}
+
+func (s *SaleController) ImportIseller(c *fiber.Ctx) error {
+	params := new(validation.ImportIsellerSales)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "file is required")
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to open file")
+	}
+	defer f.Close()
+
+	report, err := s.SaleImportService.ImportIseller(c, params, file.Filename, f)
+	if err != nil {
+		return importError(c, err)
+	}
+
+	message := fmt.Sprintf("Imported %d sales lines from iSeller", report.Imported)
+	if report.Duplicates > 0 {
+		message = fmt.Sprintf("%s, %d lines were already imported", message, report.Duplicates)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": message,
+		"data":    report,
+	})
+}
+
+func (s *SaleController) ImportCSV(c *fiber.Ctx) error {
+	params := new(validation.ImportCSVSales)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+	if err := c.BodyParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "file is required")
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to open file")
+	}
+	defer f.Close()
+
+	report, err := s.SaleImportService.ImportCSV(c, params, file.Filename, f)
+	if err != nil {
+		return importError(c, err)
+	}
+
+	if len(report.Errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Sales import has errors, nothing was imported",
+			"data":    report,
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": fmt.Sprintf("Imported %d sales lines", report.Imported),
+		"data":    report,
+	})
+}
+
+func (s *SaleController) GetImportTemplates(c *fiber.Ctx) error {
+	params := new(validation.QuerySalesImportTemplate)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	templates, err := s.SaleImportService.GetTemplates(c, params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Import templates retrieved successfully",
+		"data":    templates,
+	})
+}
+
+func (s *SaleController) CreateImportTemplate(c *fiber.Ctx) error {
+	var req validation.SalesImportTemplate
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	template, err := s.SaleImportService.CreateTemplate(c, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Import template created successfully",
+		"data":    template,
+	})
+}
+
+func (s *SaleController) UpdateImportTemplate(c *fiber.Ctx) error {
+	var req validation.SalesImportTemplate
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	template, err := s.SaleImportService.UpdateTemplate(c, c.Params("id"), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Import template updated successfully",
+		"data":    template,
+	})
+}
+
+func (s *SaleController) DeleteImportTemplate(c *fiber.Ctx) error {
+	params := new(validation.QuerySalesImportTemplate)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	if err := s.SaleImportService.DeleteTemplate(c, c.Params("id"), params); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Import template deleted successfully",
+	})
+}
+
+// importError answers unmapped POS products with the codes, so the user can
+// map them or force the import.
+func importError(c *fiber.Ctx, err error) error {
+	var unmapped *service.UnmappedProductsError
+	if errors.As(err, &unmapped) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":                 false,
+			"message":                 err.Error(),
+			"not_found_product_codes": unmapped.Codes,
+		})
+	}
+
+	return err
+}
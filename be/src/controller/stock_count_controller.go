@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"app/src/service"
+	"app/src/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type StockCountController struct {
+	StockCountService service.StockCountService
+}
+
+func NewStockCountController(stockCountService service.StockCountService) *StockCountController {
+	return &StockCountController{
+		StockCountService: stockCountService,
+	}
+}
+
+func (s *StockCountController) Create(c *fiber.Ctx) error {
+	var req validation.CreateStockCount
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	count, err := s.StockCountService.CreateStockCount(c, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Stock count recorded successfully",
+		"data":    count,
+	})
+}
+
+func (s *StockCountController) GetAll(c *fiber.Ctx) error {
+	params := new(validation.QueryStockCount)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	counts, total, err := s.StockCountService.GetStockCounts(c, params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Stock counts retrieved successfully",
+		"data":    counts,
+		"total":   total,
+	})
+}
+
+func (s *StockCountController) GetByID(c *fiber.Ctx) error {
+	count, err := s.StockCountService.GetStockCountByID(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Stock count retrieved successfully",
+		"data":    count,
+	})
+}
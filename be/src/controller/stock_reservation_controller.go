@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"app/src/service"
+	"app/src/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type StockReservationController struct {
+	StockReservationService service.StockReservationService
+}
+
+func NewStockReservationController(stockReservationService service.StockReservationService) *StockReservationController {
+	return &StockReservationController{
+		StockReservationService: stockReservationService,
+	}
+}
+
+func (s *StockReservationController) Create(c *fiber.Ctx) error {
+	var req validation.CreateStockReservation
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	reservation, err := s.StockReservationService.CreateReservation(c, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Stock reserved successfully",
+		"data":    reservation,
+	})
+}
+
+func (s *StockReservationController) GetAll(c *fiber.Ctx) error {
+	params := new(validation.QueryStockReservation)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	reservations, total, err := s.StockReservationService.GetReservations(c, params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Stock reservations retrieved successfully",
+		"data":    reservations,
+		"total":   total,
+	})
+}
+
+func (s *StockReservationController) GetByID(c *fiber.Ctx) error {
+	reservation, err := s.StockReservationService.GetReservationByID(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Stock reservation retrieved successfully",
+		"data":    reservation,
+	})
+}
+
+func (s *StockReservationController) Release(c *fiber.Ctx) error {
+	reservation, err := s.StockReservationService.ReleaseReservation(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Stock reservation released successfully",
+		"data":    reservation,
+	})
+}
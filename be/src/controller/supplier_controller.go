@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"math"
+
+	"app/src/model"
+	"app/src/response"
+	"app/src/service"
+	"app/src/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SupplierController struct {
+	SupplierService service.SupplierService
+}
+
+func NewSupplierController(supplierService service.SupplierService) *SupplierController {
+	return &SupplierController{
+		SupplierService: supplierService,
+	}
+}
+
+func (s *SupplierController) Create(c *fiber.Ctx) error {
+	var req validation.CreateSupplier
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	supplier, err := s.SupplierService.CreateSupplier(c, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Supplier created successfully",
+		"data":    supplier,
+	})
+}
+
+func (s *SupplierController) GetAll(c *fiber.Ctx) error {
+	params := new(validation.QuerySupplier)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	suppliers, total, err := s.SupplierService.GetSuppliers(c, params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.SuccessWithPaginate[model.Supplier]{
+			Code:         fiber.StatusOK,
+			Status:       "success",
+			Message:      "Suppliers retrieved successfully",
+			Results:      suppliers,
+			Page:         params.Page,
+			Limit:        params.Limit,
+			TotalPages:   int64(math.Ceil(float64(total) / float64(params.Limit))),
+			TotalResults: total,
+		})
+}
+
+// GetByID returns the supplier itself, as the supplier pages read it.
+func (s *SupplierController) GetByID(c *fiber.Ctx) error {
+	supplier, err := s.SupplierService.GetSupplierByID(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(supplier)
+}
+
+func (s *SupplierController) Update(c *fiber.Ctx) error {
+	var req validation.UpdateSupplier
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	supplier, err := s.SupplierService.UpdateSupplier(c, c.Params("id"), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Supplier updated successfully",
+		"data":    supplier,
+	})
+}
+
+func (s *SupplierController) Delete(c *fiber.Ctx) error {
+	if err := s.SupplierService.DeleteSupplier(c, c.Params("id")); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Supplier deleted successfully",
+	})
+}
@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"math"
+
+	"app/src/model"
+	"app/src/response"
+	"app/src/service"
+	"app/src/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SupplierItemController struct {
+	SupplierItemService service.SupplierItemService
+}
+
+func NewSupplierItemController(supplierItemService service.SupplierItemService) *SupplierItemController {
+	return &SupplierItemController{
+		SupplierItemService: supplierItemService,
+	}
+}
+
+func (s *SupplierItemController) Create(c *fiber.Ctx) error {
+	var req validation.CreateSupplierItem
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	supplierItem, err := s.SupplierItemService.CreateSupplierItem(c, c.Params("id"), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Supplier item created successfully",
+		"data":    supplierItem,
+	})
+}
+
+func (s *SupplierItemController) GetAll(c *fiber.Ctx) error {
+	params := new(validation.QuerySupplierItem)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	supplierItems, total, err := s.SupplierItemService.GetSupplierItems(c, c.Params("id"), params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.SuccessWithPaginate[model.SupplierItem]{
+			Code:         fiber.StatusOK,
+			Status:       "success",
+			Message:      "Supplier items retrieved successfully",
+			Results:      supplierItems,
+			Page:         params.Page,
+			Limit:        params.Limit,
+			TotalPages:   int64(math.Ceil(float64(total) / float64(params.Limit))),
+			TotalResults: total,
+		})
+}
+
+func (s *SupplierItemController) GetByItemID(c *fiber.Ctx) error {
+	supplierItem, err := s.SupplierItemService.GetSupplierItem(c, c.Params("id"), c.Params("itemId"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Supplier item retrieved successfully",
+		"data":    supplierItem,
+	})
+}
+
+func (s *SupplierItemController) Update(c *fiber.Ctx) error {
+	var req validation.UpdateSupplierItem
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	supplierItem, err := s.SupplierItemService.UpdateSupplierItem(c, c.Params("id"), c.Params("itemId"), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Supplier item updated successfully",
+		"data":    supplierItem,
+	})
+}
+
+func (s *SupplierItemController) Delete(c *fiber.Ctx) error {
+	if err := s.SupplierItemService.DeleteSupplierItem(c, c.Params("id"), c.Params("itemId")); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Supplier item deleted successfully",
+	})
+}
+
+func (s *SupplierItemController) GetPrices(c *fiber.Ctx) error {
+	prices, err := s.SupplierItemService.GetPriceHistory(c, c.Params("id"), c.Params("itemId"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Supplier item prices retrieved successfully",
+		"data":    prices,
+	})
+}
+
+func (s *SupplierItemController) GetSuppliersForItem(c *fiber.Ctx) error {
+	params := new(validation.QuerySuppliersForItem)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	supplierItems, err := s.SupplierItemService.GetSuppliersForItem(c, c.Params("itemId"), params)
+	if err != nil {
+		return err
+	}
+	if supplierItems == nil {
+		supplierItems = []model.SupplierItem{}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success":        true,
+		"message":        "Suppliers for item retrieved successfully",
+		"supplier_items": supplierItems,
+	})
+}
+
+func (s *SupplierItemController) GetItemSupplier(c *fiber.Ctx) error {
+	params := new(validation.QueryItemSupplier)
+	if err := c.QueryParser(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	supplierItem, err := s.SupplierItemService.GetItemSupplier(c, c.Params("itemId"), params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Supplier for item retrieved successfully",
+		"data":    supplierItem,
+	})
+}
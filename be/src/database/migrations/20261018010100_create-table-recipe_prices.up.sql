@@ -0,0 +1,9 @@
+CREATE TABLE recipe_prices (
+    id              UUID            PRIMARY KEY DEFAULT gen_random_uuid(),
+    recipe_id       UUID            NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    branch_id       UUID            NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+    selling_price   DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at      TIMESTAMP       DEFAULT NOW(),
+    updated_at      TIMESTAMP       DEFAULT NOW(),
+    CONSTRAINT idx_recipe_price_branch UNIQUE (recipe_id, branch_id)
+);
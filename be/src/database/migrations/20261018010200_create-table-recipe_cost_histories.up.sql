@@ -0,0 +1,14 @@
+CREATE TABLE recipe_cost_histories (
+    id                  UUID            PRIMARY KEY DEFAULT gen_random_uuid(),
+    recipe_id           UUID            NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    branch_id           UUID            NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+    cost_per_serving    DOUBLE PRECISION NOT NULL,
+    selling_price       DOUBLE PRECISION NOT NULL DEFAULT 0,
+    food_cost_pct       DOUBLE PRECISION NOT NULL DEFAULT 0,
+    gross_margin        DOUBLE PRECISION NOT NULL DEFAULT 0,
+    reason              VARCHAR(50)     NOT NULL, -- 'recipe_change' or 'item_cost_change' or 'price_change'
+    calculated_at       TIMESTAMP       NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_recipe_cost_histories_recipe_id ON recipe_cost_histories(recipe_id, calculated_at);
+CREATE INDEX IF NOT EXISTS idx_recipe_cost_histories_branch_id ON recipe_cost_histories(branch_id);
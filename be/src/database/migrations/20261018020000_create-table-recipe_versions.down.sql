@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS recipe_version_ingredients;
+DROP TABLE IF EXISTS recipe_versions;
+ALTER TABLE recipes DROP COLUMN IF EXISTS current_version;
+ALTER TABLE recipes DROP COLUMN IF EXISTS yield;
@@ -0,0 +1,42 @@
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS yield DOUBLE PRECISION NOT NULL DEFAULT 1;
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS current_version INT NOT NULL DEFAULT 1;
+
+CREATE TABLE recipe_versions (
+    id              UUID            PRIMARY KEY DEFAULT gen_random_uuid(),
+    recipe_id       UUID            NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    version         INT             NOT NULL,
+    code            VARCHAR(50)     NOT NULL,
+    name            VARCHAR(255)    NOT NULL,
+    type            VARCHAR(50)     NOT NULL,
+    description     TEXT,
+    instruction     TEXT,
+    yield           DOUBLE PRECISION NOT NULL DEFAULT 1,
+    change_note     TEXT,
+    created_by      UUID,
+    created_at      TIMESTAMP       DEFAULT NOW(),
+    CONSTRAINT idx_recipe_version UNIQUE (recipe_id, version)
+);
+
+-- item_id is kept without a foreign key so history survives item removal
+CREATE TABLE recipe_version_ingredients (
+    id                  UUID            PRIMARY KEY DEFAULT gen_random_uuid(),
+    recipe_version_id   UUID            NOT NULL REFERENCES recipe_versions(id) ON DELETE CASCADE,
+    item_id             UUID            NOT NULL,
+    item_code           VARCHAR(50)     NOT NULL,
+    item_name           VARCHAR(255)    NOT NULL,
+    quantity            DOUBLE PRECISION NOT NULL,
+    unit                VARCHAR(50)     NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_recipe_version_ingredients_version_id ON recipe_version_ingredients(recipe_version_id);
+
+-- existing recipes start at version 1
+INSERT INTO recipe_versions (recipe_id, version, code, name, type, description, instruction, yield, change_note, created_at)
+SELECT id, 1, code, name, type, description, instruction, yield, 'Initial version', created_at
+FROM recipes;
+
+INSERT INTO recipe_version_ingredients (recipe_version_id, item_id, item_code, item_name, quantity, unit)
+SELECT rv.id, ri.item_id, i.code, i.name, ri.quantity, ri.unit
+FROM recipe_ingredients ri
+JOIN recipe_versions rv ON rv.recipe_id = ri.recipe_id AND rv.version = 1
+JOIN items i ON i.id = ri.item_id;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS stock_reservations;
+DROP TABLE IF EXISTS production_order_lines;
+DROP TABLE IF EXISTS production_orders;
+DROP INDEX IF EXISTS idx_item_transactions_reference;
+ALTER TABLE item_transactions DROP COLUMN IF EXISTS reference_id;
+ALTER TABLE item_transactions DROP COLUMN IF EXISTS reference_type;
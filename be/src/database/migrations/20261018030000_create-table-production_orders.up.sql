@@ -0,0 +1,53 @@
+ALTER TABLE item_transactions ADD COLUMN IF NOT EXISTS reference_type VARCHAR(30);
+ALTER TABLE item_transactions ADD COLUMN IF NOT EXISTS reference_id UUID;
+
+CREATE INDEX IF NOT EXISTS idx_item_transactions_reference ON item_transactions(reference_type, reference_id);
+
+CREATE TABLE production_orders (
+    id                  UUID            PRIMARY KEY DEFAULT gen_random_uuid(),
+    branch_id           UUID            NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+    recipe_id           UUID            NOT NULL REFERENCES recipes(id),
+    recipe_version      INT             NOT NULL,
+    planned_date        DATE            NOT NULL,
+    serve_count         INT             NOT NULL,
+    actual_serve_count  INT,
+    status              VARCHAR(20)     NOT NULL DEFAULT 'planned', -- 'planned' or 'released' or 'completed' or 'cancelled'
+    note                TEXT,
+    created_by          UUID,
+    completed_by        UUID,
+    released_at         TIMESTAMP,
+    completed_at        TIMESTAMP,
+    cancelled_at        TIMESTAMP,
+    created_at          TIMESTAMP       DEFAULT NOW(),
+    updated_at          TIMESTAMP       DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_production_orders_branch_id ON production_orders(branch_id, planned_date);
+CREATE INDEX IF NOT EXISTS idx_production_orders_recipe_id ON production_orders(recipe_id);
+
+CREATE TABLE production_order_lines (
+    id                      UUID            PRIMARY KEY DEFAULT gen_random_uuid(),
+    production_order_id     UUID            NOT NULL REFERENCES production_orders(id) ON DELETE CASCADE,
+    item_id                 UUID            NOT NULL REFERENCES items(id),
+    planned_quantity        DOUBLE PRECISION NOT NULL,
+    actual_quantity         DOUBLE PRECISION,
+    variance                DOUBLE PRECISION NOT NULL DEFAULT 0,
+    unit                    VARCHAR(50)     NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_production_order_lines_order_id ON production_order_lines(production_order_id);
+
+CREATE TABLE stock_reservations (
+    id              UUID            PRIMARY KEY DEFAULT gen_random_uuid(),
+    branch_id       UUID            NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+    item_id         UUID            NOT NULL REFERENCES items(id),
+    quantity        DOUBLE PRECISION NOT NULL,
+    source_type     VARCHAR(30)     NOT NULL, -- 'production_order'
+    source_id       UUID            NOT NULL,
+    status          VARCHAR(20)     NOT NULL DEFAULT 'active', -- 'active' or 'consumed' or 'released'
+    created_at      TIMESTAMP       DEFAULT NOW(),
+    updated_at      TIMESTAMP       DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_item_id ON stock_reservations(item_id, status);
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_source ON stock_reservations(source_type, source_id);
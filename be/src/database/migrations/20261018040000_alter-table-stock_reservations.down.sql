@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_stock_reservations_expires_at;
+
+ALTER TABLE stock_reservations DROP COLUMN IF EXISTS created_by;
+ALTER TABLE stock_reservations DROP COLUMN IF EXISTS note;
+ALTER TABLE stock_reservations DROP COLUMN IF EXISTS expires_at;
@@ -0,0 +1,5 @@
+ALTER TABLE stock_reservations ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP;
+ALTER TABLE stock_reservations ADD COLUMN IF NOT EXISTS note TEXT;
+ALTER TABLE stock_reservations ADD COLUMN IF NOT EXISTS created_by UUID;
+
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_expires_at ON stock_reservations(expires_at) WHERE status = 'active';
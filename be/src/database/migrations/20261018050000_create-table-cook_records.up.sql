@@ -0,0 +1,18 @@
+CREATE TABLE cook_records (
+    id              UUID            PRIMARY KEY DEFAULT gen_random_uuid(),
+    branch_id       UUID            NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+    recipe_id       UUID            NOT NULL REFERENCES recipes(id),
+    recipe_version  INT             NOT NULL,
+    serve_count     INT             NOT NULL,
+    status          VARCHAR(20)     NOT NULL DEFAULT 'cooked', -- 'cooked' or 'reversed'
+    cooked_by       UUID,
+    cooked_at       TIMESTAMP       NOT NULL DEFAULT NOW(),
+    reversed_by     UUID,
+    reversed_at     TIMESTAMP,
+    reverse_reason  TEXT,
+    created_at      TIMESTAMP       DEFAULT NOW(),
+    updated_at      TIMESTAMP       DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_cook_records_branch_id ON cook_records(branch_id, cooked_at);
+CREATE INDEX IF NOT EXISTS idx_cook_records_recipe_id ON cook_records(recipe_id, cooked_at);
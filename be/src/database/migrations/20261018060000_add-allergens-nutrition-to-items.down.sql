@@ -0,0 +1,6 @@
+ALTER TABLE items DROP COLUMN IF EXISTS nutrition_sodium;
+ALTER TABLE items DROP COLUMN IF EXISTS nutrition_carbs;
+ALTER TABLE items DROP COLUMN IF EXISTS nutrition_fat;
+ALTER TABLE items DROP COLUMN IF EXISTS nutrition_protein;
+ALTER TABLE items DROP COLUMN IF EXISTS nutrition_kcal;
+ALTER TABLE items DROP COLUMN IF EXISTS allergens;
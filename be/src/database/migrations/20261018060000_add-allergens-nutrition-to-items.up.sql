@@ -0,0 +1,8 @@
+ALTER TABLE items ADD COLUMN IF NOT EXISTS allergens VARCHAR(255) NOT NULL DEFAULT '';
+
+-- nutrition per base unit of the item's unit (gram, milliliter or piece)
+ALTER TABLE items ADD COLUMN IF NOT EXISTS nutrition_kcal DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE items ADD COLUMN IF NOT EXISTS nutrition_protein DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE items ADD COLUMN IF NOT EXISTS nutrition_fat DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE items ADD COLUMN IF NOT EXISTS nutrition_carbs DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE items ADD COLUMN IF NOT EXISTS nutrition_sodium DOUBLE PRECISION NOT NULL DEFAULT 0;
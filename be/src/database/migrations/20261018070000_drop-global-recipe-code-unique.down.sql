@@ -0,0 +1 @@
+ALTER TABLE recipes ADD CONSTRAINT recipes_code_key UNIQUE (code);
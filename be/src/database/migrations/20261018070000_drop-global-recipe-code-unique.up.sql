@@ -0,0 +1,3 @@
+-- recipe codes are unique per branch (idx_recipes_code_branch), not globally,
+-- so the same recipe can exist in every outlet
+ALTER TABLE recipes DROP CONSTRAINT IF EXISTS recipes_code_key;
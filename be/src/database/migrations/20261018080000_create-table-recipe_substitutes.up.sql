@@ -0,0 +1,13 @@
+CREATE TABLE recipe_substitutes (
+    id                  UUID            PRIMARY KEY DEFAULT gen_random_uuid(),
+    recipe_id           UUID            NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    item_id             UUID            NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+    substitute_item_id  UUID            NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+    rank                INT             NOT NULL DEFAULT 1,
+    ratio               DOUBLE PRECISION NOT NULL DEFAULT 1,
+    created_at          TIMESTAMP       DEFAULT NOW(),
+    updated_at          TIMESTAMP       DEFAULT NOW(),
+    CONSTRAINT idx_recipe_substitutes_unique UNIQUE (recipe_id, item_id, substitute_item_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_recipe_substitutes_recipe_id ON recipe_substitutes(recipe_id, item_id, rank);
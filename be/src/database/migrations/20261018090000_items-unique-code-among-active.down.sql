@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_code_branch;
+ALTER TABLE items ADD CONSTRAINT idx_code_branch UNIQUE (code, branch_id);
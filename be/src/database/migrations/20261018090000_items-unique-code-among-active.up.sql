@@ -0,0 +1,3 @@
+-- items are soft-deleted now, so a deleted item must not block its code
+ALTER TABLE items DROP CONSTRAINT IF EXISTS idx_code_branch;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_code_branch ON items(code, branch_id) WHERE deleted_at IS NULL;
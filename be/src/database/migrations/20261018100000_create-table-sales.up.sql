@@ -0,0 +1,16 @@
+CREATE TABLE sales (
+    id              UUID            PRIMARY KEY DEFAULT gen_random_uuid(),
+    branch_id       UUID            NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+    recipe_id       UUID            NOT NULL REFERENCES recipes(id),
+    quantity        INT             NOT NULL,
+    date            DATE            NOT NULL,
+    note            TEXT,
+    status          VARCHAR(20)     NOT NULL DEFAULT 'active', -- 'active' or 'void'
+    void_reason     TEXT,
+    voided_at       TIMESTAMP,
+    created_at      TIMESTAMP       DEFAULT NOW(),
+    updated_at      TIMESTAMP       DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_sales_branch_date ON sales(branch_id, date);
+CREATE INDEX IF NOT EXISTS idx_sales_recipe_id ON sales(recipe_id, date);
@@ -0,0 +1,3 @@
+ALTER TABLE sales DROP COLUMN IF EXISTS depleted;
+ALTER TABLE recipes DROP COLUMN IF EXISTS sale_depletion_mode;
+ALTER TABLE branches DROP COLUMN IF EXISTS sale_depletion_mode;
@@ -0,0 +1,3 @@
+ALTER TABLE branches ADD COLUMN IF NOT EXISTS sale_depletion_mode VARCHAR(20) NOT NULL DEFAULT 'pre_cooked';
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS sale_depletion_mode VARCHAR(20);
+ALTER TABLE sales ADD COLUMN IF NOT EXISTS depleted BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_sales_import_id;
+ALTER TABLE sales DROP COLUMN IF EXISTS import_id;
+
+DROP TABLE IF EXISTS sales_imports;
+DROP TABLE IF EXISTS pos_product_mappings;
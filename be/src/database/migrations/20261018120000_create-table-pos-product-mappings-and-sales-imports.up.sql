@@ -0,0 +1,30 @@
+CREATE TABLE pos_product_mappings (
+    id              UUID            PRIMARY KEY DEFAULT gen_random_uuid(),
+    branch_id       UUID            NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+    product_code    VARCHAR(100)    NOT NULL,
+    product_name    VARCHAR(255),
+    recipe_id       UUID            NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    created_at      TIMESTAMP       DEFAULT NOW(),
+    updated_at      TIMESTAMP       DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pos_mapping_code ON pos_product_mappings(branch_id, product_code);
+CREATE INDEX IF NOT EXISTS idx_pos_product_mappings_recipe_id ON pos_product_mappings(recipe_id);
+
+CREATE TABLE sales_imports (
+    id              UUID            PRIMARY KEY DEFAULT gen_random_uuid(),
+    branch_id       UUID            NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+    source          VARCHAR(20)     NOT NULL, -- 'quinos'
+    date            DATE,
+    file_name       VARCHAR(255),
+    lines_read      INT             NOT NULL DEFAULT 0,
+    imported_count  INT             NOT NULL DEFAULT 0,
+    skipped_codes   TEXT,
+    forced          BOOLEAN         NOT NULL DEFAULT FALSE,
+    created_at      TIMESTAMP       DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_sales_imports_branch_date ON sales_imports(branch_id, source, date);
+
+ALTER TABLE sales ADD COLUMN import_id UUID REFERENCES sales_imports(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_sales_import_id ON sales(import_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_sales_branch_external_ref;
+ALTER TABLE sales DROP COLUMN IF EXISTS external_ref;
@@ -0,0 +1,4 @@
+ALTER TABLE sales ADD COLUMN external_ref VARCHAR(100);
+
+-- a POS line is imported once per branch, whatever file it comes in
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sales_branch_external_ref ON sales(branch_id, external_ref) WHERE external_ref IS NOT NULL;
@@ -0,0 +1,15 @@
+CREATE TABLE sales_import_templates (
+    id                      UUID            PRIMARY KEY DEFAULT gen_random_uuid(),
+    branch_id               UUID            NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+    name                    VARCHAR(100)    NOT NULL,
+    date_column             VARCHAR(100)    NOT NULL,
+    product_code_column     VARCHAR(100)    NOT NULL,
+    product_name_column     VARCHAR(100),
+    quantity_column         VARCHAR(100)    NOT NULL,
+    price_column            VARCHAR(100),
+    date_format             VARCHAR(50),
+    created_at              TIMESTAMP       DEFAULT NOW(),
+    updated_at              TIMESTAMP       DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sales_import_template_name ON sales_import_templates(branch_id, name);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS pos_webhook_events;
+DROP TABLE IF EXISTS pos_integrations;
@@ -0,0 +1,29 @@
+CREATE TABLE pos_integrations (
+    id              UUID            PRIMARY KEY DEFAULT gen_random_uuid(),
+    branch_id       UUID            NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+    name            VARCHAR(100)    NOT NULL,
+    source          VARCHAR(20)     NOT NULL,
+    secret          VARCHAR(100)    NOT NULL,
+    active          BOOLEAN         NOT NULL DEFAULT TRUE,
+    created_at      TIMESTAMP       DEFAULT NOW(),
+    updated_at      TIMESTAMP       DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pos_integrations_branch_id ON pos_integrations(branch_id);
+
+CREATE TABLE pos_webhook_events (
+    id              UUID            PRIMARY KEY DEFAULT gen_random_uuid(),
+    integration_id  UUID            NOT NULL REFERENCES pos_integrations(id) ON DELETE CASCADE,
+    event_id        VARCHAR(100)    NOT NULL,
+    payload         TEXT            NOT NULL,
+    status          VARCHAR(20)     NOT NULL DEFAULT 'pending', -- 'pending', 'processing', 'processed' or 'failed'
+    error           TEXT,
+    attempts        INT             NOT NULL DEFAULT 0,
+    import_id       UUID            REFERENCES sales_imports(id) ON DELETE SET NULL,
+    sale_count      INT             NOT NULL DEFAULT 0,
+    received_at     TIMESTAMP       NOT NULL DEFAULT NOW(),
+    processed_at    TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pos_webhook_event ON pos_webhook_events(integration_id, event_id);
+CREATE INDEX IF NOT EXISTS idx_pos_webhook_events_status ON pos_webhook_events(status, received_at);
@@ -0,0 +1,25 @@
+CREATE TABLE stock_counts (
+    id              UUID            PRIMARY KEY DEFAULT gen_random_uuid(),
+    branch_id       UUID            NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+    counted_at      TIMESTAMP       NOT NULL,
+    counted_by      UUID,
+    note            TEXT,
+    created_at      TIMESTAMP       DEFAULT NOW(),
+    updated_at      TIMESTAMP       DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_counts_branch_counted_at ON stock_counts(branch_id, counted_at);
+
+CREATE TABLE stock_count_lines (
+    id                  UUID            PRIMARY KEY DEFAULT gen_random_uuid(),
+    stock_count_id      UUID            NOT NULL REFERENCES stock_counts(id) ON DELETE CASCADE,
+    item_id             UUID            NOT NULL REFERENCES items(id),
+    system_quantity     DOUBLE PRECISION NOT NULL, -- book stock when counted
+    counted_quantity    DOUBLE PRECISION NOT NULL,
+    difference          DOUBLE PRECISION NOT NULL, -- counted minus system
+    unit_cost           DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at          TIMESTAMP       DEFAULT NOW(),
+    UNIQUE (stock_count_id, item_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_count_lines_item_id ON stock_count_lines(item_id);
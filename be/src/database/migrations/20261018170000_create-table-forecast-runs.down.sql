@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS forecast_values;
+DROP TABLE IF EXISTS forecast_runs;
@@ -0,0 +1,28 @@
+CREATE TABLE forecast_runs (
+    id              UUID            PRIMARY KEY DEFAULT gen_random_uuid(),
+    branch_id       UUID            NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+    model           VARCHAR(30)     NOT NULL, -- 'auto', 'moving_average', 'exponential_smoothing' or 'holt_winters'
+    window_days     INT             NOT NULL DEFAULT 7,
+    alpha           DOUBLE PRECISION NOT NULL DEFAULT 0.3,
+    beta            DOUBLE PRECISION NOT NULL DEFAULT 0.1,
+    gamma           DOUBLE PRECISION NOT NULL DEFAULT 0.3,
+    history_days    INT             NOT NULL,
+    horizon         INT             NOT NULL,
+    start_date      DATE            NOT NULL, -- first forecast day
+    recipe_count    INT             NOT NULL DEFAULT 0,
+    created_at      TIMESTAMP       DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_forecast_runs_branch_created_at ON forecast_runs(branch_id, created_at);
+
+CREATE TABLE forecast_values (
+    id              UUID            PRIMARY KEY DEFAULT gen_random_uuid(),
+    run_id          UUID            NOT NULL REFERENCES forecast_runs(id) ON DELETE CASCADE,
+    recipe_id       UUID            NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    date            DATE            NOT NULL,
+    quantity        DOUBLE PRECISION NOT NULL,
+    model           VARCHAR(30)     NOT NULL, -- model used, after any fallback
+    UNIQUE (run_id, recipe_id, date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_forecast_values_recipe_date ON forecast_values(recipe_id, date);
@@ -0,0 +1,11 @@
+CREATE TABLE recipe_forecast_models (
+    id              UUID            PRIMARY KEY DEFAULT gen_random_uuid(),
+    recipe_id       UUID            NOT NULL UNIQUE REFERENCES recipes(id) ON DELETE CASCADE,
+    model           VARCHAR(30)     NOT NULL, -- best model found by the last back-test
+    mape            DOUBLE PRECISION,
+    mae             DOUBLE PRECISION NOT NULL DEFAULT 0,
+    points          INT             NOT NULL DEFAULT 0,
+    evaluated_at    TIMESTAMP       NOT NULL,
+    created_at      TIMESTAMP       DEFAULT NOW(),
+    updated_at      TIMESTAMP       DEFAULT NOW()
+);
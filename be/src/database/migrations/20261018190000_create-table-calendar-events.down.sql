@@ -0,0 +1,4 @@
+ALTER TABLE forecast_values DROP COLUMN IF EXISTS base_quantity;
+DROP TABLE IF EXISTS forecast_override_audits;
+DROP TABLE IF EXISTS forecast_overrides;
+DROP TABLE IF EXISTS calendar_events;
@@ -0,0 +1,50 @@
+CREATE TABLE calendar_events (
+    id              UUID            PRIMARY KEY DEFAULT gen_random_uuid(),
+    branch_id       UUID            REFERENCES branches(id) ON DELETE CASCADE, -- NULL for every branch
+    name            VARCHAR(255)    NOT NULL,
+    type            VARCHAR(20)     NOT NULL, -- 'holiday', 'religious', 'local_event', 'promotion' or 'closure'
+    start_date      DATE            NOT NULL,
+    end_date        DATE            NOT NULL,
+    uplift          DOUBLE PRECISION NOT NULL DEFAULT 0, -- expected change in sales, percent
+    closed          BOOLEAN         NOT NULL DEFAULT FALSE,
+    note            TEXT,
+    created_by      UUID,
+    updated_by      UUID,
+    created_at      TIMESTAMP       DEFAULT NOW(),
+    updated_at      TIMESTAMP       DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_calendar_events_dates ON calendar_events(start_date, end_date);
+CREATE INDEX IF NOT EXISTS idx_calendar_events_branch_id ON calendar_events(branch_id);
+
+CREATE TABLE forecast_overrides (
+    id              UUID            PRIMARY KEY DEFAULT gen_random_uuid(),
+    branch_id       UUID            NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+    recipe_id       UUID            NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    date            DATE            NOT NULL,
+    quantity        DOUBLE PRECISION NOT NULL,
+    reason          TEXT,
+    changed_by      UUID            NOT NULL,
+    created_at      TIMESTAMP       DEFAULT NOW(),
+    updated_at      TIMESTAMP       DEFAULT NOW(),
+    UNIQUE (recipe_id, date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_forecast_overrides_branch_date ON forecast_overrides(branch_id, date);
+
+CREATE TABLE forecast_override_audits (
+    id              UUID            PRIMARY KEY DEFAULT gen_random_uuid(),
+    branch_id       UUID            NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+    recipe_id       UUID            NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    date            DATE            NOT NULL,
+    action          VARCHAR(10)     NOT NULL, -- 'set' or 'remove'
+    old_quantity    DOUBLE PRECISION,
+    new_quantity    DOUBLE PRECISION,
+    reason          TEXT,
+    changed_by      UUID            NOT NULL,
+    changed_at      TIMESTAMP       NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_forecast_override_audits_recipe_date ON forecast_override_audits(recipe_id, date);
+
+ALTER TABLE forecast_values ADD COLUMN base_quantity DOUBLE PRECISION; -- before calendar events
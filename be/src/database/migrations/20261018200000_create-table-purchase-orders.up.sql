@@ -0,0 +1,27 @@
+CREATE TABLE purchase_orders (
+    id              UUID            PRIMARY KEY DEFAULT gen_random_uuid(),
+    branch_id       UUID            NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+    status          VARCHAR(20)     NOT NULL DEFAULT 'ordered', -- 'ordered', 'partially_received', 'received' or 'cancelled'
+    order_date      DATE            NOT NULL,
+    expected_date   DATE            NOT NULL,
+    note            TEXT,
+    created_by      UUID,
+    received_at     TIMESTAMP,
+    cancelled_at    TIMESTAMP,
+    created_at      TIMESTAMP       DEFAULT NOW(),
+    updated_at      TIMESTAMP       DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_orders_branch_status ON purchase_orders(branch_id, status);
+
+CREATE TABLE purchase_order_lines (
+    id                  UUID            PRIMARY KEY DEFAULT gen_random_uuid(),
+    purchase_order_id   UUID            NOT NULL REFERENCES purchase_orders(id) ON DELETE CASCADE,
+    item_id             UUID            NOT NULL REFERENCES items(id),
+    quantity            DOUBLE PRECISION NOT NULL, -- in the item's stock unit
+    received_quantity   DOUBLE PRECISION NOT NULL DEFAULT 0,
+    unit_cost           DOUBLE PRECISION NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_order_lines_purchase_order_id ON purchase_order_lines(purchase_order_id);
+CREATE INDEX IF NOT EXISTS idx_purchase_order_lines_item_id ON purchase_order_lines(item_id);
@@ -0,0 +1,17 @@
+CREATE TABLE suppliers (
+    id                  UUID            PRIMARY KEY DEFAULT gen_random_uuid(),
+    name                VARCHAR(255)    NOT NULL,
+    slug                VARCHAR(255)    NOT NULL,
+    address             TEXT            NOT NULL DEFAULT '',
+    whatsapp_number     VARCHAR(30)     NOT NULL DEFAULT '',
+    phone_number        VARCHAR(30)     NOT NULL DEFAULT '',
+    created_by          UUID,
+    updated_by          UUID,
+    deleted_by          UUID,
+    deleted_at          TIMESTAMP,
+    created_at          TIMESTAMP       DEFAULT NOW(),
+    updated_at          TIMESTAMP       DEFAULT NOW()
+);
+
+-- a deleted supplier frees its slug
+CREATE UNIQUE INDEX IF NOT EXISTS idx_suppliers_slug ON suppliers(slug) WHERE deleted_at IS NULL;
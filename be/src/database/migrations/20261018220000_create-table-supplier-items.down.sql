@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS supplier_item_prices;
+DROP TABLE IF EXISTS supplier_items;
@@ -0,0 +1,30 @@
+CREATE TABLE supplier_items (
+    id              UUID            PRIMARY KEY DEFAULT gen_random_uuid(),
+    supplier_id     UUID            NOT NULL REFERENCES suppliers(id) ON DELETE CASCADE,
+    item_id         UUID            NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+    purchase_unit   VARCHAR(50)     NOT NULL, -- what is ordered, e.g. 'sack'
+    pack_size       DOUBLE PRECISION NOT NULL DEFAULT 1, -- pack_unit in one purchase unit
+    pack_unit       VARCHAR(50)     NOT NULL, -- converts to the item's stock unit
+    moq             DOUBLE PRECISION NOT NULL DEFAULT 0, -- in purchase units
+    preferred       BOOLEAN         NOT NULL DEFAULT FALSE,
+    created_by      UUID,
+    updated_by      UUID,
+    deleted_by      UUID,
+    deleted_at      TIMESTAMP,
+    created_at      TIMESTAMP       DEFAULT NOW(),
+    updated_at      TIMESTAMP       DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_supplier_items_supplier_item ON supplier_items(supplier_id, item_id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_supplier_items_item_id ON supplier_items(item_id);
+
+CREATE TABLE supplier_item_prices (
+    id                  UUID            PRIMARY KEY DEFAULT gen_random_uuid(),
+    supplier_item_id    UUID            NOT NULL REFERENCES supplier_items(id) ON DELETE CASCADE,
+    price               DOUBLE PRECISION NOT NULL, -- per purchase unit
+    effective_from      DATE            NOT NULL,
+    created_by          UUID,
+    created_at          TIMESTAMP       DEFAULT NOW(),
+    updated_at          TIMESTAMP       DEFAULT NOW(),
+    UNIQUE (supplier_item_id, effective_from)
+);
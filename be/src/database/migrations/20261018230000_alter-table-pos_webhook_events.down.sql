@@ -0,0 +1 @@
+ALTER TABLE pos_webhook_events DROP COLUMN IF EXISTS processing_started_at;
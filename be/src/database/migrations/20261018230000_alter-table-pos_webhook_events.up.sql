@@ -0,0 +1 @@
+ALTER TABLE pos_webhook_events ADD COLUMN IF NOT EXISTS processing_started_at TIMESTAMP;
@@ -0,0 +1,4 @@
+DELETE FROM calendar_events WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE calendar_events DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE calendar_events DROP COLUMN IF EXISTS deleted_by;
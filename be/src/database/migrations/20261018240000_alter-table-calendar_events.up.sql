@@ -0,0 +1,4 @@
+-- deleted events stay behind so forecast runs made before the deletion are
+-- seen as stale
+ALTER TABLE calendar_events ADD COLUMN IF NOT EXISTS deleted_by UUID;
+ALTER TABLE calendar_events ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
@@ -0,0 +1,3 @@
+-- a voided sale frees its POS line to be imported again
+DROP INDEX IF EXISTS idx_sales_branch_external_ref;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sales_branch_external_ref ON sales(branch_id, external_ref) WHERE external_ref IS NOT NULL AND status = 'active';
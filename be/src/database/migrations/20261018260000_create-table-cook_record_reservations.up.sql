@@ -0,0 +1,10 @@
+-- what a cook drew from each reservation, so a reversal can reserve it again
+CREATE TABLE cook_record_reservations (
+    id              UUID            PRIMARY KEY DEFAULT gen_random_uuid(),
+    cook_record_id  UUID            NOT NULL REFERENCES cook_records(id) ON DELETE CASCADE,
+    reservation_id  UUID            NOT NULL REFERENCES stock_reservations(id) ON DELETE CASCADE,
+    quantity        DOUBLE PRECISION NOT NULL, -- in the item's stock unit
+    created_at      TIMESTAMP       DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_cook_record_reservations_cook_record_id ON cook_record_reservations(cook_record_id);
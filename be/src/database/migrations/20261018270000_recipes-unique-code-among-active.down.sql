@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_recipes_code_branch;
+ALTER TABLE recipes ADD CONSTRAINT idx_recipes_code_branch UNIQUE (code, branch_id);
@@ -0,0 +1,4 @@
+-- a soft deleted recipe no longer holds its code, so it can be cloned or
+-- imported into the branch again
+ALTER TABLE recipes DROP CONSTRAINT IF EXISTS idx_recipes_code_branch;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_recipes_code_branch ON recipes(code, branch_id) WHERE deleted_at IS NULL;
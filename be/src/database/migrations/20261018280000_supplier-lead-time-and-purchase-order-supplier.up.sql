@@ -0,0 +1,6 @@
+ALTER TABLE supplier_items ADD COLUMN IF NOT EXISTS lead_time INT; -- days; NULL uses the item's lead time
+
+-- the supplier an order went to, so its receipts rate the supplier
+ALTER TABLE purchase_orders ADD COLUMN IF NOT EXISTS supplier_id UUID REFERENCES suppliers(id);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_orders_supplier_id ON purchase_orders(supplier_id, status) WHERE supplier_id IS NOT NULL;
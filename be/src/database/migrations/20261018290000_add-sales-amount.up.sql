@@ -0,0 +1 @@
+ALTER TABLE sales ADD COLUMN IF NOT EXISTS amount DOUBLE PRECISION; -- sales amount of the line as the POS reported it
@@ -0,0 +1 @@
+ALTER TABLE sales DROP COLUMN IF EXISTS depletion_note;
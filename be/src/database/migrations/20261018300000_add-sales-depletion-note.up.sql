@@ -0,0 +1 @@
+ALTER TABLE sales ADD COLUMN IF NOT EXISTS depletion_note TEXT; -- recipe lines that could not be deducted at sale
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS recipe_version_substitutes;
+ALTER TABLE recipe_versions DROP COLUMN IF EXISTS substitutes_recorded;
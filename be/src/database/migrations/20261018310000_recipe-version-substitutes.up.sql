@@ -0,0 +1,31 @@
+-- versions written before this recorded no substitutes; restoring them leaves
+-- the recipe's substitutes alone
+ALTER TABLE recipe_versions ADD COLUMN IF NOT EXISTS substitutes_recorded BOOLEAN NOT NULL DEFAULT false;
+
+-- item IDs are kept without a foreign key so history survives item removal
+CREATE TABLE recipe_version_substitutes (
+    id                      UUID            PRIMARY KEY DEFAULT gen_random_uuid(),
+    recipe_version_id       UUID            NOT NULL REFERENCES recipe_versions(id) ON DELETE CASCADE,
+    item_id                 UUID            NOT NULL,
+    item_code               VARCHAR(50)     NOT NULL,
+    substitute_item_id      UUID            NOT NULL,
+    substitute_item_code    VARCHAR(50)     NOT NULL,
+    substitute_item_name    VARCHAR(255)    NOT NULL,
+    rank                    INT             NOT NULL DEFAULT 1,
+    ratio                   DOUBLE PRECISION NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS idx_recipe_version_substitutes_version_id ON recipe_version_substitutes(recipe_version_id);
+
+-- the current version of every recipe gets its substitutes as they are now
+INSERT INTO recipe_version_substitutes (recipe_version_id, item_id, item_code, substitute_item_id, substitute_item_code, substitute_item_name, rank, ratio)
+SELECT rv.id, rs.item_id, i.code, rs.substitute_item_id, si.code, si.name, rs.rank, rs.ratio
+FROM recipe_substitutes rs
+JOIN recipes r ON r.id = rs.recipe_id
+JOIN recipe_versions rv ON rv.recipe_id = r.id AND rv.version = r.current_version
+JOIN items i ON i.id = rs.item_id
+JOIN items si ON si.id = rs.substitute_item_id;
+
+UPDATE recipe_versions rv SET substitutes_recorded = true
+FROM recipes r
+WHERE r.id = rv.recipe_id AND rv.version = r.current_version;
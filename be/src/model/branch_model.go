@@ -12,6 +12,7 @@ type Branch struct {
 	Slug             string     `gorm:"not null;unique" json:"slug"`
 	PicEmails        string     `gorm:"default:''" json:"pic_emails"`
 	PicPhoneNumbers  string     `gorm:"default:''" json:"pic_phone_numbers"`
+	SaleDepletionMode string     `gorm:"type:varchar(20);not null;default:pre_cooked" json:"sale_depletion_mode"` // deplete_at_sale, pre_cooked
 	CreatedBy        *string    `json:"created_by,omitempty"`
 	UpdatedBy        *string    `json:"updated_by,omitempty"`
 	DeletedBy        *string    `json:"deleted_by,omitempty"`
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CalendarEvent is a holiday, event or closure that moves sales. Events
+// without a branch apply to every branch.
+type CalendarEvent struct {
+	ID        uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	BranchID  *string    `gorm:"type:uuid;index" json:"branch_id"`
+	Name      string     `gorm:"type:varchar(255);not null" json:"name"`
+	Type      string     `gorm:"type:varchar(20);not null" json:"type"` // holiday, religious, local_event, promotion, closure
+	StartDate time.Time  `gorm:"type:date;not null" json:"start_date"`
+	EndDate   time.Time  `gorm:"type:date;not null" json:"end_date"`
+	Uplift    float64    `gorm:"not null;default:0" json:"uplift"` // expected change in sales, percent
+	Closed    bool       `gorm:"not null;default:false" json:"closed"`
+	Note      string     `gorm:"type:text" json:"note"`
+	CreatedBy *string    `gorm:"type:uuid" json:"created_by,omitempty"`
+	UpdatedBy *string    `gorm:"type:uuid" json:"updated_by,omitempty"`
+	DeletedBy *string    `gorm:"type:uuid" json:"deleted_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+func (CalendarEvent) TableName() string {
+	return "calendar_events"
+}
@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CookRecordReservation is the stock a cook drew from one reservation.
+type CookRecordReservation struct {
+	ID            uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	CookRecordID  string    `gorm:"type:uuid;not null;index" json:"cook_record_id"`
+	ReservationID string    `gorm:"type:uuid;not null" json:"reservation_id"`
+	Quantity      float64   `gorm:"not null" json:"quantity"` // in the item's stock unit
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (CookRecordReservation) TableName() string {
+	return "cook_record_reservations"
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CookRecord struct {
+	ID            uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	BranchID      string     `gorm:"type:uuid;not null;index" json:"branch_id"`
+	RecipeID      string     `gorm:"type:uuid;not null;index" json:"recipe_id"`
+	Recipe        *Recipe    `gorm:"foreignKey:RecipeID" json:"recipe,omitempty"`
+	RecipeVersion int        `gorm:"not null" json:"recipe_version"`
+	ServeCount    int        `gorm:"not null" json:"serve_count"`
+	Status        string     `gorm:"type:varchar(20);not null;default:cooked" json:"status"` // cooked, reversed
+	CookedBy      *string    `gorm:"type:uuid" json:"cooked_by,omitempty"`
+	CookedAt      time.Time  `gorm:"not null" json:"cooked_at"`
+	ReversedBy    *string    `gorm:"type:uuid" json:"reversed_by,omitempty"`
+	ReversedAt    *time.Time `json:"reversed_at"`
+	ReverseReason string     `gorm:"type:text" json:"reverse_reason"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	// stock movements posted with reference type "cook" and this record's ID
+	Movements []ItemTransaction `gorm:"-" json:"movements,omitempty"`
+}
+
+func (CookRecord) TableName() string {
+	return "cook_records"
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ForecastOverride is a planner's own figure for a recipe on a day. It wins
+// over whatever the forecast runs say.
+type ForecastOverride struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	BranchID  string    `gorm:"type:uuid;not null;index" json:"branch_id"`
+	RecipeID  string    `gorm:"type:uuid;not null;uniqueIndex:idx_forecast_override_recipe_date" json:"recipe_id"`
+	Recipe    *Recipe   `gorm:"foreignKey:RecipeID" json:"recipe,omitempty"`
+	Date      time.Time `gorm:"type:date;not null;uniqueIndex:idx_forecast_override_recipe_date" json:"date"`
+	Quantity  float64   `gorm:"not null" json:"quantity"`
+	Reason    string    `gorm:"type:text" json:"reason"`
+	ChangedBy string    `gorm:"type:uuid;not null" json:"changed_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (ForecastOverride) TableName() string {
+	return "forecast_overrides"
+}
+
+// ForecastOverrideAudit records every change made to the overrides.
+type ForecastOverrideAudit struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	BranchID    string    `gorm:"type:uuid;not null" json:"branch_id"`
+	RecipeID    string    `gorm:"type:uuid;not null;index" json:"recipe_id"`
+	Date        time.Time `gorm:"type:date;not null" json:"date"`
+	Action      string    `gorm:"type:varchar(10);not null" json:"action"` // set, remove
+	OldQuantity *float64  `json:"old_quantity"`
+	NewQuantity *float64  `json:"new_quantity"`
+	Reason      string    `gorm:"type:text" json:"reason"`
+	ChangedBy   string    `gorm:"type:uuid;not null" json:"changed_by"`
+	ChangedAt   time.Time `gorm:"not null" json:"changed_at"`
+}
+
+func (ForecastOverrideAudit) TableName() string {
+	return "forecast_override_audits"
+}
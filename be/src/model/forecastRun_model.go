@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ForecastRun is one pass of a forecasting model over the sales history of a
+// branch, with the daily forecasts it produced per recipe.
+type ForecastRun struct {
+	ID          uuid.UUID       `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	BranchID    string          `gorm:"type:uuid;not null;index" json:"branch_id"`
+	Model       string          `gorm:"type:varchar(30);not null" json:"model"` // auto, moving_average, exponential_smoothing, holt_winters
+	Window      int             `gorm:"column:window_days;not null;default:7" json:"window"`
+	Alpha       float64         `gorm:"not null;default:0.3" json:"alpha"`
+	Beta        float64         `gorm:"not null;default:0.1" json:"beta"`
+	Gamma       float64         `gorm:"not null;default:0.3" json:"gamma"`
+	HistoryDays int             `gorm:"not null" json:"history_days"`
+	Horizon     int             `gorm:"not null" json:"horizon"`
+	StartDate   time.Time       `gorm:"type:date;not null" json:"start_date"` // first forecast day
+	RecipeCount int             `gorm:"not null;default:0" json:"recipe_count"`
+	Values      []ForecastValue `gorm:"foreignKey:RunID" json:"values,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+func (ForecastRun) TableName() string {
+	return "forecast_runs"
+}
+
+type ForecastValue struct {
+	ID       uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	RunID    string    `gorm:"type:uuid;not null;index" json:"run_id"`
+	RecipeID string    `gorm:"type:uuid;not null;index" json:"recipe_id"`
+	Recipe   *Recipe   `gorm:"foreignKey:RecipeID" json:"recipe,omitempty"`
+	Date     time.Time `gorm:"type:date;not null" json:"date"`
+	Quantity float64   `gorm:"not null" json:"quantity"`
+	Base     *float64  `gorm:"column:base_quantity" json:"base_quantity,omitempty"` // before calendar events
+	Model    string    `gorm:"type:varchar(30);not null" json:"model"`              // model used, after any fallback
+}
+
+func (ForecastValue) TableName() string {
+	return "forecast_values"
+}
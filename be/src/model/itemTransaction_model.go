@@ -12,10 +12,12 @@ type ItemTransaction struct {
 	BranchID        string     `gorm:"not null;index" json:"branch_id"`
 	FromBranchID    *string    `gorm:"-" json:"from_branch_id,omitempty"`
 	ToBranchID      *string    `gorm:"-" json:"to_branch_id,omitempty"`
-	Type            string     `gorm:"type:varchar(20);not null" json:"type"` // in, out, transfer_in, transfer_out, cook_in, cook_out
+	Type            string     `gorm:"type:varchar(20);not null" json:"type"` // in, out, waste, transfer_in, transfer_out, cook_in, cook_out, count_in, count_out
 	Amount          float64    `gorm:"not null" json:"amount"`
 	CurrentStock    float64    `gorm:"not null" json:"current_stock"`
 	Note            string     `gorm:"type:text" json:"note"`
+	ReferenceType   *string    `gorm:"type:varchar(30);index:idx_item_transactions_reference" json:"reference_type,omitempty"` // production_order, cook, sale, ...
+	ReferenceID     *string    `gorm:"type:uuid;index:idx_item_transactions_reference" json:"reference_id,omitempty"`
 	TransactionDate time.Time  `gorm:"not null" json:"transaction_date"`
 	CreatedAt       time.Time  `json:"created_at"`
 	UpdatedAt       time.Time  `json:"updated_at"`
@@ -15,6 +15,11 @@ type Item struct {
 	Unit      string     `json:"unit" gorm:"type:varchar(50);not null"`
     Stock     float64    `json:"stock" gorm:"not null;default:0" `
     LeadTime  int        `json:"lead_time" gorm:"not null;default:0" `
+    UnitCost  float64    `json:"unit_cost" gorm:"not null;default:0"` // cost per stock unit
+    Allergens string     `json:"allergens" gorm:"type:varchar(255);not null;default:''"` // comma separated, lowercase
+    Nutrition Nutrition  `json:"nutrition" gorm:"embedded;embeddedPrefix:nutrition_"`       // per base unit
+    Reserved  float64    `json:"reserved" gorm:"-"`  // held by active reservations
+    Available float64    `json:"available" gorm:"-"` // stock (on hand) minus reserved
     CreatedAt time.Time  `json:"created_at"`
     UpdatedAt time.Time  `json:"updated_at"`
     DeletedAt *time.Time `json:"deleted_at"`
@@ -0,0 +1,23 @@
+package model
+
+// Nutrition is a nutrition panel. Items store it per base unit of their
+// stock unit (gram, milliliter or piece), recipes report it per serving.
+type Nutrition struct {
+	Kcal    float64 `json:"kcal"`
+	Protein float64 `json:"protein"` // gram
+	Fat     float64 `json:"fat"`     // gram
+	Carbs   float64 `json:"carbs"`   // gram
+	Sodium  float64 `json:"sodium"`  // milligram
+}
+
+func (n Nutrition) IsZero() bool {
+	return n == Nutrition{}
+}
+
+func (n *Nutrition) Add(other Nutrition, factor float64) {
+	n.Kcal += other.Kcal * factor
+	n.Protein += other.Protein * factor
+	n.Fat += other.Fat * factor
+	n.Carbs += other.Carbs * factor
+	n.Sodium += other.Sodium * factor
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PosIntegration is a POS system allowed to push sales of a branch through
+// the webhook. Its secret signs every request.
+type PosIntegration struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	BranchID  string    `gorm:"type:uuid;not null;index" json:"branch_id"`
+	Name      string    `gorm:"type:varchar(100);not null" json:"name"`
+	Source    string    `gorm:"type:varchar(20);not null" json:"source"` // iseller, quinos, custom, ...
+	Secret    string    `gorm:"type:varchar(100);not null" json:"-"`
+	Active    bool      `gorm:"not null;default:true" json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (PosIntegration) TableName() string {
+	return "pos_integrations"
+}
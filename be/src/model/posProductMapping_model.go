@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PosProductMapping links a product code of a POS export to the recipe it
+// sells, per branch.
+type PosProductMapping struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	BranchID    string    `gorm:"type:uuid;not null;uniqueIndex:idx_pos_mapping_code" json:"branch_id"`
+	ProductCode string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_pos_mapping_code" json:"product_code"`
+	ProductName string    `gorm:"type:varchar(255)" json:"product_name"`
+	RecipeID    string    `gorm:"type:uuid;not null;index" json:"recipe_id"`
+	Recipe      *Recipe   `gorm:"foreignKey:RecipeID" json:"recipe,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (PosProductMapping) TableName() string {
+	return "pos_product_mappings"
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PosWebhookEvent is a sale pushed by a POS integration. The event ID given by
+// the POS deduplicates deliveries.
+type PosWebhookEvent struct {
+	ID                  uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	IntegrationID       string     `gorm:"type:uuid;not null;uniqueIndex:idx_pos_webhook_event" json:"integration_id"`
+	EventID             string     `gorm:"type:varchar(100);not null;uniqueIndex:idx_pos_webhook_event" json:"event_id"`
+	Payload             string     `gorm:"type:text;not null" json:"-"`
+	Status              string     `gorm:"type:varchar(20);not null;default:pending" json:"status"` // pending, processing, processed, failed
+	Error               string     `gorm:"type:text" json:"error,omitempty"`
+	Attempts            int        `gorm:"not null;default:0" json:"attempts"`
+	ImportID            *string    `gorm:"type:uuid" json:"import_id,omitempty"`
+	SaleCount           int        `gorm:"not null;default:0" json:"sale_count"`
+	ReceivedAt          time.Time  `gorm:"not null" json:"received_at"`
+	ProcessingStartedAt *time.Time `json:"processing_started_at,omitempty"`
+	ProcessedAt         *time.Time `json:"processed_at,omitempty"`
+}
+
+func (PosWebhookEvent) TableName() string {
+	return "pos_webhook_events"
+}
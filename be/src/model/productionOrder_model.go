@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ProductionOrder struct {
+	ID               uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	BranchID         string     `gorm:"type:uuid;not null;index" json:"branch_id"`
+	RecipeID         string     `gorm:"type:uuid;not null;index" json:"recipe_id"`
+	Recipe           *Recipe    `gorm:"foreignKey:RecipeID" json:"recipe,omitempty"`
+	RecipeVersion    int        `gorm:"not null" json:"recipe_version"`
+	PlannedDate      time.Time  `gorm:"type:date;not null" json:"planned_date"`
+	ServeCount       int        `gorm:"not null" json:"serve_count"`
+	ActualServeCount *int       `json:"actual_serve_count"`
+	Status           string     `gorm:"type:varchar(20);not null;default:planned" json:"status"` // planned, released, completed, cancelled
+	Note             string     `gorm:"type:text" json:"note"`
+	CreatedBy        *string    `gorm:"type:uuid" json:"created_by,omitempty"`
+	CompletedBy      *string    `gorm:"type:uuid" json:"completed_by,omitempty"`
+	ReleasedAt       *time.Time `json:"released_at"`
+	CompletedAt      *time.Time `json:"completed_at"`
+	CancelledAt      *time.Time `json:"cancelled_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+
+	Lines []ProductionOrderLine `gorm:"foreignKey:ProductionOrderID;constraint:OnDelete:CASCADE" json:"lines,omitempty"`
+}
+
+func (ProductionOrder) TableName() string {
+	return "production_orders"
+}
+
+type ProductionOrderLine struct {
+	ID                uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ProductionOrderID string    `gorm:"type:uuid;not null;index" json:"production_order_id"`
+	ItemID            string    `gorm:"type:uuid;not null" json:"item_id"`
+	Item              *Item     `gorm:"foreignKey:ItemID" json:"item,omitempty"`
+	PlannedQuantity   float64   `gorm:"not null" json:"planned_quantity"`
+	ActualQuantity    *float64  `json:"actual_quantity"`
+	Variance          float64   `gorm:"not null;default:0" json:"variance"` // actual - planned
+	Unit              string    `gorm:"type:varchar(50);not null" json:"unit"`
+}
+
+func (ProductionOrderLine) TableName() string {
+	return "production_order_lines"
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PurchaseOrder struct {
+	ID           uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	BranchID     string     `gorm:"type:uuid;not null;index" json:"branch_id"`
+	SupplierID   *string    `gorm:"type:uuid" json:"supplier_id"`
+	Supplier     *Supplier  `gorm:"foreignKey:SupplierID" json:"supplier,omitempty"`
+	Status       string     `gorm:"type:varchar(20);not null;default:ordered" json:"status"` // ordered, partially_received, received, cancelled
+	OrderDate    time.Time  `gorm:"type:date;not null" json:"order_date"`
+	ExpectedDate time.Time  `gorm:"type:date;not null" json:"expected_date"`
+	Note         string     `gorm:"type:text" json:"note"`
+	CreatedBy    *string    `gorm:"type:uuid" json:"created_by,omitempty"`
+	ReceivedAt   *time.Time `json:"received_at"`
+	CancelledAt  *time.Time `json:"cancelled_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+
+	Lines []PurchaseOrderLine `gorm:"foreignKey:PurchaseOrderID;constraint:OnDelete:CASCADE" json:"lines,omitempty"`
+}
+
+func (PurchaseOrder) TableName() string {
+	return "purchase_orders"
+}
+
+type PurchaseOrderLine struct {
+	ID               uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	PurchaseOrderID  string    `gorm:"type:uuid;not null;index" json:"purchase_order_id"`
+	ItemID           string    `gorm:"type:uuid;not null;index" json:"item_id"`
+	Item             *Item     `gorm:"foreignKey:ItemID" json:"item,omitempty"`
+	Quantity         float64   `gorm:"not null" json:"quantity"` // in the item's stock unit
+	ReceivedQuantity float64   `gorm:"not null;default:0" json:"received_quantity"`
+	UnitCost         float64   `gorm:"not null;default:0" json:"unit_cost"`
+}
+
+func (PurchaseOrderLine) TableName() string {
+	return "purchase_order_lines"
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RecipeCostHistory struct {
+	ID             uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	RecipeID       string    `gorm:"type:uuid;not null;index" json:"recipe_id"`
+	BranchID       string    `gorm:"type:uuid;not null;index" json:"branch_id"`
+	CostPerServing float64   `gorm:"not null" json:"cost_per_serving"`
+	SellingPrice   float64   `gorm:"not null;default:0" json:"selling_price"`
+	FoodCostPct    float64   `gorm:"not null;default:0" json:"food_cost_pct"`
+	GrossMargin    float64   `gorm:"not null;default:0" json:"gross_margin"`
+	Reason         string    `gorm:"type:varchar(50);not null" json:"reason"` // recipe_change, item_cost_change, price_change
+	CalculatedAt   time.Time `gorm:"not null" json:"calculated_at"`
+}
+
+func (RecipeCostHistory) TableName() string {
+	return "recipe_cost_histories"
+}
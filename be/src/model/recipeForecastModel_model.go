@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecipeForecastModel is the forecasting model that back-tested best for a
+// recipe. Forecast runs with the auto model use it.
+type RecipeForecastModel struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	RecipeID    string    `gorm:"type:uuid;not null;uniqueIndex" json:"recipe_id"`
+	Model       string    `gorm:"type:varchar(30);not null" json:"model"`
+	MAPE        *float64  `gorm:"column:mape" json:"mape"`
+	MAE         float64   `gorm:"column:mae;not null;default:0" json:"mae"`
+	Points      int       `gorm:"not null;default:0" json:"points"`
+	EvaluatedAt time.Time `gorm:"not null" json:"evaluated_at"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (RecipeForecastModel) TableName() string {
+	return "recipe_forecast_models"
+}
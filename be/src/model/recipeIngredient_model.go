@@ -17,6 +17,8 @@ type RecipeIngredient struct {
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
 	DeletedAt *time.Time `json:"deleted_at"`
+
+	Substitutes []RecipeSubstitute `gorm:"-" json:"substitutes,omitempty"`
 }
 
 func (RecipeIngredient) TableName() string {
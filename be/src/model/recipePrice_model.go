@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RecipePrice struct {
+	ID           uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	RecipeID     string    `gorm:"type:uuid;not null;uniqueIndex:idx_recipe_price_branch" json:"recipe_id"`
+	BranchID     string    `gorm:"type:uuid;not null;uniqueIndex:idx_recipe_price_branch" json:"branch_id"`
+	SellingPrice float64   `gorm:"not null;default:0" json:"selling_price"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (RecipePrice) TableName() string {
+	return "recipe_prices"
+}
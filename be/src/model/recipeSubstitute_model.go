@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecipeSubstitute is an approved alternative for one ingredient item of a
+// recipe. Ratio is the quantity of the substitute replacing one unit of the
+// primary item, after converting between the two items' units.
+type RecipeSubstitute struct {
+	ID               uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	RecipeID         string    `gorm:"type:uuid;not null;index" json:"recipe_id"`
+	ItemID           string    `gorm:"type:uuid;not null" json:"item_id"` // primary ingredient item
+	SubstituteItemID string    `gorm:"type:uuid;not null" json:"substitute_item_id"`
+	SubstituteItem   *Item     `gorm:"foreignKey:SubstituteItemID" json:"substitute_item,omitempty"`
+	Rank             int       `gorm:"not null;default:1" json:"rank"` // 1 is tried first
+	Ratio            float64   `gorm:"not null;default:1" json:"ratio"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+func (RecipeSubstitute) TableName() string {
+	return "recipe_substitutes"
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecipeVersion is an immutable snapshot of a recipe formulation. A new
+// version is written every time the recipe is created, updated or restored.
+type RecipeVersion struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	RecipeID    string    `gorm:"type:uuid;not null;uniqueIndex:idx_recipe_version" json:"recipe_id"`
+	Version     int       `gorm:"not null;uniqueIndex:idx_recipe_version" json:"version"`
+	Code        string    `gorm:"type:varchar(50);not null" json:"code"`
+	Name        string    `gorm:"type:varchar(255);not null" json:"name"`
+	Type        string    `gorm:"type:varchar(50);not null" json:"type"`
+	Description string    `gorm:"type:text" json:"description"`
+	Instruction string    `gorm:"type:text" json:"instructions"`
+	Yield       float64   `gorm:"not null;default:1" json:"yield"`
+	ChangeNote  string    `gorm:"type:text" json:"change_note"`
+	CreatedBy   *string   `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	// versions from before substitutes were versioned have none recorded
+	SubstitutesRecorded bool `gorm:"not null;default:false" json:"substitutes_recorded"`
+
+	Ingredients []RecipeVersionIngredient `gorm:"foreignKey:RecipeVersionID;constraint:OnDelete:CASCADE" json:"ingredients,omitempty"`
+	Substitutes []RecipeVersionSubstitute `gorm:"foreignKey:RecipeVersionID;constraint:OnDelete:CASCADE" json:"substitutes,omitempty"`
+}
+
+func (RecipeVersion) TableName() string {
+	return "recipe_versions"
+}
+
+type RecipeVersionIngredient struct {
+	ID              uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	RecipeVersionID string    `gorm:"type:uuid;not null;index" json:"recipe_version_id"`
+	ItemID          string    `gorm:"type:uuid;not null" json:"item_id"`
+	ItemCode        string    `gorm:"type:varchar(50);not null" json:"item_code"`
+	ItemName        string    `gorm:"type:varchar(255);not null" json:"item_name"`
+	Quantity        float64   `gorm:"not null" json:"quantity"`
+	Unit            string    `gorm:"type:varchar(50);not null" json:"unit"`
+}
+
+func (RecipeVersionIngredient) TableName() string {
+	return "recipe_version_ingredients"
+}
+
+type RecipeVersionSubstitute struct {
+	ID                 uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	RecipeVersionID    string    `gorm:"type:uuid;not null;index" json:"recipe_version_id"`
+	ItemID             string    `gorm:"type:uuid;not null" json:"item_id"`
+	ItemCode           string    `gorm:"type:varchar(50);not null" json:"item_code"`
+	SubstituteItemID   string    `gorm:"type:uuid;not null" json:"substitute_item_id"`
+	SubstituteItemCode string    `gorm:"type:varchar(50);not null" json:"substitute_item_code"`
+	SubstituteItemName string    `gorm:"type:varchar(255);not null" json:"substitute_item_name"`
+	Rank               int       `gorm:"not null;default:1" json:"rank"`
+	Ratio              float64   `gorm:"not null;default:1" json:"ratio"`
+}
+
+func (RecipeVersionSubstitute) TableName() string {
+	return "recipe_version_substitutes"
+}
@@ -14,12 +14,19 @@ type Recipe struct {
     Type        string     `json:"type" gorm:"type:varchar(50);not null"` // half_finished, finished
     Description string     `json:"description" gorm:"type:text"`
     Instruction string     `json:"instructions" gorm:"type:text"`
+    Yield       float64    `json:"yield" gorm:"not null;default:1"` // servings produced by the ingredient list
+    CurrentVersion int     `json:"current_version" gorm:"not null;default:1"`
+    SaleDepletionMode *string `json:"sale_depletion_mode" gorm:"type:varchar(20)"` // nil follows the branch
     CreatedBy   string     `json:"created_by" gorm:"type:uuid;not null"`
     CreatedAt   time.Time  `json:"created_at"`
     UpdatedAt   time.Time  `json:"updated_at"`
     DeletedAt   *time.Time `json:"deleted_at"`
 
     Ingredients []RecipeIngredient  `gorm:"foreignKey:RecipeID;constraint:OnDelete:CASCADE" json:"ingredients,omitempty"`
+
+    // rolled up from the ingredients, per serving
+    Allergens []string   `gorm:"-" json:"allergens"`
+    Nutrition *Nutrition `gorm:"-" json:"nutrition,omitempty"`
 }
 
 func (Recipe) TableName() string {
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Sale struct {
+	ID            uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	BranchID      string     `gorm:"type:uuid;not null;index" json:"branch_id"`
+	RecipeID      string     `gorm:"type:uuid;not null;index" json:"recipe_id"`
+	Recipe        *Recipe    `gorm:"foreignKey:RecipeID" json:"recipe,omitempty"`
+	RecipeName    string     `gorm:"-" json:"recipe_name"`
+	Quantity      int        `gorm:"not null" json:"quantity"`
+	Date          time.Time  `gorm:"type:date;not null;index" json:"date"`
+	Note          string     `gorm:"type:text" json:"note"`
+	Status        string     `gorm:"type:varchar(20);not null;default:active" json:"status"` // active, void
+	VoidReason    string     `gorm:"type:text" json:"void_reason,omitempty"`
+	VoidedAt      *time.Time `json:"voided_at,omitempty"`
+	Depleted      bool       `gorm:"not null;default:false" json:"depleted"`          // ingredients were deducted at sale
+	DepletionNote string     `gorm:"type:text" json:"depletion_note,omitempty"`       // recipe lines that could not be deducted
+	ImportID      *string    `gorm:"type:uuid;index" json:"import_id,omitempty"`      // POS import the sale came from
+	ExternalRef   *string    `gorm:"type:varchar(100)" json:"external_ref,omitempty"` // POS line it came from, unique per branch
+	Amount        *float64   `json:"amount,omitempty"`                                // sales amount of the POS line
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	Movements []ItemTransaction `gorm:"-" json:"movements,omitempty"`
+}
+
+func (Sale) TableName() string {
+	return "sales"
+}
+
+func (s *Sale) AfterFind(tx *gorm.DB) error {
+	if s.Recipe != nil {
+		s.RecipeName = s.Recipe.Name
+	}
+	return nil
+}
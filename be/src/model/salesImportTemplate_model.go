@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SalesImportTemplate remembers which columns of a POS sales CSV hold the
+// sale fields.
+type SalesImportTemplate struct {
+	ID                uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	BranchID          string    `gorm:"type:uuid;not null;uniqueIndex:idx_sales_import_template_name" json:"branch_id"`
+	Name              string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_sales_import_template_name" json:"name"`
+	DateColumn        string    `gorm:"type:varchar(100);not null" json:"date_column"`
+	ProductCodeColumn string    `gorm:"type:varchar(100);not null" json:"product_code_column"`
+	ProductNameColumn string    `gorm:"type:varchar(100)" json:"product_name_column"`
+	QuantityColumn    string    `gorm:"type:varchar(100);not null" json:"quantity_column"`
+	PriceColumn       string    `gorm:"type:varchar(100)" json:"price_column"`
+	DateFormat        string    `gorm:"type:varchar(50)" json:"date_format"` // e.g. DD/MM/YYYY, empty accepts the usual formats
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+func (SalesImportTemplate) TableName() string {
+	return "sales_import_templates"
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SalesImport records one POS export imported into sales.
+type SalesImport struct {
+	ID            uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	BranchID      string     `gorm:"type:uuid;not null;index" json:"branch_id"`
+	Source        string     `gorm:"type:varchar(20);not null" json:"source"` // quinos, iseller, csv, webhook
+	Date          *time.Time `gorm:"type:date" json:"date,omitempty"`         // day covered by single-day exports
+	FileName      string     `gorm:"type:varchar(255)" json:"file_name"`
+	LinesRead     int        `gorm:"not null;default:0" json:"lines_read"`
+	ImportedCount int        `gorm:"not null;default:0" json:"imported_count"`
+	SkippedCodes  string     `gorm:"type:text" json:"skipped_codes"` // unmapped product codes left out by a forced import
+	Forced        bool       `gorm:"not null;default:false" json:"forced"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+func (SalesImport) TableName() string {
+	return "sales_imports"
+}
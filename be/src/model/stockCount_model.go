@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type StockCount struct {
+	ID        uuid.UUID        `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	BranchID  string           `gorm:"type:uuid;not null;index" json:"branch_id"`
+	CountedAt time.Time        `gorm:"not null" json:"counted_at"`
+	CountedBy *string          `gorm:"type:uuid" json:"counted_by,omitempty"`
+	Note      string           `gorm:"type:text" json:"note"`
+	Lines     []StockCountLine `gorm:"foreignKey:StockCountID" json:"lines,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+func (StockCount) TableName() string {
+	return "stock_counts"
+}
+
+type StockCountLine struct {
+	ID              uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	StockCountID    string    `gorm:"type:uuid;not null;index" json:"stock_count_id"`
+	ItemID          string    `gorm:"type:uuid;not null;index" json:"item_id"`
+	Item            *Item     `gorm:"foreignKey:ItemID" json:"item,omitempty"`
+	SystemQuantity  float64   `gorm:"not null" json:"system_quantity"` // book stock when counted
+	CountedQuantity float64   `gorm:"not null" json:"counted_quantity"`
+	Difference      float64   `gorm:"not null" json:"difference"` // counted minus system
+	UnitCost        float64   `gorm:"not null;default:0" json:"unit_cost"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+func (StockCountLine) TableName() string {
+	return "stock_count_lines"
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type StockReservation struct {
+	ID         uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	BranchID   string     `gorm:"type:uuid;not null;index" json:"branch_id"`
+	ItemID     string     `gorm:"type:uuid;not null;index" json:"item_id"`
+	Item       *Item      `gorm:"foreignKey:ItemID" json:"item,omitempty"`
+	Quantity   float64    `gorm:"not null" json:"quantity"`                                                         // in the item's stock unit
+	SourceType string     `gorm:"type:varchar(30);not null;index:idx_stock_reservations_source" json:"source_type"` // production_order, catering_order, manual
+	SourceID   string     `gorm:"type:uuid;not null;index:idx_stock_reservations_source" json:"source_id"`
+	Status     string     `gorm:"type:varchar(20);not null;default:active" json:"status"` // active, consumed, released
+	ExpiresAt  *time.Time `json:"expires_at"`
+	Note       string     `gorm:"type:text" json:"note"`
+	CreatedBy  *string    `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func (StockReservation) TableName() string {
+	return "stock_reservations"
+}
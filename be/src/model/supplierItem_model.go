@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SupplierItem is an item a supplier sells. It is ordered in purchase units
+// of pack size pack units each, e.g. a sack of 25 kg.
+type SupplierItem struct {
+	ID           uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	SupplierID   string     `gorm:"type:uuid;not null;index" json:"supplier_id"`
+	Supplier     *Supplier  `gorm:"foreignKey:SupplierID" json:"supplier,omitempty"`
+	ItemID       string     `gorm:"type:uuid;not null;index" json:"item_id"`
+	Item         *Item      `gorm:"foreignKey:ItemID" json:"item,omitempty"`
+	PurchaseUnit string     `gorm:"type:varchar(50);not null" json:"purchase_unit"`
+	PackSize     float64    `gorm:"not null;default:1" json:"pack_size"`
+	PackUnit     string     `gorm:"type:varchar(50);not null" json:"pack_unit"`
+	MOQ          float64    `gorm:"column:moq;not null;default:0" json:"moq"` // in purchase units
+	LeadTime     *int       `json:"lead_time"`                                // days; nil uses the item's lead time
+	Preferred    bool       `gorm:"not null;default:false" json:"preferred"`
+	CreatedBy    *string    `gorm:"type:uuid" json:"created_by,omitempty"`
+	UpdatedBy    *string    `gorm:"type:uuid" json:"updated_by,omitempty"`
+	DeletedBy    *string    `gorm:"type:uuid" json:"deleted_by,omitempty"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+
+	Price         *float64 `gorm:"-" json:"price"`          // per purchase unit, effective at the asked date; nil without a price yet
+	EffectiveFrom *string  `gorm:"-" json:"effective_from"` // of that price
+	StockPerUnit  float64  `gorm:"-" json:"stock_per_unit"` // stock units of the item in one purchase unit
+	StockPrice    *float64 `gorm:"-" json:"stock_price"`    // price per stock unit of the item
+}
+
+func (SupplierItem) TableName() string {
+	return "supplier_items"
+}
+
+type SupplierItemPrice struct {
+	ID             uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	SupplierItemID string    `gorm:"type:uuid;not null;index" json:"supplier_item_id"`
+	Price          float64   `gorm:"not null" json:"price"` // per purchase unit
+	EffectiveFrom  time.Time `gorm:"type:date;not null" json:"effective_from"`
+	CreatedBy      *string   `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func (SupplierItemPrice) TableName() string {
+	return "supplier_item_prices"
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Supplier struct {
+	ID             uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Name           string     `gorm:"type:varchar(255);not null" json:"name"`
+	Slug           string     `gorm:"type:varchar(255);not null" json:"slug"` // unique among suppliers that are not deleted
+	Address        string     `gorm:"type:text;not null;default:''" json:"address"`
+	WhatsappNumber string     `gorm:"type:varchar(30);not null;default:''" json:"whatsapp_number"`
+	PhoneNumber    string     `gorm:"type:varchar(30);not null;default:''" json:"phone_number"`
+	CreatedBy      *string    `gorm:"type:uuid" json:"created_by,omitempty"`
+	UpdatedBy      *string    `gorm:"type:uuid" json:"updated_by,omitempty"`
+	DeletedBy      *string    `gorm:"type:uuid" json:"deleted_by,omitempty"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (Supplier) TableName() string {
+	return "suppliers"
+}
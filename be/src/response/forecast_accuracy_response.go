@@ -0,0 +1,47 @@
+package response
+
+type ForecastAccuracyMetrics struct {
+	Points  int      `json:"points"`
+	MAPE    *float64 `json:"mape"` // percent, days without sales left out
+	MAE     float64  `json:"mae"`
+	Bias    float64  `json:"bias"`     // servings per day, positive when forecasts were too high
+	HitRate float64  `json:"hit_rate"` // percent of days within 20% or one serving
+}
+
+type RecipeForecastAccuracy struct {
+	RecipeID   string `json:"recipe_id"`
+	RecipeCode string `json:"recipe_code"`
+	RecipeName string `json:"recipe_name"`
+	ForecastAccuracyMetrics
+}
+
+type ForecastAccuracyReport struct {
+	BranchID  string                   `json:"branch_id"`
+	RunID     string                   `json:"run_id,omitempty"`
+	StartDate string                   `json:"start_date"`
+	EndDate   string                   `json:"end_date"`
+	Overall   ForecastAccuracyMetrics  `json:"overall"`
+	Recipes   []RecipeForecastAccuracy `json:"recipes"`
+}
+
+type ForecastModelScore struct {
+	Model string `json:"model"`
+	ForecastAccuracyMetrics
+}
+
+type RecipeBacktest struct {
+	RecipeID   string               `json:"recipe_id"`
+	RecipeCode string               `json:"recipe_code"`
+	RecipeName string               `json:"recipe_name"`
+	BestModel  string               `json:"best_model"`
+	Models     []ForecastModelScore `json:"models"`
+}
+
+type ForecastBacktestReport struct {
+	BranchID    string           `json:"branch_id"`
+	HistoryDays int              `json:"history_days"`
+	Horizon     int              `json:"horizon"`
+	Folds       int              `json:"folds"`
+	Applied     bool             `json:"applied"` // best models saved for auto forecasts
+	Recipes     []RecipeBacktest `json:"recipes"`
+}
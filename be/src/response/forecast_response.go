@@ -0,0 +1,45 @@
+package response
+
+import "time"
+
+// ForecastShortItem is an ingredient the stock cannot cover: Quantity is what
+// the forecast needs, Stock what is available.
+type ForecastShortItem struct {
+	ID             string              `json:"id"`
+	BranchID       string              `json:"branch_id"`
+	Code           string              `json:"code"`
+	Name           string              `json:"name"`
+	Type           string              `json:"type"`
+	Unit           string              `json:"unit"`
+	Stock          float64             `json:"stock"`
+	LeadTime       int                 `json:"lead_time"`
+	Quantity       float64             `json:"quantity"`
+	NotEnoughItems []ForecastShortItem `json:"not_enough_items"`
+}
+
+type ForecastItem struct {
+	RecipeCode          string              `json:"recipe_code"`
+	RecipeName          string              `json:"recipe_name"`
+	Type                string              `json:"type"` // real, forecast
+	Value               float64             `json:"value"`
+	IsIngredientsEnough bool                `json:"is_ingredients_enough"`
+	NotEnoughItems      []ForecastShortItem `json:"not_enough_items"`
+	Overridden          bool                `json:"overridden,omitempty"`        // set by a planner
+	IngredientsError    string              `json:"ingredients_error,omitempty"` // why the ingredients could not be checked
+}
+
+type ForecastData struct {
+	Date   string         `json:"date"`
+	Total  float64        `json:"total"`
+	Type   string         `json:"type"` // real, forecast
+	Events []string       `json:"events,omitempty"`
+	Items  []ForecastItem `json:"items"`
+}
+
+type ForecastProcessed struct {
+	Code        int            `json:"code"`
+	Status      string         `json:"status"`
+	Message     string         `json:"message"`
+	Data        []ForecastData `json:"data"`
+	RetrievedAt time.Time      `json:"retrieved_at"`
+}
@@ -0,0 +1,13 @@
+package response
+
+type ItemUsage struct {
+	RecipeID   string   `json:"recipe_id"`
+	RecipeCode string   `json:"recipe_code"`
+	RecipeName string   `json:"recipe_name"`
+	RecipeType string   `json:"recipe_type"`
+	Quantity   float64  `json:"quantity"` // of the item or, when indirect, of the first sub-recipe item in Via
+	Unit       string   `json:"unit"`
+	Direct     bool     `json:"direct"`
+	Via        []string `json:"via,omitempty"` // codes of the half-finished recipes in between
+	Substitute bool     `json:"substitute,omitempty"`
+}
@@ -0,0 +1,47 @@
+package response
+
+type ClonedRecipe struct {
+	SourceRecipeID string `json:"source_recipe_id"`
+	RecipeID       string `json:"recipe_id"`
+	Code           string `json:"code"`
+	Name           string `json:"name"`
+}
+
+type SkippedRecipe struct {
+	SourceRecipeID string `json:"source_recipe_id"`
+	Code           string `json:"code"`
+	Reason         string `json:"reason"`
+}
+
+type ClonedItem struct {
+	ItemID string `json:"item_id"`
+	Code   string `json:"code"`
+	Name   string `json:"name"`
+}
+
+type UnresolvedIngredient struct {
+	RecipeCode string  `json:"recipe_code"`
+	ItemCode   string  `json:"item_code"`
+	ItemName   string  `json:"item_name"`
+	Quantity   float64 `json:"quantity"`
+	Unit       string  `json:"unit"`
+	Reason     string  `json:"reason"`
+}
+
+type UnresolvedSubstitute struct {
+	RecipeCode         string `json:"recipe_code"`
+	SubstituteItemCode string `json:"substitute_item_code"`
+	SubstituteItemName string `json:"substitute_item_name"`
+	Reason             string `json:"reason"`
+}
+
+type RecipeCloneReport struct {
+	SourceBranchID string                 `json:"source_branch_id"`
+	TargetBranchID string                 `json:"target_branch_id"`
+	Cloned         []ClonedRecipe         `json:"cloned"`
+	Skipped        []SkippedRecipe        `json:"skipped"`
+	CreatedItems   []ClonedItem           `json:"created_items"`
+	Unresolved     []UnresolvedIngredient `json:"unresolved"`
+
+	UnresolvedSubstitutes []UnresolvedSubstitute `json:"unresolved_substitutes"`
+}
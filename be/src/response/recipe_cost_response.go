@@ -0,0 +1,29 @@
+package response
+
+type RecipeIngredientCost struct {
+	ItemID        string  `json:"item_id"`
+	ItemCode      string  `json:"item_code"`
+	ItemName      string  `json:"item_name"`
+	Quantity      float64 `json:"quantity"`
+	Unit          string  `json:"unit"`
+	StockQuantity float64 `json:"stock_quantity"`
+	StockUnit     string  `json:"stock_unit"`
+	UnitCost      float64 `json:"unit_cost"`
+	TotalCost     float64 `json:"total_cost"`
+	CostSource    string  `json:"cost_source"` // supplier, item, sub_recipe
+}
+
+type RecipeCostResponse struct {
+	RecipeID       string                 `json:"recipe_id"`
+	RecipeCode     string                 `json:"recipe_code"`
+	RecipeName     string                 `json:"recipe_name"`
+	BranchID       string                 `json:"branch_id"`
+	CostPerServing float64                `json:"cost_per_serving"`
+	SellingPrice   float64                `json:"selling_price"`
+	FoodCostPct    float64                `json:"food_cost_pct"`
+	GrossMargin    float64                `json:"gross_margin"`
+	GrossMarginPct float64                `json:"gross_margin_pct"`
+	Ingredients    []RecipeIngredientCost `json:"ingredients"`
+	Incomplete     bool                   `json:"incomplete"` // some lines could not be costed, see warnings
+	Warnings       []string               `json:"warnings,omitempty"`
+}
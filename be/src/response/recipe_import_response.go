@@ -0,0 +1,33 @@
+package response
+
+type RecipeExportIngredient struct {
+	ItemCode string  `json:"item_code"`
+	ItemName string  `json:"item_name,omitempty"`
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit"`
+}
+
+type RecipeExport struct {
+	Code        string                   `json:"code"`
+	Name        string                   `json:"name"`
+	Type        string                   `json:"type"`
+	Description string                   `json:"description"`
+	Instruction string                   `json:"instructions"`
+	Yield       float64                  `json:"yield"`
+	Ingredients []RecipeExportIngredient `json:"ingredients"`
+}
+
+type RecipeImportError struct {
+	Line       int    `json:"line"`
+	RecipeCode string `json:"recipe_code,omitempty"`
+	Field      string `json:"field,omitempty"`
+	Message    string `json:"message"`
+}
+
+type RecipeImportReport struct {
+	DryRun  bool                `json:"dry_run"`
+	Total   int                 `json:"total"`
+	Created []string            `json:"created"`
+	Updated []string            `json:"updated"`
+	Errors  []RecipeImportError `json:"errors"`
+}
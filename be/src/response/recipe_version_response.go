@@ -0,0 +1,26 @@
+package response
+
+type RecipeFieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+type RecipeIngredientChange struct {
+	ItemID       string  `json:"item_id"`
+	ItemCode     string  `json:"item_code"`
+	ItemName     string  `json:"item_name"`
+	Change       string  `json:"change"` // added, removed, changed
+	FromQuantity float64 `json:"from_quantity"`
+	ToQuantity   float64 `json:"to_quantity"`
+	FromUnit     string  `json:"from_unit,omitempty"`
+	ToUnit       string  `json:"to_unit,omitempty"`
+}
+
+type RecipeVersionDiffResponse struct {
+	RecipeID    string                   `json:"recipe_id"`
+	FromVersion int                      `json:"from_version"`
+	ToVersion   int                      `json:"to_version"`
+	Fields      []RecipeFieldChange      `json:"fields"`
+	Ingredients []RecipeIngredientChange `json:"ingredients"`
+}
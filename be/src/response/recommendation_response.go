@@ -0,0 +1,43 @@
+package response
+
+type SupplierRecommendation struct {
+	SupplierName     string  `json:"supplier_name"`
+	Contact          string  `json:"contact"`
+	PricePerUnit     float64 `json:"price_per_unit"`
+	MinimumOrder     float64 `json:"minimum_order"`
+	DeliveryTime     string  `json:"delivery_time"`
+	QualityRating    float64 `json:"quality_rating"`    // out of 5; not tracked yet, always the neutral 3
+	ReliabilityScore float64 `json:"reliability_score"` // percent of received orders on time; a neutral 50 without any
+}
+
+// IngredientRecommendation is what to buy of an ingredient, in its stock
+// unit, and why.
+type IngredientRecommendation struct {
+	IngredientCode          string                   `json:"ingredient_code"`
+	IngredientName          string                   `json:"ingredient_name"`
+	RequiredQuantity        float64                  `json:"required_quantity"`
+	Unit                    string                   `json:"unit"`
+	UrgencyLevel            string                   `json:"urgency_level"` // low, medium, high, critical
+	EstimatedCost           float64                  `json:"estimated_cost"`
+	OrderDate               string                   `json:"order_date"` // when to order at the latest
+	NeedDate                string                   `json:"need_date"`  // when stock runs out
+	SupplierRecommendations []SupplierRecommendation `json:"supplier_recommendations"`
+	Reasons                 []string                 `json:"reasons"`
+	AffectedRecipes         []string                 `json:"affected_recipes"` // "CODE - Name"
+}
+
+type PurchaseRecommendationSummary struct {
+	TotalRecipesAnalyzed int      `json:"total_recipes_analyzed"`
+	ForecastPeriod       string   `json:"forecast_period"`
+	ConfidenceScore      float64  `json:"confidence_score"` // hit rate of the recent forecasts, percent
+	Notes                []string `json:"notes"`
+}
+
+type PurchaseRecommendation struct {
+	TotalEstimatedCost float64                       `json:"total_estimated_cost"`
+	UrgentItemsCount   int                           `json:"urgent_items_count"` // high and critical
+	RecommendationDate string                        `json:"recommendation_date"`
+	ValidityPeriod     string                        `json:"validity_period"`
+	Ingredients        []IngredientRecommendation    `json:"ingredients"`
+	Summary            PurchaseRecommendationSummary `json:"summary"`
+}
@@ -0,0 +1,55 @@
+package response
+
+// RequirementDay is one day of an item's plan. Quantities are in the item's
+// stock unit.
+type RequirementDay struct {
+	Date              string  `json:"date"`
+	Gross             float64 `json:"gross"`              // needed by the forecast, and by planned production of half-finished items
+	ScheduledReceipts float64 `json:"scheduled_receipts"` // open purchase orders and released production
+	Net               float64 `json:"net"`                // not covered by stock or receipts
+	Projected         float64 `json:"projected"`          // available at the end of the day, after the planned orders
+}
+
+// PlannedOrder tells when to order (or start producing) how much of an item
+// so it is there on the day it is needed.
+type PlannedOrder struct {
+	OrderDate string  `json:"order_date"`
+	NeedDate  string  `json:"need_date"`
+	Quantity  float64 `json:"quantity"`
+	Late      bool    `json:"late"` // the lead time has already passed; order today
+}
+
+type ItemRequirement struct {
+	ItemID   string           `json:"item_id"`
+	ItemCode string           `json:"item_code"`
+	ItemName string           `json:"item_name"`
+	Unit     string           `json:"unit"`
+	LeadTime int              `json:"lead_time"`
+	Source   string           `json:"source"` // purchase, or production for half-finished items
+	OnHand   float64          `json:"on_hand"`
+	Reserved float64          `json:"reserved"`
+	OnOrder  float64          `json:"on_order"`
+	Gross    float64          `json:"gross"`
+	Net      float64          `json:"net"`
+	Recipes  []string         `json:"recipes"` // codes of the recipes that need the item
+	Days     []RequirementDay `json:"days"`
+	Orders   []PlannedOrder   `json:"orders"`
+}
+
+// UnplannedRecipe is a recipe the plan could not explode into its
+// ingredients, so its demand is missing from the items.
+type UnplannedRecipe struct {
+	RecipeCode string `json:"recipe_code"`
+	RecipeName string `json:"recipe_name"`
+	Reason     string `json:"reason"`
+}
+
+type RequirementsPlan struct {
+	BranchID       string            `json:"branch_id"`
+	RunID          string            `json:"run_id"`
+	StartDate      string            `json:"start_date"`
+	Horizon        int               `json:"horizon"`
+	RecipeCount    int               `json:"recipe_count"` // recipes with a forecast in the horizon
+	Items          []ItemRequirement `json:"items"`
+	SkippedRecipes []UnplannedRecipe `json:"skipped_recipes,omitempty"`
+}
@@ -0,0 +1,21 @@
+package response
+
+type SaleImportError struct {
+	Line    int    `json:"line"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+type SaleImportReport struct {
+	ImportID             string            `json:"import_id,omitempty"`
+	Source               string            `json:"source"`
+	Dates                []string          `json:"dates"`
+	LinesRead            int               `json:"lines_read"`
+	Imported             int               `json:"imported"`
+	SkippedLines         int               `json:"skipped_lines"` // unmapped products and zero or negative quantities
+	Duplicates           int               `json:"duplicates"`    // lines imported before
+	Forced               bool              `json:"forced"`
+	NotFoundProductCodes []string          `json:"not_found_product_codes"`
+	IgnoredModifiers     []string          `json:"ignored_modifiers"` // modifiers that do not map to a recipe
+	Errors               []SaleImportError `json:"errors,omitempty"`
+}
@@ -0,0 +1,67 @@
+package response
+
+import "time"
+
+// UsageVarianceLine compares what an item should have been used for (sales and
+// production times recipes) with what left the stock in the period. Quantities
+// are in the item's stock unit, values in its unit cost.
+type UsageVarianceLine struct {
+	ItemID           string     `json:"item_id,omitempty"` // empty when rolled up across branches
+	BranchID         string     `json:"branch_id,omitempty"`
+	ItemCode         string     `json:"item_code"`
+	ItemName         string     `json:"item_name"`
+	Unit             string     `json:"unit"`
+	Branches         int        `json:"branches"`
+	Opening          float64    `json:"opening"`
+	Purchases        float64    `json:"purchases"`
+	TransfersIn      float64    `json:"transfers_in"`
+	TransfersOut     float64    `json:"transfers_out"`
+	Produced         float64    `json:"produced"`
+	Closing          float64    `json:"closing"`
+	CountAdjustment  float64    `json:"count_adjustment"` // found (+) or missing (-) at stock counts
+	ActualUsage      float64    `json:"actual_usage"`
+	TheoreticalUsage float64    `json:"theoretical_usage"`
+	Waste            float64    `json:"waste"`
+	Variance         float64    `json:"variance"` // actual usage not explained by sales, production or waste
+	VariancePercent  *float64   `json:"variance_percent"`
+	ActualValue      float64    `json:"actual_value"`
+	TheoreticalValue float64    `json:"theoretical_value"`
+	WasteValue       float64    `json:"waste_value"`
+	VarianceValue    float64    `json:"variance_value"`
+	Counted          bool       `json:"counted"` // counted within the period
+	LastCountedAt    *time.Time `json:"last_counted_at"`
+}
+
+// ExcludedUsageLine is a recipe line left out of the theoretical usage because
+// its unit does not convert to the item's stock unit.
+type ExcludedUsageLine struct {
+	RecipeID   string `json:"recipe_id"`
+	RecipeName string `json:"recipe_name"`
+	ItemID     string `json:"item_id"`
+	ItemCode   string `json:"item_code"`
+	ItemName   string `json:"item_name"`
+	Unit       string `json:"unit"`
+	Reason     string `json:"reason"`
+}
+
+type UsageVarianceBranch struct {
+	BranchID         string  `json:"branch_id"`
+	BranchName       string  `json:"branch_name"`
+	ActualValue      float64 `json:"actual_value"`
+	TheoreticalValue float64 `json:"theoretical_value"`
+	WasteValue       float64 `json:"waste_value"`
+	VarianceValue    float64 `json:"variance_value"`
+}
+
+type UsageVarianceReport struct {
+	BranchID         string                `json:"branch_id,omitempty"`
+	StartDate        string                `json:"start_date"`
+	EndDate          string                `json:"end_date"`
+	Lines            []UsageVarianceLine   `json:"lines"`
+	Branches         []UsageVarianceBranch `json:"branches"`
+	ActualValue      float64               `json:"actual_value"`
+	TheoreticalValue float64               `json:"theoretical_value"`
+	WasteValue       float64               `json:"waste_value"`
+	VarianceValue    float64               `json:"variance_value"`
+	Excluded         []ExcludedUsageLine   `json:"excluded,omitempty"` // recipe lines missing from the theoretical usage
+}
@@ -0,0 +1,21 @@
+package router
+
+import (
+	"app/src/controller"
+	m "app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func CalendarEventRoutes(v1 fiber.Router, calendarEventService service.CalendarEventService, u service.UserService) {
+	calendarEventController := controller.NewCalendarEventController(calendarEventService)
+
+	// signed in, so the audit fields name the user
+	events := v1.Group("/calendar-events", m.Auth(u))
+
+	events.Get("/", calendarEventController.GetAll)
+	events.Post("/", calendarEventController.Create)
+	events.Put("/:id", calendarEventController.Update)
+	events.Delete("/:id", calendarEventController.Delete)
+}
@@ -0,0 +1,18 @@
+package router
+
+import (
+	"app/src/controller"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func CookRecordRoutes(v1 fiber.Router, cookRecordService service.CookRecordService) {
+	cookRecordController := controller.NewCookRecordController(cookRecordService)
+
+	cookRecords := v1.Group("/cook-records")
+
+	cookRecords.Get("/", cookRecordController.GetAll)
+	cookRecords.Get("/:id", cookRecordController.GetByID)
+	cookRecords.Post("/:id/reverse", cookRecordController.Reverse)
+}
@@ -0,0 +1,31 @@
+package router
+
+import (
+	"app/src/controller"
+	m "app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func ForecastRoutes(v1 fiber.Router, forecastService service.ForecastService, u service.UserService) {
+	forecastController := controller.NewForecastController(forecastService)
+
+	forecast := v1.Group("/forecast")
+
+	forecast.Get("/processed", forecastController.GetProcessed)
+	forecast.Post("/runs", forecastController.CreateRun)
+	forecast.Get("/runs", forecastController.GetRuns)
+	forecast.Get("/runs/:id", forecastController.GetRunByID)
+	forecast.Get("/runs/:id/accuracy", forecastController.GetRunAccuracy)
+	forecast.Get("/accuracy", forecastController.GetAccuracy)
+	forecast.Post("/backtest", forecastController.Backtest)
+
+	// signed in, so the audit trail names the user
+	overrides := forecast.Group("/overrides", m.Auth(u))
+
+	overrides.Get("/", forecastController.GetOverrides)
+	overrides.Put("/", forecastController.SetOverride)
+	overrides.Get("/audit", forecastController.GetOverrideAudits)
+	overrides.Delete("/:id", forecastController.RemoveOverride)
+}
@@ -29,4 +29,5 @@ func ItemRoutes(v1 fiber.Router, itemService service.ItemService, itemTransactio
 	items.Put("/:id", itemController.Update)
 	items.Delete("/:id", itemController.Delete)
 	items.Get("/:id", itemController.GetByID)
+	items.Get("/:id/where-used", itemController.GetWhereUsed)
 }
\ No newline at end of file
@@ -0,0 +1,16 @@
+package router
+
+import (
+	"app/src/controller"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func PlanningRoutes(v1 fiber.Router, planningService service.PlanningService) {
+	planningController := controller.NewPlanningController(planningService)
+
+	planning := v1.Group("/planning")
+
+	planning.Get("/requirements", planningController.Requirements)
+}
@@ -0,0 +1,34 @@
+package router
+
+import (
+	"app/src/controller"
+	m "app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func PosIntegrationRoutes(
+	v1 fiber.Router, posIntegrationService service.PosIntegrationService,
+	posWebhookService service.PosWebhookService, u service.UserService,
+) {
+	posIntegrationController := controller.NewPosIntegrationController(posIntegrationService, posWebhookService)
+
+	integrations := v1.Group("/pos-integrations")
+
+	// creating and rotating hand out the signing secret
+	integrations.Post("/", m.Auth(u, "manageIntegrations"), posIntegrationController.Create)
+	integrations.Get("/", m.Auth(u), posIntegrationController.GetAll)
+	integrations.Patch("/:id", m.Auth(u, "manageIntegrations"), posIntegrationController.Update)
+	integrations.Post("/:id/rotate-secret", m.Auth(u, "manageIntegrations"), posIntegrationController.RotateSecret)
+	integrations.Get("/:id/events", m.Auth(u), posIntegrationController.GetIntegrationEvents)
+	integrations.Get("/:id/events/:eventId", m.Auth(u), posIntegrationController.GetIntegrationEvent)
+
+	webhooks := v1.Group("/webhooks/pos")
+
+	// called by the POS without a user session: signed with the integration
+	// secret instead, see PosWebhookService
+	webhooks.Post("/:integrationId", posIntegrationController.Receive)
+	webhooks.Get("/:integrationId/events", posIntegrationController.GetEvents)
+	webhooks.Get("/:integrationId/events/:eventId", posIntegrationController.GetEvent)
+}
@@ -0,0 +1,18 @@
+package router
+
+import (
+	"app/src/controller"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func PosProductMappingRoutes(v1 fiber.Router, posProductMappingService service.PosProductMappingService) {
+	posProductMappingController := controller.NewPosProductMappingController(posProductMappingService)
+
+	mappings := v1.Group("/pos-product-mappings")
+
+	mappings.Get("/", posProductMappingController.GetAll)
+	mappings.Put("/", posProductMappingController.Save)
+	mappings.Delete("/:id", posProductMappingController.Delete)
+}
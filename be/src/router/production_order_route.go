@@ -0,0 +1,21 @@
+package router
+
+import (
+	"app/src/controller"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func ProductionOrderRoutes(v1 fiber.Router, productionOrderService service.ProductionOrderService) {
+	productionOrderController := controller.NewProductionOrderController(productionOrderService)
+
+	productionOrders := v1.Group("/production-orders")
+
+	productionOrders.Post("/", productionOrderController.Create)
+	productionOrders.Get("/", productionOrderController.GetAll)
+	productionOrders.Get("/:id", productionOrderController.GetByID)
+	productionOrders.Post("/:id/release", productionOrderController.Release)
+	productionOrders.Post("/:id/complete", productionOrderController.Complete)
+	productionOrders.Post("/:id/cancel", productionOrderController.Cancel)
+}
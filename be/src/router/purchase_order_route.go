@@ -0,0 +1,22 @@
+package router
+
+import (
+	"app/src/controller"
+	m "app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func PurchaseOrderRoutes(v1 fiber.Router, purchaseOrderService service.PurchaseOrderService, u service.UserService) {
+	purchaseOrderController := controller.NewPurchaseOrderController(purchaseOrderService)
+
+	// signed in, so the orders name the user who placed them
+	purchaseOrders := v1.Group("/purchase-orders", m.Auth(u))
+
+	purchaseOrders.Post("/", purchaseOrderController.Create)
+	purchaseOrders.Get("/", purchaseOrderController.GetAll)
+	purchaseOrders.Get("/:id", purchaseOrderController.GetByID)
+	purchaseOrders.Post("/:id/receive", purchaseOrderController.Receive)
+	purchaseOrders.Post("/:id/cancel", purchaseOrderController.Cancel)
+}
@@ -7,16 +7,46 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-func RecipeRoutes(v1 fiber.Router, recipeService service.RecipeService) {
-	recipeController := controller.NewRecipeController(recipeService)
+func RecipeRoutes(
+	v1 fiber.Router,
+	recipeService service.RecipeService,
+	recipeCostService service.RecipeCostService,
+	recipeVersionService service.RecipeVersionService,
+	recipeImportService service.RecipeImportService,
+	recipeSubstituteService service.RecipeSubstituteService,
+) {
+	recipeController := controller.NewRecipeController(
+		recipeService,
+		recipeCostService,
+		recipeVersionService,
+		recipeImportService,
+		recipeSubstituteService,
+	)
 
 	recipes := v1.Group("/recipes")
 
 	recipes.Post("/", recipeController.Create)
 	recipes.Get("/", recipeController.GetAll)
+	recipes.Get("/max-servings", recipeController.GetMaxServings)
+	recipes.Post("/clone", recipeController.Clone)
+	recipes.Get("/export", recipeController.Export)
+	recipes.Post("/import", recipeController.Import)
 	recipes.Get("/:id", recipeController.GetByID)
 	recipes.Put("/:id", recipeController.Update)
 	recipes.Delete("/:id", recipeController.Delete)
 
 	recipes.Post("/:id/cook", recipeController.Cook)
+	recipes.Post("/:id/cook/check", recipeController.CheckCook)
+
+	recipes.Get("/:id/cost", recipeController.GetCost)
+	recipes.Get("/:id/cost-history", recipeController.GetCostHistory)
+	recipes.Put("/:id/price", recipeController.SetPrice)
+
+	recipes.Get("/:id/substitutes", recipeController.GetSubstitutes)
+	recipes.Put("/:id/substitutes", recipeController.SetSubstitutes)
+
+	recipes.Get("/:id/versions", recipeController.GetVersions)
+	recipes.Get("/:id/versions/diff", recipeController.DiffVersions)
+	recipes.Get("/:id/versions/:version", recipeController.GetVersion)
+	recipes.Post("/:id/versions/:version/restore", recipeController.RestoreVersion)
 }
\ No newline at end of file
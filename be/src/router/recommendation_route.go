@@ -0,0 +1,18 @@
+package router
+
+import (
+	"app/src/controller"
+	m "app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func RecommendationRoutes(r fiber.Router, recommendationService service.RecommendationService, u service.UserService) {
+	recommendationController := controller.NewRecommendationController(recommendationService)
+
+	recommendations := r.Group("/recommendations")
+
+	// signed in, so the branch can default to the user's active branch
+	recommendations.Post("/ingredients", m.Auth(u), recommendationController.Ingredients)
+}
@@ -0,0 +1,16 @@
+package router
+
+import (
+	"app/src/controller"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func ReportRoutes(v1 fiber.Router, varianceReportService service.VarianceReportService) {
+	reportController := controller.NewReportController(varianceReportService)
+
+	reports := v1.Group("/reports")
+
+	reports.Get("/usage-variance", reportController.UsageVariance)
+}
@@ -21,6 +21,32 @@ func Routes(app *fiber.App, db *gorm.DB) {
 	itemService := service.NewItemService(db, validate)
 	itemTransactionService := service.NewItemTransactionService(db, validate)
 	recipeService := service.NewRecipeService(db, validate, itemService, itemTransactionService)
+	recipeCostService := service.NewRecipeCostService(db, validate)
+	recipeVersionService := service.NewRecipeVersionService(db, validate)
+	recipeImportService := service.NewRecipeImportService(db, validate)
+	recipeSubstituteService := service.NewRecipeSubstituteService(db, validate)
+	productionOrderService := service.NewProductionOrderService(db, validate)
+	stockReservationService := service.NewStockReservationService(db, validate)
+	cookRecordService := service.NewCookRecordService(db, validate)
+	saleService := service.NewSaleService(db, validate)
+	saleImportService := service.NewSaleImportService(db, validate)
+	posProductMappingService := service.NewPosProductMappingService(db, validate)
+	posIntegrationService := service.NewPosIntegrationService(db, validate)
+	posWebhookService := service.NewPosWebhookService(db, validate)
+	posWebhookService.Start()
+	app.Hooks().OnShutdown(func() error {
+		posWebhookService.Stop()
+		return nil
+	})
+	stockCountService := service.NewStockCountService(db, validate)
+	varianceReportService := service.NewVarianceReportService(db, validate)
+	forecastService := service.NewForecastService(db, validate)
+	calendarEventService := service.NewCalendarEventService(db, validate)
+	purchaseOrderService := service.NewPurchaseOrderService(db, validate)
+	planningService := service.NewPlanningService(db, validate)
+	recommendationService := service.NewRecommendationService(db, validate)
+	supplierService := service.NewSupplierService(db, validate)
+	supplierItemService := service.NewSupplierItemService(db, validate)
 
 	v1 := app.Group("/v1")
 
@@ -29,7 +55,23 @@ func Routes(app *fiber.App, db *gorm.DB) {
 	UserRoutes(v1, userService, tokenService)
 	BranchRoutes(v1, branchService)
 	ItemRoutes(v1, itemService, itemTransactionService)
-	RecipeRoutes(v1, recipeService)
+	RecipeRoutes(v1, recipeService, recipeCostService, recipeVersionService, recipeImportService, recipeSubstituteService)
+	ProductionOrderRoutes(v1, productionOrderService)
+	StockReservationRoutes(v1, stockReservationService)
+	CookRecordRoutes(v1, cookRecordService)
+	SaleRoutes(v1, saleService, saleImportService)
+	PosProductMappingRoutes(v1, posProductMappingService)
+	PosIntegrationRoutes(v1, posIntegrationService, posWebhookService, userService)
+	StockCountRoutes(v1, stockCountService)
+	ReportRoutes(v1, varianceReportService)
+	ForecastRoutes(v1, forecastService, userService)
+	CalendarEventRoutes(v1, calendarEventService, userService)
+	PurchaseOrderRoutes(v1, purchaseOrderService, userService)
+	PlanningRoutes(v1, planningService)
+	RecommendationRoutes(v1, recommendationService, userService)
+	// the dashboard calls the recommendations without the version prefix
+	RecommendationRoutes(app, recommendationService, userService)
+	SupplierRoutes(v1, supplierService, supplierItemService, userService)
 	// TODO: add another routes here...
 
 	if !config.IsProd {
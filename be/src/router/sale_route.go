@@ -0,0 +1,29 @@
+package router
+
+import (
+	"app/src/controller"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SaleRoutes(v1 fiber.Router, saleService service.SaleService, saleImportService service.SaleImportService) {
+	saleController := controller.NewSaleController(saleService, saleImportService)
+
+	sales := v1.Group("/sales")
+
+	sales.Post("/", saleController.Create)
+	sales.Get("/", saleController.GetAll)
+	sales.Post("/import-quinos", saleController.ImportQuinos)
+	sales.Post("/import-iseller", saleController.ImportIseller)
+	sales.Post("/import-csv", saleController.ImportCSV)
+	sales.Get("/import-templates", saleController.GetImportTemplates)
+	sales.Post("/import-templates", saleController.CreateImportTemplate)
+	sales.Put("/import-templates/:id", saleController.UpdateImportTemplate)
+	sales.Delete("/import-templates/:id", saleController.DeleteImportTemplate)
+	sales.Get("/date-range", saleController.GetByDateRange)
+	sales.Get("/recipe/:recipeId", saleController.GetByRecipe)
+	sales.Get("/:id", saleController.GetByID)
+	sales.Put("/:id", saleController.Update)
+	sales.Post("/:id/void", saleController.Void)
+}
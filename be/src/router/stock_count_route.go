@@ -0,0 +1,18 @@
+package router
+
+import (
+	"app/src/controller"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func StockCountRoutes(v1 fiber.Router, stockCountService service.StockCountService) {
+	stockCountController := controller.NewStockCountController(stockCountService)
+
+	stockCounts := v1.Group("/stock-counts")
+
+	stockCounts.Post("/", stockCountController.Create)
+	stockCounts.Get("/", stockCountController.GetAll)
+	stockCounts.Get("/:id", stockCountController.GetByID)
+}
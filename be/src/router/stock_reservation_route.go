@@ -0,0 +1,19 @@
+package router
+
+import (
+	"app/src/controller"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func StockReservationRoutes(v1 fiber.Router, stockReservationService service.StockReservationService) {
+	stockReservationController := controller.NewStockReservationController(stockReservationService)
+
+	reservations := v1.Group("/stock-reservations")
+
+	reservations.Post("/", stockReservationController.Create)
+	reservations.Get("/", stockReservationController.GetAll)
+	reservations.Get("/:id", stockReservationController.GetByID)
+	reservations.Post("/:id/release", stockReservationController.Release)
+}
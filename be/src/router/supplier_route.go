@@ -0,0 +1,38 @@
+package router
+
+import (
+	"app/src/controller"
+	m "app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SupplierRoutes(
+	v1 fiber.Router, supplierService service.SupplierService,
+	supplierItemService service.SupplierItemService, u service.UserService,
+) {
+	supplierController := controller.NewSupplierController(supplierService)
+	supplierItemController := controller.NewSupplierItemController(supplierItemService)
+
+	// signed in, so the audit fields name the user
+	suppliers := v1.Group("/suppliers", m.Auth(u))
+
+	suppliers.Get("/items/:itemId", supplierItemController.GetSuppliersForItem)
+	suppliers.Get("/items/:itemId/supplier", supplierItemController.GetItemSupplier)
+
+	suppliers.Post("/", supplierController.Create)
+	suppliers.Get("/", supplierController.GetAll)
+	suppliers.Get("/:id", supplierController.GetByID)
+	suppliers.Put("/:id", supplierController.Update)
+	suppliers.Patch("/:id", supplierController.Update)
+	suppliers.Delete("/:id", supplierController.Delete)
+
+	suppliers.Post("/:id/items", supplierItemController.Create)
+	suppliers.Get("/:id/items", supplierItemController.GetAll)
+	suppliers.Get("/:id/items/:itemId", supplierItemController.GetByItemID)
+	suppliers.Put("/:id/items/:itemId", supplierItemController.Update)
+	suppliers.Patch("/:id/items/:itemId", supplierItemController.Update)
+	suppliers.Delete("/:id/items/:itemId", supplierItemController.Delete)
+	suppliers.Get("/:id/items/:itemId/prices", supplierItemController.GetPrices)
+}
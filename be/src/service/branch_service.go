@@ -61,10 +61,14 @@ func (s *branchService) Create(c *fiber.Ctx, req *validation.CreateBranch) (*res
 	}
 
 	branch := &model.Branch{
-		Name:             req.Name,
-		Slug:             req.Slug,
-		PicEmails:        req.PicEmails,
-		PicPhoneNumbers:  req.PicPhoneNumbers,
+		Name:              req.Name,
+		Slug:              req.Slug,
+		PicEmails:         req.PicEmails,
+		PicPhoneNumbers:   req.PicPhoneNumbers,
+		SaleDepletionMode: req.SaleDepletionMode,
+	}
+	if branch.SaleDepletionMode == "" {
+		branch.SaleDepletionMode = SaleDepletionPreCooked
 	}
 
 	if err := s.DB.WithContext(c.Context()).Create(branch).Error; err != nil {
@@ -119,6 +123,9 @@ func (s *branchService) Update(c *fiber.Ctx, id uuid.UUID, req *validation.Updat
 	branch.Slug = req.Slug
 	branch.PicEmails = req.PicEmails
 	branch.PicPhoneNumbers = req.PicPhoneNumbers
+	if req.SaleDepletionMode != "" {
+		branch.SaleDepletionMode = req.SaleDepletionMode
+	}
 
 	if err := s.DB.Save(&branch).Error; err != nil {
 		return nil, err
@@ -179,14 +186,15 @@ func (s *branchService) mapBranchToResponse(b *model.Branch) *response.BranchRes
 	}
 
 	return &response.BranchResponse{
-		ID:              b.ID.String(),
-		Name:            b.Name,
-		Slug:            b.Slug,
-		PicEmails:       b.PicEmails,
-		PicPhoneNumbers: b.PicPhoneNumbers,
-		CreatedAt:       b.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:       b.UpdatedAt.Format(time.RFC3339),
-		DeletedAt:       deletedAt,
-		DeletedBy:       deletedBy,
+		ID:                b.ID.String(),
+		Name:              b.Name,
+		Slug:              b.Slug,
+		PicEmails:         b.PicEmails,
+		PicPhoneNumbers:   b.PicPhoneNumbers,
+		SaleDepletionMode: b.SaleDepletionMode,
+		CreatedAt:         b.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:         b.UpdatedAt.Format(time.RFC3339),
+		DeletedAt:         deletedAt,
+		DeletedBy:         deletedBy,
 	}
 }
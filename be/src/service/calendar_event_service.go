@@ -0,0 +1,237 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const CalendarEventClosure = "closure"
+
+type CalendarEventService interface {
+	GetEvents(c *fiber.Ctx, params *validation.QueryCalendarEvent) ([]model.CalendarEvent, int64, error)
+	CreateEvent(c *fiber.Ctx, req *validation.CreateCalendarEvent) (*model.CalendarEvent, error)
+	UpdateEvent(c *fiber.Ctx, id string, req *validation.UpdateCalendarEvent) (*model.CalendarEvent, error)
+	DeleteEvent(c *fiber.Ctx, id string) error
+}
+
+type calendarEventService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewCalendarEventService(db *gorm.DB, validate *validator.Validate) CalendarEventService {
+	return &calendarEventService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+func (s *calendarEventService) GetEvents(c *fiber.Ctx, params *validation.QueryCalendarEvent) ([]model.CalendarEvent, int64, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, 0, err
+	}
+
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 {
+		params.Limit = 10
+	}
+
+	query := s.DB.WithContext(c.Context()).Model(&model.CalendarEvent{}).Where("deleted_at IS NULL")
+	if params.BranchID != "" {
+		query = query.Where("branch_id = ? OR branch_id IS NULL", params.BranchID)
+	} else {
+		query = query.Where("branch_id IS NULL")
+	}
+	if params.Type != "" {
+		query = query.Where("type = ?", params.Type)
+	}
+	if params.StartDate != "" {
+		query = query.Where("end_date >= ?", params.StartDate)
+	}
+	if params.EndDate != "" {
+		query = query.Where("start_date <= ?", params.EndDate)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var events []model.CalendarEvent
+	if err := query.Order("start_date ASC, name ASC").
+		Offset((params.Page - 1) * params.Limit).
+		Limit(params.Limit).
+		Find(&events).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
+
+func (s *calendarEventService) CreateEvent(c *fiber.Ctx, req *validation.CreateCalendarEvent) (*model.CalendarEvent, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	event := model.CalendarEvent{
+		Name:   req.Name,
+		Type:   req.Type,
+		Uplift: req.Uplift,
+		Closed: req.Closed || req.Type == CalendarEventClosure,
+		Note:   req.Note,
+	}
+	event.StartDate, _ = time.Parse("2006-01-02", req.StartDate)
+	event.EndDate, _ = time.Parse("2006-01-02", req.EndDate)
+	if event.EndDate.Before(event.StartDate) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "end_date must not be before start_date")
+	}
+	event.CreatedBy = currentUserID(c)
+	event.UpdatedBy = event.CreatedBy
+
+	if req.BranchID != "" {
+		var branch model.Branch
+		if err := s.DB.WithContext(c.Context()).First(&branch, "id = ? AND deleted_at IS NULL", req.BranchID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fiber.NewError(fiber.StatusNotFound, "Branch not found")
+			}
+			return nil, err
+		}
+		event.BranchID = &req.BranchID
+	}
+
+	if err := s.DB.WithContext(c.Context()).Create(&event).Error; err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+func (s *calendarEventService) UpdateEvent(c *fiber.Ctx, id string, req *validation.UpdateCalendarEvent) (*model.CalendarEvent, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	event, err := s.findEvent(c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		event.Name = *req.Name
+	}
+	if req.Type != nil {
+		event.Type = *req.Type
+	}
+	if req.StartDate != nil {
+		event.StartDate, _ = time.Parse("2006-01-02", *req.StartDate)
+	}
+	if req.EndDate != nil {
+		event.EndDate, _ = time.Parse("2006-01-02", *req.EndDate)
+	}
+	if req.Uplift != nil {
+		event.Uplift = *req.Uplift
+	}
+	if req.Closed != nil {
+		event.Closed = *req.Closed
+	}
+	if req.Note != nil {
+		event.Note = *req.Note
+	}
+	event.UpdatedBy = currentUserID(c)
+	event.Closed = event.Closed || event.Type == CalendarEventClosure
+
+	if event.EndDate.Before(event.StartDate) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "end_date must not be before start_date")
+	}
+
+	if err := s.DB.WithContext(c.Context()).Save(event).Error; err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// DeleteEvent soft deletes the event. Its updated_at moves too, so the
+// forecast runs made before it are regenerated without it.
+func (s *calendarEventService) DeleteEvent(c *fiber.Ctx, id string) error {
+	event, err := s.findEvent(c, id)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	return s.DB.WithContext(c.Context()).Model(event).Updates(map[string]interface{}{
+		"deleted_at": now,
+		"deleted_by": currentUserID(c),
+		"updated_at": now,
+	}).Error
+}
+
+func (s *calendarEventService) findEvent(c *fiber.Ctx, id string) (*model.CalendarEvent, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid calendar event ID")
+	}
+
+	var event model.CalendarEvent
+	if err := s.DB.WithContext(c.Context()).First(&event, "id = ? AND deleted_at IS NULL", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "calendar event not found")
+		}
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+// calendarDays resolves the events of the branch, its own and those of every
+// branch, for each of the days from start: their combined multiplier, whether
+// the branch is closed, and their names. Overlapping uplifts compound.
+func calendarDays(db *gorm.DB, branchID string, start time.Time, days int) ([]utils.CalendarDay, [][]string, error) {
+	calendar := make([]utils.CalendarDay, days)
+	names := make([][]string, days)
+	for i := range calendar {
+		calendar[i].Multiplier = 1
+	}
+	if days <= 0 {
+		return calendar, names, nil
+	}
+
+	var events []model.CalendarEvent
+	if err := db.Where("(branch_id = ? OR branch_id IS NULL) AND deleted_at IS NULL AND start_date < ? AND end_date >= ?",
+		branchID, start.AddDate(0, 0, days).Format("2006-01-02"), start.Format("2006-01-02")).
+		Order("start_date ASC, name ASC").
+		Find(&events).Error; err != nil {
+		return nil, nil, err
+	}
+
+	for _, event := range events {
+		for day := 0; day < days; day++ {
+			date := start.AddDate(0, 0, day)
+			if date.Before(event.StartDate) || date.After(event.EndDate) {
+				continue
+			}
+
+			names[day] = append(names[day], event.Name)
+			calendar[day].Multiplier *= 1 + event.Uplift/100
+			if event.Closed || calendar[day].Multiplier <= 0 {
+				calendar[day].Closed = true
+			}
+		}
+	}
+
+	return calendar, names, nil
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	CookReference = "cook"
+
+	CookStatusCooked   = "cooked"
+	CookStatusReversed = "reversed"
+)
+
+type CookRecordService interface {
+	GetCookRecords(c *fiber.Ctx, params *validation.QueryCookRecord) ([]model.CookRecord, int64, error)
+	GetCookRecordByID(c *fiber.Ctx, id string) (*model.CookRecord, error)
+	ReverseCookRecord(c *fiber.Ctx, id string, req *validation.ReverseCookRecord) (*model.CookRecord, error)
+}
+
+type cookRecordService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewCookRecordService(db *gorm.DB, validate *validator.Validate) CookRecordService {
+	return &cookRecordService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+func (s *cookRecordService) GetCookRecords(c *fiber.Ctx, params *validation.QueryCookRecord) ([]model.CookRecord, int64, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, 0, err
+	}
+
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 {
+		params.Limit = 10
+	}
+
+	query := s.DB.WithContext(c.Context()).Model(&model.CookRecord{}).
+		Where("branch_id = ?", params.BranchID)
+
+	if params.RecipeID != "" {
+		query = query.Where("recipe_id = ?", params.RecipeID)
+	}
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+	if params.StartDate != "" {
+		query = query.Where("cooked_at >= ?", params.StartDate)
+	}
+	if params.EndDate != "" {
+		query = query.Where("cooked_at < ?::date + INTERVAL '1 day'", params.EndDate)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var records []model.CookRecord
+	if err := query.Preload("Recipe").
+		Order("cooked_at DESC").
+		Offset((params.Page - 1) * params.Limit).
+		Limit(params.Limit).
+		Find(&records).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, nil
+}
+
+func (s *cookRecordService) GetCookRecordByID(c *fiber.Ctx, id string) (*model.CookRecord, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid cook record ID")
+	}
+
+	db := s.DB.WithContext(c.Context())
+
+	var record model.CookRecord
+	if err := db.Preload("Recipe").First(&record, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "cook record not found")
+		}
+		return nil, err
+	}
+
+	if err := db.Preload("Item").
+		Where("reference_type = ? AND reference_id = ?", CookReference, record.ID).
+		Order("created_at ASC").
+		Find(&record.Movements).Error; err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// ReverseCookRecord puts back every ingredient the cook consumed and reserves
+// again what it drew from reservations, in a single transaction. The reversal
+// movements reference the same cook record.
+func (s *cookRecordService) ReverseCookRecord(c *fiber.Ctx, id string, req *validation.ReverseCookRecord) (*model.CookRecord, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid cook record ID")
+	}
+
+	err := s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		var record model.CookRecord
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&record, "id = ?", id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fiber.NewError(fiber.StatusNotFound, "cook record not found")
+			}
+			return err
+		}
+
+		if record.Status == CookStatusReversed {
+			return fiber.NewError(fiber.StatusBadRequest, "cook record is already reversed")
+		}
+
+		var movements []model.ItemTransaction
+		if err := tx.Where("reference_type = ? AND reference_id = ?", CookReference, record.ID).
+			Find(&movements).Error; err != nil {
+			return err
+		}
+
+		ref := &movementRef{Type: CookReference, ID: record.ID.String()}
+		note := fmt.Sprintf("Reversal of cook %s: %s", record.ID, req.Reason)
+
+		for _, movement := range movements {
+			var item model.Item
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				First(&item, "id = ?", movement.ItemID).Error; err != nil {
+				return err
+			}
+
+			txType := "in"
+			if isInboundMovement(movement.Type) {
+				if item.Stock < movement.Amount {
+					return fiber.NewError(fiber.StatusBadRequest,
+						fmt.Sprintf("cannot reverse: %s (%s) has only %.2f %s left", item.Name, item.Code, item.Stock, item.Unit))
+				}
+				txType = "out"
+			}
+
+			if _, err := postMovement(tx, &item, txType, movement.Amount, note, ref); err != nil {
+				return err
+			}
+		}
+
+		var consumed []model.CookRecordReservation
+		if err := tx.Where("cook_record_id = ?", record.ID).Find(&consumed).Error; err != nil {
+			return err
+		}
+		draws := make([]reservationDraw, 0, len(consumed))
+		for _, draw := range consumed {
+			draws = append(draws, reservationDraw{ReservationID: draw.ReservationID, Quantity: draw.Quantity})
+		}
+		if err := reinstateReservations(tx, draws, note); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		updates := map[string]interface{}{
+			"status":         CookStatusReversed,
+			"reversed_at":    now,
+			"reverse_reason": req.Reason,
+		}
+		if req.ReversedBy != "" {
+			updates["reversed_by"] = req.ReversedBy
+		}
+
+		return tx.Model(&record).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetCookRecordByID(c, id)
+}
@@ -0,0 +1,310 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"app/src/model"
+	"app/src/response"
+	"app/src/utils"
+	"app/src/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultBacktestHistoryDays = 84
+	defaultBacktestFolds       = 4
+)
+
+// forecastPoint is what was forecast for a recipe on a day.
+type forecastPoint struct {
+	RecipeID string
+	Date     time.Time
+	Quantity float64
+}
+
+// GetRunAccuracy compares a run with the sales of the days it forecast that
+// are over. Days still to come are left out.
+func (s *forecastService) GetRunAccuracy(c *fiber.Ctx, id string) (*response.ForecastAccuracyReport, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid forecast run ID")
+	}
+
+	db := s.DB.WithContext(c.Context())
+
+	var run model.ForecastRun
+	if err := db.First(&run, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "forecast run not found")
+		}
+		return nil, err
+	}
+
+	var points []forecastPoint
+	if err := db.Model(&model.ForecastValue{}).
+		Select("recipe_id, date, quantity").
+		Where("run_id = ? AND date < ?", run.ID, forecastToday().Format("2006-01-02")).
+		Scan(&points).Error; err != nil {
+		return nil, err
+	}
+
+	end := run.StartDate.AddDate(0, 0, run.Horizon-1)
+	if today := forecastToday(); !end.Before(today) {
+		end = today.AddDate(0, 0, -1)
+	}
+
+	report, err := forecastAccuracy(db, run.BranchID, points, run.StartDate, end)
+	if err != nil {
+		return nil, err
+	}
+	report.RunID = run.ID.String()
+
+	return report, nil
+}
+
+// GetAccuracy measures the forecasts of the branch over a period. For every
+// recipe and day the forecast of the latest run that covered the day counts,
+// i.e. the one the kitchen would have worked from.
+func (s *forecastService) GetAccuracy(c *fiber.Ctx, params *validation.QueryForecastAccuracy) (*response.ForecastAccuracyReport, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, err
+	}
+	if params.EndDate < params.StartDate {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "end_date must not be before start_date")
+	}
+
+	start, _ := time.Parse("2006-01-02", params.StartDate)
+	end, _ := time.Parse("2006-01-02", params.EndDate)
+	if today := forecastToday(); !end.Before(today) {
+		end = today.AddDate(0, 0, -1)
+	}
+
+	db := s.DB.WithContext(c.Context())
+
+	points, err := workedForecastPoints(db, params.BranchID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return forecastAccuracy(db, params.BranchID, points, start, end)
+}
+
+// workedForecastPoints returns, for every recipe and day from start to end,
+// the forecast of the latest run that covered the day.
+func workedForecastPoints(db *gorm.DB, branchID string, start, end time.Time) ([]forecastPoint, error) {
+	var points []forecastPoint
+	if err := db.Raw(`SELECT DISTINCT ON (forecast_values.recipe_id, forecast_values.date)
+			forecast_values.recipe_id, forecast_values.date, forecast_values.quantity
+		FROM forecast_values
+		JOIN forecast_runs ON forecast_runs.id = forecast_values.run_id
+		WHERE forecast_runs.branch_id = ? AND forecast_values.date BETWEEN ? AND ?
+		ORDER BY forecast_values.recipe_id, forecast_values.date, forecast_runs.created_at DESC`,
+		branchID, start.Format("2006-01-02"), end.Format("2006-01-02")).
+		Scan(&points).Error; err != nil {
+		return nil, err
+	}
+
+	return points, nil
+}
+
+// Backtest scores each model on every recipe sold in the history window, with
+// calendar events taken out as the forecast runs do, and picks the one with
+// the lowest MAPE, or the lowest MAE for recipes that were not sold on the
+// tested days. With Apply the picks are saved for the auto model.
+func (s *forecastService) Backtest(c *fiber.Ctx, req *validation.ForecastBacktest) (*response.ForecastBacktestReport, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	if len(req.Models) == 0 {
+		req.Models = []string{utils.ForecastMovingAverage, utils.ForecastExponentialSmoothing, utils.ForecastHoltWinters}
+	}
+	if req.HistoryDays <= 0 {
+		req.HistoryDays = defaultBacktestHistoryDays
+	}
+	if req.Horizon <= 0 {
+		req.Horizon = defaultForecastHorizon
+	}
+	if req.Folds <= 0 {
+		req.Folds = defaultBacktestFolds
+	}
+	params := utils.ForecastParams{Window: req.Window, Alpha: req.Alpha, Beta: req.Beta, Gamma: req.Gamma}
+
+	db := s.DB.WithContext(c.Context())
+
+	recipeQuery := db.Where("branch_id = ? AND deleted_at IS NULL", req.BranchID)
+	if len(req.RecipeCodes) > 0 {
+		recipeQuery = recipeQuery.Where("code IN ?", req.RecipeCodes)
+	}
+	var recipes []model.Recipe
+	if err := recipeQuery.Order("code ASC").Find(&recipes).Error; err != nil {
+		return nil, err
+	}
+	recipeIDs := make([]string, 0, len(recipes))
+	for _, recipe := range recipes {
+		recipeIDs = append(recipeIDs, recipe.ID.String())
+	}
+
+	historyStart := forecastToday().AddDate(0, 0, -req.HistoryDays)
+	history, err := dailySales(db, req.BranchID, recipeIDs, historyStart, req.HistoryDays)
+	if err != nil {
+		return nil, err
+	}
+	calendar, _, err := calendarDays(db, req.BranchID, historyStart, req.HistoryDays)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &response.ForecastBacktestReport{
+		BranchID:    req.BranchID,
+		HistoryDays: req.HistoryDays,
+		Horizon:     req.Horizon,
+		Folds:       req.Folds,
+		Applied:     req.Apply,
+		Recipes:     []response.RecipeBacktest{},
+	}
+
+	var chosen []model.RecipeForecastModel
+	now := time.Now()
+	for _, recipe := range recipes {
+		series, ok := history[recipe.ID.String()]
+		if !ok {
+			continue
+		}
+		series = utils.RemoveCalendarEffects(series, calendar)
+
+		result := response.RecipeBacktest{
+			RecipeID:   recipe.ID.String(),
+			RecipeCode: recipe.Code,
+			RecipeName: recipe.Name,
+		}
+		var best *utils.ForecastAccuracy
+		for _, name := range req.Models {
+			accuracy, err := utils.Backtest(name, series, req.Horizon, req.Folds, params)
+			if err != nil {
+				return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+			}
+			if accuracy.Points == 0 {
+				continue
+			}
+
+			result.Models = append(result.Models, response.ForecastModelScore{Model: name, ForecastAccuracyMetrics: accuracyMetrics(accuracy)})
+			if best == nil || moreAccurate(accuracy, *best) {
+				best = &accuracy
+				result.BestModel = name
+			}
+		}
+		if best == nil {
+			continue
+		}
+
+		report.Recipes = append(report.Recipes, result)
+		chosen = append(chosen, model.RecipeForecastModel{
+			RecipeID:    result.RecipeID,
+			Model:       result.BestModel,
+			MAPE:        best.MAPE,
+			MAE:         best.MAE,
+			Points:      best.Points,
+			EvaluatedAt: now,
+		})
+	}
+
+	if req.Apply && len(chosen) > 0 {
+		if err := db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "recipe_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"model", "mape", "mae", "points", "evaluated_at", "updated_at"}),
+		}).Create(&chosen).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// forecastAccuracy measures the points against the sales of the days from
+// start to end, per recipe and over all of them.
+func forecastAccuracy(db *gorm.DB, branchID string, points []forecastPoint, start, end time.Time) (*response.ForecastAccuracyReport, error) {
+	report := &response.ForecastAccuracyReport{
+		BranchID:  branchID,
+		StartDate: start.Format("2006-01-02"),
+		EndDate:   end.Format("2006-01-02"),
+		Recipes:   []response.RecipeForecastAccuracy{},
+	}
+
+	days := int(end.Sub(start).Hours()/24) + 1
+	if days <= 0 || len(points) == 0 {
+		return report, nil
+	}
+
+	forecasts := map[string][]float64{}
+	actuals := map[string][]float64{}
+	var recipeIDs []string
+	for _, point := range points {
+		if _, ok := forecasts[point.RecipeID]; !ok {
+			recipeIDs = append(recipeIDs, point.RecipeID)
+		}
+		forecasts[point.RecipeID] = append(forecasts[point.RecipeID], point.Quantity)
+	}
+
+	history, err := dailySales(db, branchID, recipeIDs, start, days)
+	if err != nil {
+		return nil, err
+	}
+	for _, point := range points {
+		actual := 0.0
+		if day := int(point.Date.Sub(start).Hours() / 24); day >= 0 && day < days && history[point.RecipeID] != nil {
+			actual = history[point.RecipeID][day]
+		}
+		actuals[point.RecipeID] = append(actuals[point.RecipeID], actual)
+	}
+
+	var recipes []model.Recipe
+	if err := db.Where("id IN ?", recipeIDs).Find(&recipes).Error; err != nil {
+		return nil, err
+	}
+	sort.Slice(recipes, func(a, b int) bool { return recipes[a].Code < recipes[b].Code })
+
+	var allForecasts, allActuals []float64
+	for _, recipe := range recipes {
+		id := recipe.ID.String()
+		report.Recipes = append(report.Recipes, response.RecipeForecastAccuracy{
+			RecipeID:                id,
+			RecipeCode:              recipe.Code,
+			RecipeName:              recipe.Name,
+			ForecastAccuracyMetrics: accuracyMetrics(utils.MeasureForecast(forecasts[id], actuals[id])),
+		})
+		allForecasts = append(allForecasts, forecasts[id]...)
+		allActuals = append(allActuals, actuals[id]...)
+	}
+	report.Overall = accuracyMetrics(utils.MeasureForecast(allForecasts, allActuals))
+
+	return report, nil
+}
+
+func moreAccurate(a, b utils.ForecastAccuracy) bool {
+	if a.MAPE != nil && b.MAPE != nil && *a.MAPE != *b.MAPE {
+		return *a.MAPE < *b.MAPE
+	}
+	return a.MAE < b.MAE
+}
+
+func accuracyMetrics(accuracy utils.ForecastAccuracy) response.ForecastAccuracyMetrics {
+	metrics := response.ForecastAccuracyMetrics{
+		Points:  accuracy.Points,
+		MAE:     math.Round(accuracy.MAE*100) / 100,
+		Bias:    math.Round(accuracy.Bias*100) / 100,
+		HitRate: math.Round(accuracy.HitRate*100) / 100,
+	}
+	if accuracy.MAPE != nil {
+		mape := math.Round(*accuracy.MAPE*100) / 100
+		metrics.MAPE = &mape
+	}
+
+	return metrics
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"app/src/model"
+	"app/src/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	ForecastOverrideSet    = "set"
+	ForecastOverrideRemove = "remove"
+)
+
+// SetOverride fixes the forecast of a recipe on a day, replacing any earlier
+// override of that day, and records the change in the audit trail.
+func (s *forecastService) SetOverride(c *fiber.Ctx, req *validation.SetForecastOverride) (*model.ForecastOverride, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	changedBy := currentUserID(c)
+	if changedBy == nil {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Please authenticate")
+	}
+
+	date, _ := time.Parse("2006-01-02", req.Date)
+	var override model.ForecastOverride
+
+	err := s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		var recipe model.Recipe
+		if err := tx.First(&recipe, "id = ? AND deleted_at IS NULL", req.RecipeID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fiber.NewError(fiber.StatusNotFound, "recipe not found")
+			}
+			return err
+		}
+
+		audit := model.ForecastOverrideAudit{
+			BranchID:    recipe.BranchID,
+			RecipeID:    req.RecipeID,
+			Date:        date,
+			Action:      ForecastOverrideSet,
+			NewQuantity: req.Quantity,
+			Reason:      req.Reason,
+			ChangedBy:   *changedBy,
+			ChangedAt:   time.Now(),
+		}
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&override, "recipe_id = ? AND date = ?", req.RecipeID, req.Date).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			override = model.ForecastOverride{BranchID: recipe.BranchID, RecipeID: req.RecipeID, Date: date}
+		case err != nil:
+			return err
+		default:
+			previous := override.Quantity
+			audit.OldQuantity = &previous
+		}
+
+		override.Quantity = *req.Quantity
+		override.Reason = req.Reason
+		override.ChangedBy = *changedBy
+		if err := tx.Save(&override).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&audit).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &override, nil
+}
+
+func (s *forecastService) GetOverrides(c *fiber.Ctx, params *validation.QueryForecastOverride) ([]model.ForecastOverride, int64, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, 0, err
+	}
+
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 {
+		params.Limit = 10
+	}
+
+	query := overrideQuery(s.DB.WithContext(c.Context()).Model(&model.ForecastOverride{}), params)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var overrides []model.ForecastOverride
+	if err := query.Preload("Recipe").
+		Order("date ASC").
+		Offset((params.Page - 1) * params.Limit).
+		Limit(params.Limit).
+		Find(&overrides).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return overrides, total, nil
+}
+
+// RemoveOverride hands the day back to the forecast runs.
+func (s *forecastService) RemoveOverride(c *fiber.Ctx, id string, params *validation.RemoveForecastOverride) error {
+	if err := s.Validate.Struct(params); err != nil {
+		return err
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid forecast override ID")
+	}
+	changedBy := currentUserID(c)
+	if changedBy == nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "Please authenticate")
+	}
+
+	return s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		var override model.ForecastOverride
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&override, "id = ?", id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fiber.NewError(fiber.StatusNotFound, "forecast override not found")
+			}
+			return err
+		}
+
+		if err := tx.Delete(&override).Error; err != nil {
+			return err
+		}
+
+		previous := override.Quantity
+		return tx.Create(&model.ForecastOverrideAudit{
+			BranchID:    override.BranchID,
+			RecipeID:    override.RecipeID,
+			Date:        override.Date,
+			Action:      ForecastOverrideRemove,
+			OldQuantity: &previous,
+			Reason:      params.Reason,
+			ChangedBy:   *changedBy,
+			ChangedAt:   time.Now(),
+		}).Error
+	})
+}
+
+// GetOverrideAudits lists the changes to the overrides, newest first. The
+// dates filter the forecast days that were changed.
+func (s *forecastService) GetOverrideAudits(c *fiber.Ctx, params *validation.QueryForecastOverride) ([]model.ForecastOverrideAudit, int64, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, 0, err
+	}
+
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 {
+		params.Limit = 10
+	}
+
+	query := overrideQuery(s.DB.WithContext(c.Context()).Model(&model.ForecastOverrideAudit{}), params)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var audits []model.ForecastOverrideAudit
+	if err := query.Order("changed_at DESC").
+		Offset((params.Page - 1) * params.Limit).
+		Limit(params.Limit).
+		Find(&audits).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return audits, total, nil
+}
+
+func overrideQuery(query *gorm.DB, params *validation.QueryForecastOverride) *gorm.DB {
+	query = query.Where("branch_id = ?", params.BranchID)
+	if params.RecipeID != "" {
+		query = query.Where("recipe_id = ?", params.RecipeID)
+	}
+	if params.StartDate != "" {
+		query = query.Where("date >= ?", params.StartDate)
+	}
+	if params.EndDate != "" {
+		query = query.Where("date <= ?", params.EndDate)
+	}
+
+	return query
+}
@@ -0,0 +1,552 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"app/src/model"
+	"app/src/response"
+	"app/src/utils"
+	"app/src/validation"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// ForecastAuto forecasts every recipe with the model that back-tested best
+	// for it, Holt-Winters for recipes that were never back-tested.
+	ForecastAuto = "auto"
+
+	defaultForecastHistoryDays = 56
+	defaultForecastHorizon     = 7
+	defaultForecastRealDays    = 7
+)
+
+type ForecastService interface {
+	CreateRun(c *fiber.Ctx, req *validation.CreateForecastRun) (*model.ForecastRun, error)
+	GetRuns(c *fiber.Ctx, params *validation.QueryForecastRun) ([]model.ForecastRun, int64, error)
+	GetRunByID(c *fiber.Ctx, id string) (*model.ForecastRun, error)
+	GetProcessed(c *fiber.Ctx, params *validation.QueryProcessedForecast) ([]response.ForecastData, error)
+	GetRunAccuracy(c *fiber.Ctx, id string) (*response.ForecastAccuracyReport, error)
+	GetAccuracy(c *fiber.Ctx, params *validation.QueryForecastAccuracy) (*response.ForecastAccuracyReport, error)
+	Backtest(c *fiber.Ctx, req *validation.ForecastBacktest) (*response.ForecastBacktestReport, error)
+	SetOverride(c *fiber.Ctx, req *validation.SetForecastOverride) (*model.ForecastOverride, error)
+	GetOverrides(c *fiber.Ctx, params *validation.QueryForecastOverride) ([]model.ForecastOverride, int64, error)
+	RemoveOverride(c *fiber.Ctx, id string, params *validation.RemoveForecastOverride) error
+	GetOverrideAudits(c *fiber.Ctx, params *validation.QueryForecastOverride) ([]model.ForecastOverrideAudit, int64, error)
+}
+
+type forecastService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewForecastService(db *gorm.DB, validate *validator.Validate) ForecastService {
+	return &forecastService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+func (s *forecastService) CreateRun(c *fiber.Ctx, req *validation.CreateForecastRun) (*model.ForecastRun, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	db := s.DB.WithContext(c.Context())
+
+	var branch model.Branch
+	if err := db.First(&branch, "id = ? AND deleted_at IS NULL", req.BranchID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "Branch not found")
+		}
+		return nil, err
+	}
+
+	params := utils.ForecastParams{Window: req.Window, Alpha: req.Alpha, Beta: req.Beta, Gamma: req.Gamma}.WithDefaults()
+	run := model.ForecastRun{
+		BranchID:    req.BranchID,
+		Model:       req.Model,
+		Window:      params.Window,
+		Alpha:       params.Alpha,
+		Beta:        params.Beta,
+		Gamma:       params.Gamma,
+		HistoryDays: req.HistoryDays,
+		Horizon:     req.Horizon,
+	}
+	if err := runForecast(db, &run); err != nil {
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+func (s *forecastService) GetRuns(c *fiber.Ctx, params *validation.QueryForecastRun) ([]model.ForecastRun, int64, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, 0, err
+	}
+
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 {
+		params.Limit = 10
+	}
+
+	query := s.DB.WithContext(c.Context()).Model(&model.ForecastRun{}).
+		Where("branch_id = ?", params.BranchID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var runs []model.ForecastRun
+	if err := query.Order("created_at DESC").
+		Offset((params.Page - 1) * params.Limit).
+		Limit(params.Limit).
+		Find(&runs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return runs, total, nil
+}
+
+func (s *forecastService) GetRunByID(c *fiber.Ctx, id string) (*model.ForecastRun, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid forecast run ID")
+	}
+
+	var run model.ForecastRun
+	if err := s.DB.WithContext(c.Context()).
+		Preload("Values", func(db *gorm.DB) *gorm.DB { return db.Order("date ASC") }).
+		Preload("Values.Recipe").
+		First(&run, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "forecast run not found")
+		}
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+// GetProcessed returns the actual sales of the last days and the forecast of
+// the coming ones, day by day, from the current forecast of the branch.
+// Planner overrides replace the forecast of their day. Each forecast line
+// tells whether the stock on hand covers the recipe's ingredients for its
+// forecast up to that day.
+func (s *forecastService) GetProcessed(c *fiber.Ctx, params *validation.QueryProcessedForecast) ([]response.ForecastData, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, err
+	}
+	if params.Days <= 0 {
+		params.Days = defaultForecastRealDays
+	}
+
+	db := s.DB.WithContext(c.Context())
+	today := forecastToday()
+
+	run, err := currentForecast(db, params.BranchID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	recipeQuery := db.Preload("Ingredients").Where("branch_id = ? AND deleted_at IS NULL", params.BranchID)
+	if codes := splitList(params.RecipeCodes); len(codes) > 0 {
+		recipeQuery = recipeQuery.Where("code IN ?", codes)
+	}
+	var recipes []model.Recipe
+	if err := recipeQuery.Order("code ASC").Find(&recipes).Error; err != nil {
+		return nil, err
+	}
+	recipesByID := make(map[string]*model.Recipe, len(recipes))
+	recipeIDs := make([]string, 0, len(recipes))
+	for i := range recipes {
+		recipesByID[recipes[i].ID.String()] = &recipes[i]
+		recipeIDs = append(recipeIDs, recipes[i].ID.String())
+	}
+
+	var data []response.ForecastData
+
+	start := today.AddDate(0, 0, -params.Days)
+	history, err := dailySales(db, params.BranchID, recipeIDs, start, params.Days)
+	if err != nil {
+		return nil, err
+	}
+	_, pastEvents, err := calendarDays(db, params.BranchID, start, params.Days)
+	if err != nil {
+		return nil, err
+	}
+	for day := 0; day < params.Days; day++ {
+		entry := response.ForecastData{Date: start.AddDate(0, 0, day).Format("2006-01-02"), Type: "real", Events: pastEvents[day], Items: []response.ForecastItem{}}
+		for _, recipe := range recipes {
+			series, ok := history[recipe.ID.String()]
+			if !ok || series[day] == 0 {
+				continue
+			}
+			entry.Total += series[day]
+			entry.Items = append(entry.Items, response.ForecastItem{
+				RecipeCode:          recipe.Code,
+				RecipeName:          recipe.Name,
+				Type:                "real",
+				Value:               series[day],
+				IsIngredientsEnough: true,
+				NotEnoughItems:      []response.ForecastShortItem{},
+			})
+		}
+		data = append(data, entry)
+	}
+
+	byDate := map[string][]model.ForecastValue{}
+	forecastFor := map[string]bool{}
+	for _, value := range run.Values {
+		if _, ok := recipesByID[value.RecipeID]; ok {
+			date := value.Date.Format("2006-01-02")
+			byDate[date] = append(byDate[date], value)
+			forecastFor[value.RecipeID+date] = true
+		}
+	}
+
+	var overrides []model.ForecastOverride
+	if err := db.Where("branch_id = ? AND date >= ? AND date < ?", params.BranchID,
+		run.StartDate.Format("2006-01-02"), run.StartDate.AddDate(0, 0, run.Horizon).Format("2006-01-02")).
+		Find(&overrides).Error; err != nil {
+		return nil, err
+	}
+	overridden := map[string]float64{}
+	for _, override := range overrides {
+		if _, ok := recipesByID[override.RecipeID]; !ok {
+			continue
+		}
+		date := override.Date.Format("2006-01-02")
+		overridden[override.RecipeID+date] = override.Quantity
+		if !forecastFor[override.RecipeID+date] {
+			byDate[date] = append(byDate[date], model.ForecastValue{RecipeID: override.RecipeID, Date: override.Date})
+		}
+	}
+
+	_, events, err := calendarDays(db, params.BranchID, run.StartDate, run.Horizon)
+	if err != nil {
+		return nil, err
+	}
+
+	perServing := map[string][]ingredientRequirement{}
+	unexplodable := map[string]string{}
+	cumulative := map[string]float64{}
+	for day := 0; day < run.Horizon; day++ {
+		date := run.StartDate.AddDate(0, 0, day).Format("2006-01-02")
+		values := byDate[date]
+		sort.Slice(values, func(a, b int) bool {
+			return recipesByID[values[a].RecipeID].Code < recipesByID[values[b].RecipeID].Code
+		})
+
+		entry := response.ForecastData{Date: date, Type: "forecast", Events: events[day], Items: []response.ForecastItem{}}
+		for _, value := range values {
+			recipe := recipesByID[value.RecipeID]
+			override, isOverridden := overridden[value.RecipeID+date]
+			if isOverridden {
+				value.Quantity = override
+			}
+			quantity := math.Round(value.Quantity*100) / 100
+			cumulative[value.RecipeID] += value.Quantity
+
+			requirements, ok := perServing[value.RecipeID]
+			if !ok {
+				requirements, _, err = planRecipeConsumption(db, recipe, 1, false, nil)
+				if err != nil {
+					if !recipeProblem(err) {
+						return nil, err
+					}
+					// the recipe is still forecast; only its ingredients are unknown
+					unexplodable[value.RecipeID] = err.Error()
+				}
+				perServing[value.RecipeID] = requirements
+			}
+
+			item := response.ForecastItem{
+				RecipeCode:     recipe.Code,
+				RecipeName:     recipe.Name,
+				Type:           "forecast",
+				Value:          quantity,
+				NotEnoughItems: []response.ForecastShortItem{},
+				Overridden:     isOverridden,
+			}
+			if reason, ok := unexplodable[value.RecipeID]; ok {
+				item.IngredientsError = reason
+			} else {
+				item.NotEnoughItems = forecastShortages(requirements, cumulative[value.RecipeID])
+				item.IsIngredientsEnough = len(item.NotEnoughItems) == 0
+			}
+			entry.Total += quantity
+			entry.Items = append(entry.Items, item)
+		}
+		data = append(data, entry)
+	}
+
+	return data, nil
+}
+
+// currentForecast returns the latest run of the branch with its values. When
+// there is none, or it was made before today or before the calendar last
+// changed, or it does not reach horizon days ahead, the branch is forecast
+// again with the same settings, so the forecast always starts today.
+// Regeneration holds a lock per branch; requests that waited for it use the
+// run the first one made.
+func currentForecast(db *gorm.DB, branchID string, horizon int) (*model.ForecastRun, error) {
+	run, fresh, err := latestForecast(db, branchID, horizon)
+	if err != nil {
+		return nil, err
+	}
+	if fresh {
+		if err := db.Where("run_id = ?", run.ID).Find(&run.Values).Error; err != nil {
+			return nil, err
+		}
+		return run, nil
+	}
+
+	var branch model.Branch
+	if err := db.First(&branch, "id = ? AND deleted_at IS NULL", branchID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "Branch not found")
+		}
+		return nil, err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "forecast:"+branchID).Error; err != nil {
+			return err
+		}
+
+		latest, fresh, err := latestForecast(tx, branchID, horizon)
+		if err != nil {
+			return err
+		}
+		if fresh {
+			run = latest
+			return tx.Where("run_id = ?", run.ID).Find(&run.Values).Error
+		}
+
+		run = &model.ForecastRun{
+			BranchID:    branchID,
+			Model:       latest.Model,
+			Window:      latest.Window,
+			Alpha:       latest.Alpha,
+			Beta:        latest.Beta,
+			Gamma:       latest.Gamma,
+			HistoryDays: latest.HistoryDays,
+			Horizon:     max(latest.Horizon, horizon),
+		}
+		return runForecast(tx, run)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// latestForecast returns the latest run of the branch, without its values,
+// and whether it can still be used. Without a run an empty one is returned.
+func latestForecast(db *gorm.DB, branchID string, horizon int) (*model.ForecastRun, bool, error) {
+	var run model.ForecastRun
+	err := db.Where("branch_id = ?", branchID).Order("created_at DESC").First(&run).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &run, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if run.StartDate.Before(forecastToday()) || run.Horizon < horizon {
+		return &run, false, nil
+	}
+
+	// deleted events count too; they are soft deleted for this
+	var changed int64
+	if err := db.Model(&model.CalendarEvent{}).
+		Where("(branch_id = ? OR branch_id IS NULL) AND updated_at > ?", branchID, run.CreatedAt).
+		Count(&changed).Error; err != nil {
+		return nil, false, err
+	}
+
+	return &run, changed == 0, nil
+}
+
+// runForecast fills in the defaults of the run, forecasts every recipe sold in
+// its history window and saves the run with its values. History ends
+// yesterday; the forecast starts today. Calendar events are taken out of the
+// history before the models see it and put back into the forecast days.
+func runForecast(db *gorm.DB, run *model.ForecastRun) error {
+	if run.Model == "" {
+		run.Model = ForecastAuto
+	}
+	if run.HistoryDays <= 0 {
+		run.HistoryDays = defaultForecastHistoryDays
+	}
+	if run.Horizon <= 0 {
+		run.Horizon = defaultForecastHorizon
+	}
+	params := utils.ForecastParams{Window: run.Window, Alpha: run.Alpha, Beta: run.Beta, Gamma: run.Gamma}.WithDefaults()
+	run.Window, run.Alpha, run.Beta, run.Gamma = params.Window, params.Alpha, params.Beta, params.Gamma
+
+	run.StartDate = forecastToday()
+	historyStart := run.StartDate.AddDate(0, 0, -run.HistoryDays)
+	history, err := dailySales(db, run.BranchID, nil, historyStart, run.HistoryDays)
+	if err != nil {
+		return err
+	}
+	pastCalendar, _, err := calendarDays(db, run.BranchID, historyStart, run.HistoryDays)
+	if err != nil {
+		return err
+	}
+	calendar, _, err := calendarDays(db, run.BranchID, run.StartDate, run.Horizon)
+	if err != nil {
+		return err
+	}
+
+	recipeIDs := make([]string, 0, len(history))
+	for recipeID := range history {
+		recipeIDs = append(recipeIDs, recipeID)
+	}
+	sort.Strings(recipeIDs)
+
+	models := map[string]string{}
+	if run.Model == ForecastAuto && len(recipeIDs) > 0 {
+		var chosen []model.RecipeForecastModel
+		if err := db.Where("recipe_id IN ?", recipeIDs).Find(&chosen).Error; err != nil {
+			return err
+		}
+		for _, choice := range chosen {
+			models[choice.RecipeID] = choice.Model
+		}
+	}
+
+	run.Values = nil
+	for _, recipeID := range recipeIDs {
+		recipeModel := run.Model
+		if recipeModel == ForecastAuto {
+			recipeModel = models[recipeID]
+			if recipeModel == "" {
+				recipeModel = utils.ForecastHoltWinters
+			}
+		}
+
+		base, used, err := utils.Forecast(recipeModel, utils.RemoveCalendarEffects(history[recipeID], pastCalendar), run.Horizon, params)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		values := utils.ApplyCalendarEffects(base, calendar)
+		for day := range values {
+			run.Values = append(run.Values, model.ForecastValue{
+				RecipeID: recipeID,
+				Date:     run.StartDate.AddDate(0, 0, day),
+				Quantity: values[day],
+				Base:     &base[day],
+				Model:    used,
+			})
+		}
+	}
+	run.RecipeCount = len(history)
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		values := run.Values
+		run.Values = nil
+		if err := tx.Create(run).Error; err != nil {
+			return err
+		}
+
+		for i := range values {
+			values[i].RunID = run.ID.String()
+		}
+		if len(values) > 0 {
+			if err := tx.CreateInBatches(values, 500).Error; err != nil {
+				return err
+			}
+		}
+		run.Values = values
+
+		return nil
+	})
+}
+
+// dailySales returns, per recipe sold, the servings sold on each of the days
+// from start, zero on days without sales. Only the given recipes are read
+// when recipeIDs is not nil.
+func dailySales(db *gorm.DB, branchID string, recipeIDs []string, start time.Time, days int) (map[string][]float64, error) {
+	var rows []struct {
+		RecipeID string
+		Date     time.Time
+		Quantity float64
+	}
+
+	query := db.Table("sales").
+		Select("sales.recipe_id, sales.date, SUM(sales.quantity) AS quantity").
+		Joins("JOIN recipes ON recipes.id = sales.recipe_id AND recipes.deleted_at IS NULL").
+		Where("sales.branch_id = ? AND sales.status = ? AND sales.date >= ? AND sales.date < ?",
+			branchID, "active", start.Format("2006-01-02"), start.AddDate(0, 0, days).Format("2006-01-02")).
+		Group("sales.recipe_id, sales.date")
+	if recipeIDs != nil {
+		query = query.Where("sales.recipe_id IN ?", recipeIDs)
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	history := map[string][]float64{}
+	for _, row := range rows {
+		day := int(row.Date.Sub(start).Hours() / 24)
+		if day < 0 || day >= days {
+			continue
+		}
+
+		series, ok := history[row.RecipeID]
+		if !ok {
+			series = make([]float64, days)
+			history[row.RecipeID] = series
+		}
+		series[day] += row.Quantity
+	}
+
+	return history, nil
+}
+
+// forecastShortages lists the ingredients whose available stock does not
+// cover the servings.
+func forecastShortages(perServing []ingredientRequirement, servings float64) []response.ForecastShortItem {
+	shortItems := []response.ForecastShortItem{}
+	for _, requirement := range perServing {
+		required := requirement.Required * servings
+		if requirement.Available >= required-1e-9 {
+			continue
+		}
+
+		item := requirement.Item
+		shortItems = append(shortItems, response.ForecastShortItem{
+			ID:             item.ID.String(),
+			BranchID:       item.BranchID,
+			Code:           item.Code,
+			Name:           item.Name,
+			Type:           item.Type,
+			Unit:           item.Unit,
+			Stock:          requirement.Available,
+			LeadTime:       item.LeadTime,
+			Quantity:       math.Round(required*100) / 100,
+			NotEnoughItems: []response.ForecastShortItem{},
+		})
+	}
+
+	return shortItems
+}
+
+func forecastToday() time.Time {
+	today, _ := time.Parse("2006-01-02", time.Now().Format("2006-01-02"))
+	return today
+}
@@ -1,18 +1,22 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 	"time"
 
 	"app/src/model"
+	"app/src/response"
 	"app/src/validation"
 	"encoding/csv"
 	"app/src/utils"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
@@ -23,7 +27,8 @@ type ItemService interface {
 	GetItemsByBranch(c *fiber.Ctx, branchID string) ([]model.Item, error)
 	CreateItem(c *fiber.Ctx, req *validation.CreateItem) (*model.Item, error)
 	UpdateItem(c *fiber.Ctx, req *validation.UpdateItem, id string) (*model.Item, error)
-	DeleteItem(c *fiber.Ctx, id string) error
+	DeleteItem(c *fiber.Ctx, id string, params *validation.DeleteItem) error
+	GetWhereUsed(c *fiber.Ctx, id string) ([]response.ItemUsage, error)
 	ImportCSV(c *fiber.Ctx, branchID string, file io.Reader) error
 }
 
@@ -55,7 +60,7 @@ func (i *itemService) GetItems(c *fiber.Ctx, params *validation.QueryItem) ([]mo
 		return nil, 0, fiber.NewError(fiber.StatusBadRequest, "branch_id is required")
 	}
 
-	query := i.DB.WithContext(c.Context()).Where("branch_id = ?", params.BranchID).Order("created_at asc")
+	query := i.DB.WithContext(c.Context()).Where("branch_id = ? AND deleted_at IS NULL", params.BranchID).Order("created_at asc")
 
 	if search := params.Search; search != "" {
 		query = query.Where("name LIKE ? OR code LIKE ?", "%"+search+"%", "%"+search+"%")
@@ -68,16 +73,24 @@ func (i *itemService) GetItems(c *fiber.Ctx, params *validation.QueryItem) ([]mo
 		return nil, 0, result.Error
 	}
 
+	if err := withAvailability(i.DB.WithContext(c.Context()), itemRefs(items)); err != nil {
+		return nil, 0, err
+	}
+
 	return items, total, nil
 }
 
 func (i *itemService) GetItemByID(c *fiber.Ctx, id string) (*model.Item, error) {
 	var item model.Item
-	result := i.DB.WithContext(c.Context()).First(&item, "id = ?", id)
+	result := i.DB.WithContext(c.Context()).First(&item, "id = ? AND deleted_at IS NULL", id)
 	if result.Error != nil {
 		return nil, fiber.NewError(fiber.StatusNotFound, "Item not found")
 	}
 
+	if err := withAvailability(i.DB.WithContext(c.Context()), []*model.Item{&item}); err != nil {
+		return nil, err
+	}
+
 	return &item, nil
 }
 
@@ -86,11 +99,15 @@ func (i *itemService) GetItemsByBranch(c *fiber.Ctx, branchID string) ([]model.I
 
 	if err := i.DB.
 		WithContext(c.Context()).
-		Where("branch_id = ?", branchID).
+		Where("branch_id = ? AND deleted_at IS NULL", branchID).
 		Find(&items).Error; err != nil {
 		return nil, fmt.Errorf("failed to fetch items: %w", err)
 	}
 
+	if err := withAvailability(i.DB.WithContext(c.Context()), itemRefs(items)); err != nil {
+		return nil, err
+	}
+
 	return items, nil
 }
 
@@ -107,6 +124,11 @@ func (i *itemService) CreateItem(c *fiber.Ctx, req *validation.CreateItem) (*mod
 		Stock:    float64(req.Stock),
 		Unit:     req.Unit,
 		LeadTime: req.LeadTime,
+		UnitCost: req.UnitCost,
+		Allergens: utils.JoinAllergens(strings.Split(req.Allergens, ",")),
+	}
+	if req.Nutrition != nil {
+		item.Nutrition = model.Nutrition(*req.Nutrition)
 	}
 
 	result := i.DB.WithContext(c.Context()).Create(item)
@@ -123,7 +145,7 @@ func (i *itemService) UpdateItem(c *fiber.Ctx, req *validation.UpdateItem, id st
 	}
 
 	var item model.Item
-	result := i.DB.WithContext(c.Context()).First(&item, "id = ?", id)
+	result := i.DB.WithContext(c.Context()).First(&item, "id = ? AND deleted_at IS NULL", id)
 	if result.Error != nil {
 		return nil, fiber.NewError(fiber.StatusNotFound, "Item not found")
 	}
@@ -147,21 +169,344 @@ func (i *itemService) UpdateItem(c *fiber.Ctx, req *validation.UpdateItem, id st
 		item.LeadTime = *req.LeadTime
 	}
 
+	if req.Allergens != nil {
+		item.Allergens = utils.JoinAllergens(strings.Split(*req.Allergens, ","))
+	}
+	if req.Nutrition != nil {
+		item.Nutrition = model.Nutrition(*req.Nutrition)
+	}
+
+	costChanged := req.UnitCost != nil && *req.UnitCost != item.UnitCost
+	if req.UnitCost != nil {
+		item.UnitCost = *req.UnitCost
+	}
+
 	item.UpdatedAt = time.Now()
 
-	if err := i.DB.Save(&item).Error; err != nil {
+	err := i.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&item).Error; err != nil {
+			return err
+		}
+
+		if costChanged {
+			return recalculateCostsForItem(tx, &item)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := withAvailability(i.DB.WithContext(c.Context()), []*model.Item{&item}); err != nil {
 		return nil, err
 	}
 
 	return &item, nil
 }
 
-func (i *itemService) DeleteItem(c *fiber.Ctx, id string) error {
-	result := i.DB.WithContext(c.Context()).Delete(&model.Item{}, "id = ?", id)
-	if result.RowsAffected == 0 {
-		return fiber.NewError(fiber.StatusNotFound, "Item not found")
+func (i *itemService) GetWhereUsed(c *fiber.Ctx, id string) ([]response.ItemUsage, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid item ID")
+	}
+
+	var item model.Item
+	if err := i.DB.WithContext(c.Context()).First(&item, "id = ? AND deleted_at IS NULL", id).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Item not found")
+	}
+
+	return whereUsed(i.DB.WithContext(c.Context()), &item)
+}
+
+// ItemInUseError is returned when deleting an item that recipes still use.
+type ItemInUseError struct {
+	Usages []response.ItemUsage
+}
+
+func (e *ItemInUseError) Error() string {
+	return fmt.Sprintf("item is used by %d recipe(s), give a replacement_item_id to delete it", len(e.Usages))
+}
+
+// DeleteItem soft-deletes the item. While recipes reference it the delete is
+// refused, unless a replacement item is given: every reference is then moved
+// to the replacement and the affected recipes get a new version, see
+// replaceItemInRecipes.
+func (i *itemService) DeleteItem(c *fiber.Ctx, id string, params *validation.DeleteItem) error {
+	if err := i.Validate.Struct(params); err != nil {
+		return err
+	}
+
+	if _, err := uuid.Parse(id); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid item ID")
+	}
+
+	return i.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		var item model.Item
+		if err := tx.First(&item, "id = ? AND deleted_at IS NULL", id).Error; err != nil {
+			return fiber.NewError(fiber.StatusNotFound, "Item not found")
+		}
+
+		var recipeIDs []string
+		if err := tx.Model(&model.RecipeIngredient{}).
+			Where("item_id = ? AND recipe_id IN (?)", id, tx.Model(&model.Recipe{}).Select("id").Where("deleted_at IS NULL")).
+			Distinct().Pluck("recipe_id", &recipeIDs).Error; err != nil {
+			return err
+		}
+
+		var substituteCount int64
+		if err := tx.Model(&model.RecipeSubstitute{}).
+			Where("item_id = ? OR substitute_item_id = ?", id, id).
+			Count(&substituteCount).Error; err != nil {
+			return err
+		}
+
+		if (len(recipeIDs) > 0 || substituteCount > 0) && params.ReplacementItemID == "" {
+			usages, err := whereUsed(tx, &item)
+			if err != nil {
+				return err
+			}
+			return &ItemInUseError{Usages: usages}
+		}
+
+		if params.ReplacementItemID != "" {
+			if err := replaceItemInRecipes(tx, &item, params.ReplacementItemID); err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&item).Update("deleted_at", time.Now()).Error
+	})
+}
+
+// replaceItemInRecipes moves every recipe reference of the item to the
+// replacement. A recipe already using the replacement keeps one line for it,
+// with the quantities added up, and substitutes that would then be listed
+// twice are dropped. Every active recipe that changes gets a new version.
+func replaceItemInRecipes(tx *gorm.DB, item *model.Item, replacementID string) error {
+	if replacementID == item.ID.String() {
+		return fiber.NewError(fiber.StatusBadRequest, "replacement item must be a different item")
+	}
+
+	var replacement model.Item
+	if err := tx.First(&replacement, "id = ? AND branch_id = ? AND deleted_at IS NULL", replacementID, item.BranchID).Error; err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "replacement item not found in branch")
+	}
+
+	var ingredients []model.RecipeIngredient
+	if err := tx.Where("item_id = ?", item.ID).Find(&ingredients).Error; err != nil {
+		return err
+	}
+	for _, ing := range ingredients {
+		if !utils.IsConvertibleUnit(ing.Unit, replacement.Unit) {
+			return fiber.NewError(fiber.StatusBadRequest,
+				fmt.Sprintf("recipe unit '%s' cannot be converted to replacement unit '%s'", ing.Unit, replacement.Unit))
+		}
+	}
+
+	touched := map[string]bool{}
+	for _, ing := range ingredients {
+		touched[ing.RecipeID] = true
+		if err := moveIngredient(tx, ing, &replacement); err != nil {
+			return err
+		}
+	}
+
+	var substitutes []model.RecipeSubstitute
+	if err := tx.Where("item_id = ? OR substitute_item_id = ?", item.ID, item.ID).
+		Order("rank ASC").Find(&substitutes).Error; err != nil {
+		return err
+	}
+	for _, substitute := range substitutes {
+		touched[substitute.RecipeID] = true
+		if err := moveSubstitute(tx, substitute, item.ID.String(), replacementID); err != nil {
+			return err
+		}
+	}
+
+	recipeIDs := make([]string, 0, len(touched))
+	for id := range touched {
+		recipeIDs = append(recipeIDs, id)
+	}
+	var active []string
+	if err := tx.Model(&model.Recipe{}).Where("id IN ? AND deleted_at IS NULL", recipeIDs).
+		Order("code ASC").Pluck("id", &active).Error; err != nil {
+		return err
+	}
+
+	note := fmt.Sprintf("Replaced item %s with %s", item.Code, replacement.Code)
+	for _, recipeID := range active {
+		if _, err := snapshotRecipeVersion(tx, recipeID, "", note); err != nil {
+			return err
+		}
+
+		if err := recordRecipeCosts(tx, uuid.MustParse(recipeID), CostReasonRecipeChange); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// moveIngredient points the recipe line at the replacement item, or adds its
+// quantity to the line the recipe already has for it.
+func moveIngredient(tx *gorm.DB, ing model.RecipeIngredient, replacement *model.Item) error {
+	var existing model.RecipeIngredient
+	err := tx.Where("recipe_id = ? AND item_id = ? AND id <> ?", ing.RecipeID, replacement.ID, ing.ID).
+		First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return tx.Model(&ing).Update("item_id", replacement.ID).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	quantity, unit, ok := mergedQuantity(existing, ing, replacement)
+	if !ok {
+		return tx.Model(&ing).Update("item_id", replacement.ID).Error
 	}
-	return result.Error
+	if err := tx.Model(&existing).Updates(map[string]interface{}{"quantity": quantity, "unit": unit}).Error; err != nil {
+		return err
+	}
+
+	return tx.Delete(&ing).Error
+}
+
+// mergedQuantity adds the moved line to the line already there, in that
+// line's unit when the two convert, and in the item's stock unit otherwise.
+// Lines that cannot be brought to one unit are not merged.
+func mergedQuantity(existing, moved model.RecipeIngredient, item *model.Item) (float64, string, bool) {
+	if quantity, err := utils.ConvertUnit(moved.Quantity, moved.Unit, existing.Unit); err == nil {
+		return existing.Quantity + quantity, existing.Unit, true
+	}
+
+	existingQuantity, err := stockQuantity(existing.Quantity, existing.Unit, item)
+	if err != nil {
+		return 0, "", false
+	}
+	movedQuantity, err := stockQuantity(moved.Quantity, moved.Unit, item)
+	if err != nil {
+		return 0, "", false
+	}
+	return existingQuantity + movedQuantity, item.Unit, true
+}
+
+// moveSubstitute replaces the item on either side of the substitute. It is
+// dropped when it would substitute the replacement for itself or duplicate a
+// substitute the recipe already has.
+func moveSubstitute(tx *gorm.DB, substitute model.RecipeSubstitute, itemID, replacementID string) error {
+	if substitute.ItemID == itemID {
+		substitute.ItemID = replacementID
+	}
+	if substitute.SubstituteItemID == itemID {
+		substitute.SubstituteItemID = replacementID
+	}
+	if substitute.ItemID == substitute.SubstituteItemID {
+		return tx.Delete(&substitute).Error
+	}
+
+	var count int64
+	if err := tx.Model(&model.RecipeSubstitute{}).
+		Where("recipe_id = ? AND item_id = ? AND substitute_item_id = ? AND id <> ?",
+			substitute.RecipeID, substitute.ItemID, substitute.SubstituteItemID, substitute.ID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return tx.Delete(&substitute).Error
+	}
+
+	return tx.Model(&substitute).Updates(map[string]interface{}{
+		"item_id":            substitute.ItemID,
+		"substitute_item_id": substitute.SubstituteItemID,
+	}).Error
+}
+
+// whereUsed lists the recipes that use the item, directly or through the
+// half-finished recipes it goes into, and the recipes listing it as a
+// substitute.
+func whereUsed(db *gorm.DB, item *model.Item) ([]response.ItemUsage, error) {
+	type pending struct {
+		item model.Item
+		via  []string
+	}
+
+	usages := []response.ItemUsage{}
+	visited := map[string]bool{}
+	queue := []pending{{item: *item}}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		var ingredients []model.RecipeIngredient
+		if err := db.Where("item_id = ? AND recipe_id IN (?)", current.item.ID,
+			db.Model(&model.Recipe{}).Select("id").Where("deleted_at IS NULL"),
+		).Find(&ingredients).Error; err != nil {
+			return nil, err
+		}
+
+		for _, ing := range ingredients {
+			if visited[ing.RecipeID] {
+				continue
+			}
+			visited[ing.RecipeID] = true
+
+			var recipe model.Recipe
+			if err := db.First(&recipe, "id = ?", ing.RecipeID).Error; err != nil {
+				return nil, err
+			}
+
+			usages = append(usages, response.ItemUsage{
+				RecipeID:   recipe.ID.String(),
+				RecipeCode: recipe.Code,
+				RecipeName: recipe.Name,
+				RecipeType: recipe.Type,
+				Quantity:   ing.Quantity,
+				Unit:       ing.Unit,
+				Direct:     len(current.via) == 0,
+				Via:        current.via,
+			})
+
+			if recipe.Type != RecipeTypeHalfFinished {
+				continue
+			}
+
+			var produced []model.Item
+			if err := db.Where("branch_id = ? AND code = ? AND deleted_at IS NULL", recipe.BranchID, recipe.Code).
+				Find(&produced).Error; err != nil {
+				return nil, err
+			}
+			for _, p := range produced {
+				via := append(append([]string{}, current.via...), recipe.Code)
+				queue = append(queue, pending{item: p, via: via})
+			}
+		}
+	}
+
+	var substitutes []model.RecipeSubstitute
+	if err := db.Where("substitute_item_id = ?", item.ID).Find(&substitutes).Error; err != nil {
+		return nil, err
+	}
+	for _, substitute := range substitutes {
+		var recipe model.Recipe
+		if err := db.First(&recipe, "id = ? AND deleted_at IS NULL", substitute.RecipeID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return nil, err
+		}
+
+		usages = append(usages, response.ItemUsage{
+			RecipeID:   recipe.ID.String(),
+			RecipeCode: recipe.Code,
+			RecipeName: recipe.Name,
+			RecipeType: recipe.Type,
+			Direct:     true,
+			Substitute: true,
+		})
+	}
+
+	return usages, nil
 }
 
 func (i *itemService) ImportCSV(c *fiber.Ctx, branchID string, file io.Reader) error {
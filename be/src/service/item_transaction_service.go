@@ -43,8 +43,11 @@ func (t *itemTransactionService) CreateTransaction(c *fiber.Ctx, req *validation
 
 		var item model.Item
 		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
-			Where("id = ? AND branch_id = ?", req.ItemID, req.BranchID).
+			Where("id = ? AND branch_id = ? AND deleted_at IS NULL", req.ItemID, req.BranchID).
 			First(&item).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fiber.NewError(fiber.StatusNotFound, "Item not found in branch")
+			}
 			return err
 		}
 
@@ -52,23 +55,44 @@ func (t *itemTransactionService) CreateTransaction(c *fiber.Ctx, req *validation
 		if req.Type == "in" {
 			newStock = item.Stock + req.Amount
 		} else {
-			if item.Stock < req.Amount {
+			reservation, err := reservationSource(tx, req.ReservationID, item.ID.String())
+			if err != nil {
+				return err
+			}
+
+			available, err := availableQuantity(tx, &item, reservation)
+			if err != nil {
+				return err
+			}
+			if available < req.Amount {
 				return errors.New("insufficient stock")
 			}
 			newStock = item.Stock - req.Amount
+
+			if reservation != nil {
+				if _, err := consumeReservations(tx, *reservation, item.ID.String(), req.Amount); err != nil {
+					return err
+				}
+			}
 		}
 
 		if err := tx.Model(&item).Update("stock", newStock).Error; err != nil {
 			return err
 		}
 
+		transactionDate := req.TransactionDate
+		if transactionDate.IsZero() {
+			transactionDate = time.Now()
+		}
+
 		transaction = &model.ItemTransaction{
-			ItemID:       req.ItemID,
-			BranchID:     req.BranchID,
-			Type:         req.Type,
-			Amount:       req.Amount,
-			CurrentStock: newStock,
-			Note:         req.Note,
+			ItemID:          req.ItemID,
+			BranchID:        req.BranchID,
+			Type:            req.Type,
+			Amount:          req.Amount,
+			CurrentStock:    newStock,
+			Note:            req.Note,
+			TransactionDate: transactionDate,
 		}
 
 		if err := tx.Create(transaction).Error; err != nil {
@@ -190,7 +214,7 @@ func (t *itemTransactionService) TransferItem(c *fiber.Ctx, req *validation.Tran
 		var itemFrom model.Item
 		if err := tx.
 			Clauses(clause.Locking{Strength: "UPDATE"}).
-			Where("id = ? AND branch_id = ?", itemID, fromBranchID).
+			Where("id = ? AND branch_id = ? AND deleted_at IS NULL", itemID, fromBranchID).
 			First(&itemFrom).Error; err != nil {
 
 			if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -202,13 +226,29 @@ func (t *itemTransactionService) TransferItem(c *fiber.Ctx, req *validation.Tran
 			return err
 		}
 
-		if itemFrom.Stock < req.Amount {
+		reservation, err := reservationSource(tx, req.ReservationID, itemFrom.ID.String())
+		if err != nil {
+			return err
+		}
+
+		available, err := availableQuantity(tx, &itemFrom, reservation)
+		if err != nil {
+			return err
+		}
+
+		if available < req.Amount {
 			return fiber.NewError(
 				fiber.StatusBadRequest,
 				"Insufficient stock",
 			)
 		}
 
+		if reservation != nil {
+			if _, err := consumeReservations(tx, *reservation, itemFrom.ID.String(), req.Amount); err != nil {
+				return err
+			}
+		}
+
 		newStockFrom := itemFrom.Stock - req.Amount
 		
 		if err := tx.Model(&itemFrom).
@@ -217,9 +257,9 @@ func (t *itemTransactionService) TransferItem(c *fiber.Ctx, req *validation.Tran
 		}
 
 		var itemTo model.Item
-		err := tx.
+		err = tx.
 			Clauses(clause.Locking{Strength: "UPDATE"}).
-			Where("code = ? AND branch_id = ?", itemFrom.Code, toBranchID).
+			Where("code = ? AND branch_id = ? AND deleted_at IS NULL", itemFrom.Code, toBranchID).
 			First(&itemTo).Error
 
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -274,3 +314,48 @@ func (t *itemTransactionService) TransferItem(c *fiber.Ctx, req *validation.Tran
 		return nil
 	})
 }
+
+
+// movementRef ties a ledger row to the document that caused it.
+type movementRef struct {
+	Type string
+	ID   string
+}
+
+// postMovement applies a stock movement to an already loaded (and locked) item
+// and writes the matching ledger row, both inside tx.
+func postMovement(tx *gorm.DB, item *model.Item, txType string, amount float64, note string, ref *movementRef) (*model.ItemTransaction, error) {
+	newStock := item.Stock - amount
+	if isInboundMovement(txType) {
+		newStock = item.Stock + amount
+	}
+
+	if err := tx.Model(item).Update("stock", newStock).Error; err != nil {
+		return nil, err
+	}
+	item.Stock = newStock
+
+	transaction := &model.ItemTransaction{
+		ItemID:          item.ID,
+		BranchID:        item.BranchID,
+		Type:            txType,
+		Amount:          amount,
+		CurrentStock:    newStock,
+		Note:            note,
+		TransactionDate: time.Now(),
+	}
+	if ref != nil {
+		transaction.ReferenceType = &ref.Type
+		transaction.ReferenceID = &ref.ID
+	}
+
+	if err := tx.Create(transaction).Error; err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+func isInboundMovement(txType string) bool {
+	return txType == "in" || txType == "transfer_in" || txType == "cook_in" || txType == "count_in"
+}
@@ -0,0 +1,477 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"app/src/model"
+	"app/src/response"
+	"app/src/utils"
+	"app/src/validation"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const defaultPlanningHorizon = 14
+
+type PlanningService interface {
+	GetRequirements(c *fiber.Ctx, params *validation.QueryRequirementsPlan) (*response.RequirementsPlan, error)
+}
+
+type planningService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewPlanningService(db *gorm.DB, validate *validator.Validate) PlanningService {
+	return &planningService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+// plannedItem is an item in the requirements plan. Half-finished items are
+// produced by their sub recipe; everything else is purchased.
+type plannedItem struct {
+	Item       model.Item
+	SubRecipe  *model.Recipe
+	Components []ingredientRequirement // per unit produced
+	Level      int                     // deepest level the item is used at; finished recipes are 0
+	LeadTime   int                     // days from ordering to receiving, see purchaseLeadTime
+	Recipes    map[string]bool         // codes of the recipes it is needed for
+	Available  float64
+	OnOrder    float64
+	Gross      []float64
+	Receipts   []float64
+}
+
+// requirementsPlanner explodes demand through the recipes. Items are added
+// the first time they are needed, with the components of their sub recipe.
+type requirementsPlanner struct {
+	db       *gorm.DB
+	horizon  int
+	items    map[string]*plannedItem
+	building map[string]bool
+	skipped  []response.UnplannedRecipe
+}
+
+// GetRequirements plans the ingredients of the branch over the horizon. The
+// forecast servings of every recipe, with planner overrides, are exploded
+// through the recipe into gross requirements per day. Half-finished items are
+// netted first and what has to be produced of them is exploded into their own
+// ingredients. Each item is netted day by day against its available stock
+// (on hand minus reservations) and scheduled receipts: open purchase orders
+// and production orders of half-finished items. What is still missing is
+// planned to arrive on the day it is needed, and to be ordered the lead time
+// of the supplier it is bought from before (see purchaseLeadTime).
+func (s *planningService) GetRequirements(c *fiber.Ctx, params *validation.QueryRequirementsPlan) (*response.RequirementsPlan, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, err
+	}
+	if params.Horizon <= 0 {
+		params.Horizon = defaultPlanningHorizon
+	}
+
+	return planRequirements(s.DB.WithContext(c.Context()), params.BranchID, params.Horizon, splitList(params.RecipeCodes))
+}
+
+// planRequirements plans the ingredients the recipes need over the horizon;
+// without codes every recipe of the branch is planned.
+func planRequirements(db *gorm.DB, branchID string, horizon int, recipeCodes []string) (*response.RequirementsPlan, error) {
+	today := forecastToday()
+
+	run, err := currentForecast(db, branchID, horizon)
+	if err != nil {
+		return nil, err
+	}
+
+	recipeQuery := db.Preload("Ingredients").Where("branch_id = ? AND deleted_at IS NULL", branchID)
+	if len(recipeCodes) > 0 {
+		recipeQuery = recipeQuery.Where("code IN ?", recipeCodes)
+	}
+	var recipes []model.Recipe
+	if err := recipeQuery.Order("code ASC").Find(&recipes).Error; err != nil {
+		return nil, err
+	}
+
+	demand := map[string][]float64{}
+	for i := range recipes {
+		demand[recipes[i].ID.String()] = make([]float64, horizon)
+	}
+	for _, value := range run.Values {
+		if day := planningDay(value.Date, today); day < horizon && !value.Date.Before(today) {
+			if series, ok := demand[value.RecipeID]; ok {
+				series[day] = value.Quantity
+			}
+		}
+	}
+
+	var overrides []model.ForecastOverride
+	if err := db.Where("branch_id = ? AND date >= ? AND date < ?", branchID,
+		today.Format("2006-01-02"), today.AddDate(0, 0, horizon).Format("2006-01-02")).
+		Find(&overrides).Error; err != nil {
+		return nil, err
+	}
+	for _, override := range overrides {
+		if series, ok := demand[override.RecipeID]; ok {
+			series[planningDay(override.Date, today)] = override.Quantity
+		}
+	}
+
+	plan := &response.RequirementsPlan{
+		BranchID:  branchID,
+		RunID:     run.ID.String(),
+		StartDate: today.Format("2006-01-02"),
+		Horizon:   horizon,
+		Items:     []response.ItemRequirement{},
+	}
+
+	planner := &requirementsPlanner{db: db, horizon: horizon, items: map[string]*plannedItem{}, building: map[string]bool{}}
+	for i := range recipes {
+		series := demand[recipes[i].ID.String()]
+		if !hasDemand(series) {
+			continue
+		}
+
+		requirements, _, err := planRecipeConsumption(db, &recipes[i], 1, false, nil)
+		if err != nil {
+			if !recipeProblem(err) {
+				return nil, err
+			}
+			planner.skip(&recipes[i], err)
+			continue
+		}
+		for _, requirement := range requirements {
+			planned, err := planner.add(requirement.Item)
+			if err != nil {
+				return nil, err
+			}
+			for day, servings := range series {
+				planned.Gross[day] += requirement.Required * servings
+			}
+			planned.Recipes[recipes[i].Code] = true
+		}
+		plan.RecipeCount++
+	}
+
+	if err := planner.addProductionOrders(branchID, today); err != nil {
+		return nil, err
+	}
+	if err := planner.addPurchaseOrders(branchID, today); err != nil {
+		return nil, err
+	}
+
+	for _, planned := range planner.ordered() {
+		requirement, produce := planner.net(planned, today)
+		for _, component := range planned.Components {
+			child := planner.items[component.Item.ID.String()]
+			for day, quantity := range produce {
+				child.Gross[day] += component.Required * quantity
+			}
+			for code := range planned.Recipes {
+				child.Recipes[code] = true
+			}
+		}
+		plan.Items = append(plan.Items, requirement)
+	}
+
+	sort.Slice(plan.Items, func(a, b int) bool {
+		return plan.Items[a].ItemCode < plan.Items[b].ItemCode
+	})
+	plan.SkippedRecipes = planner.skipped
+
+	return plan, nil
+}
+
+// skip reports a recipe that could not be exploded instead of failing the
+// whole plan.
+func (p *requirementsPlanner) skip(recipe *model.Recipe, err error) {
+	for _, skipped := range p.skipped {
+		if skipped.RecipeCode == recipe.Code {
+			return
+		}
+	}
+	p.skipped = append(p.skipped, response.UnplannedRecipe{RecipeCode: recipe.Code, RecipeName: recipe.Name, Reason: err.Error()})
+}
+
+// add returns the item's entry in the plan, adding it with the components of
+// its sub recipe when it is new. A sub recipe that uses itself, directly or
+// further down, is not exploded again.
+func (p *requirementsPlanner) add(item model.Item) (*plannedItem, error) {
+	id := item.ID.String()
+	if planned, ok := p.items[id]; ok {
+		return planned, nil
+	}
+
+	available, err := availableQuantity(p.db, &item, nil)
+	if err != nil {
+		return nil, err
+	}
+	planned := &plannedItem{
+		Item:      item,
+		Recipes:   map[string]bool{},
+		Available: available,
+		LeadTime:  item.LeadTime,
+		Gross:     make([]float64, p.horizon),
+		Receipts:  make([]float64, p.horizon),
+	}
+	p.items[id] = planned
+
+	subRecipe, err := findSubRecipe(p.db, &item)
+	if err != nil {
+		return nil, err
+	}
+	if subRecipe == nil {
+		suppliers, err := itemSuppliers(p.db, []string{id}, "", forecastToday())
+		if err != nil {
+			return nil, err
+		}
+		planned.LeadTime = purchaseLeadTime(&item, chooseSupplier(suppliers[id], SupplierStrategyPreferred))
+		return planned, nil
+	}
+	if err := p.db.Where("recipe_id = ?", subRecipe.ID).Find(&subRecipe.Ingredients).Error; err != nil {
+		return nil, err
+	}
+	planned.SubRecipe = subRecipe
+
+	p.building[id] = true
+	defer delete(p.building, id)
+
+	// one stock unit of a half-finished item is one serving of its recipe; one
+	// that cannot be exploded is still planned, without its components
+	components, _, err := planRecipeConsumption(p.db, subRecipe, 1, false, nil)
+	if err != nil {
+		if !recipeProblem(err) {
+			return nil, err
+		}
+		p.skip(subRecipe, err)
+		return planned, nil
+	}
+	for _, component := range components {
+		if p.building[component.Item.ID.String()] {
+			continue
+		}
+		if _, err := p.add(component.Item); err != nil {
+			return nil, err
+		}
+		planned.Components = append(planned.Components, component)
+	}
+
+	return planned, nil
+}
+
+// addProductionOrders schedules the planned and released production of the
+// half-finished items in the plan as receipts. Released orders have already
+// reserved their ingredients; the lines of planned ones are still needed on
+// their planned date.
+func (p *requirementsPlanner) addProductionOrders(branchID string, today time.Time) error {
+	byCode := map[string]*plannedItem{}
+	for _, planned := range p.items {
+		if planned.SubRecipe != nil {
+			byCode[planned.SubRecipe.Code] = planned
+		}
+	}
+	if len(byCode) == 0 {
+		return nil
+	}
+
+	var orders []model.ProductionOrder
+	if err := p.db.Preload("Recipe").Preload("Lines.Item").
+		Where("branch_id = ? AND status IN ? AND planned_date < ?", branchID,
+			[]string{ProductionStatusPlanned, ProductionStatusReleased},
+			today.AddDate(0, 0, p.horizon).Format("2006-01-02")).
+		Find(&orders).Error; err != nil {
+		return err
+	}
+
+	for _, order := range orders {
+		if order.Recipe == nil || order.Recipe.Type != RecipeTypeHalfFinished {
+			continue
+		}
+		planned, ok := byCode[order.Recipe.Code]
+		if !ok {
+			continue
+		}
+
+		day := planningDay(order.PlannedDate, today)
+		planned.Receipts[day] += float64(order.ServeCount)
+		planned.OnOrder += float64(order.ServeCount)
+
+		if order.Status != ProductionStatusPlanned {
+			continue
+		}
+		for _, line := range order.Lines {
+			if line.Item == nil {
+				continue
+			}
+			component, err := p.add(*line.Item)
+			if err != nil {
+				return err
+			}
+			component.Gross[day] += line.PlannedQuantity
+			component.Recipes[order.Recipe.Code] = true
+		}
+	}
+
+	return nil
+}
+
+// addPurchaseOrders schedules what is still to be received of the open
+// purchase orders. Overdue orders are expected today.
+func (p *requirementsPlanner) addPurchaseOrders(branchID string, today time.Time) error {
+	var open []struct {
+		ItemID       string
+		ExpectedDate time.Time
+		Remaining    float64
+	}
+	if err := p.db.Table("purchase_order_lines").
+		Select("purchase_order_lines.item_id, purchase_orders.expected_date, purchase_order_lines.quantity - purchase_order_lines.received_quantity AS remaining").
+		Joins("JOIN purchase_orders ON purchase_orders.id = purchase_order_lines.purchase_order_id").
+		Where("purchase_orders.branch_id = ? AND purchase_orders.status IN ?", branchID,
+			[]string{PurchaseStatusOrdered, PurchaseStatusPartiallyReceived}).
+		Where("purchase_order_lines.quantity > purchase_order_lines.received_quantity").
+		Scan(&open).Error; err != nil {
+		return err
+	}
+
+	for _, line := range open {
+		planned, ok := p.items[line.ItemID]
+		if !ok {
+			continue
+		}
+		planned.OnOrder += line.Remaining
+		if day := planningDay(line.ExpectedDate, today); day < p.horizon {
+			planned.Receipts[day] += line.Remaining
+		}
+	}
+
+	return nil
+}
+
+// ordered returns the items so that every half-finished item comes before
+// the components it is made of.
+func (p *requirementsPlanner) ordered() []*plannedItem {
+	items := make([]*plannedItem, 0, len(p.items))
+	for _, planned := range p.items {
+		p.lower(planned, map[string]bool{})
+	}
+	for _, planned := range p.items {
+		items = append(items, planned)
+	}
+
+	sort.Slice(items, func(a, b int) bool {
+		if items[a].Level != items[b].Level {
+			return items[a].Level < items[b].Level
+		}
+		return items[a].Item.Code < items[b].Item.Code
+	})
+
+	return items
+}
+
+func (p *requirementsPlanner) lower(planned *plannedItem, path map[string]bool) {
+	path[planned.Item.ID.String()] = true
+	defer delete(path, planned.Item.ID.String())
+
+	for _, component := range planned.Components {
+		child := p.items[component.Item.ID.String()]
+		if path[component.Item.ID.String()] || child.Level > planned.Level {
+			continue
+		}
+		child.Level = planned.Level + 1
+		p.lower(child, path)
+	}
+}
+
+// net projects the available quantity of the item day by day. Whatever the
+// projection falls short of is planned to arrive that day; it is returned
+// per day so half-finished items can explode it into their ingredients.
+func (p *requirementsPlanner) net(planned *plannedItem, today time.Time) (response.ItemRequirement, []float64) {
+	requirement := response.ItemRequirement{
+		ItemID:   planned.Item.ID.String(),
+		ItemCode: planned.Item.Code,
+		ItemName: planned.Item.Name,
+		Unit:     planned.Item.Unit,
+		LeadTime: planned.LeadTime,
+		Source:   "purchase",
+		OnHand:   roundQuantity(planned.Item.Stock),
+		Reserved: roundQuantity(planned.Item.Stock - planned.Available),
+		OnOrder:  roundQuantity(planned.OnOrder),
+		Recipes:  sortedKeys(planned.Recipes),
+		Days:     make([]response.RequirementDay, 0, p.horizon),
+		Orders:   []response.PlannedOrder{},
+	}
+	if planned.SubRecipe != nil {
+		requirement.Source = "production"
+	}
+
+	shortfall, projected := utils.NetRequirements(planned.Available, planned.Gross, planned.Receipts)
+	for day := 0; day < p.horizon; day++ {
+		requirement.Gross += planned.Gross[day]
+		requirement.Net += shortfall[day]
+		requirement.Days = append(requirement.Days, response.RequirementDay{
+			Date:              today.AddDate(0, 0, day).Format("2006-01-02"),
+			Gross:             roundQuantity(planned.Gross[day]),
+			ScheduledReceipts: roundQuantity(planned.Receipts[day]),
+			Net:               roundQuantity(shortfall[day]),
+			Projected:         roundQuantity(projected[day]),
+		})
+
+		if shortfall[day] == 0 {
+			continue
+		}
+		requirement.Orders = append(requirement.Orders, plannedOrder(today, day, planned.LeadTime, shortfall[day]))
+	}
+	requirement.Gross = roundQuantity(requirement.Gross)
+	requirement.Net = roundQuantity(requirement.Net)
+
+	return requirement, shortfall
+}
+
+// plannedOrder schedules what is needed on the day of the plan to be ordered
+// the lead time before; when that is already past it is late and ordered
+// today.
+func plannedOrder(today time.Time, day, leadTime int, quantity float64) response.PlannedOrder {
+	orderDay, late := utils.OrderDay(day, leadTime)
+	return response.PlannedOrder{
+		OrderDate: today.AddDate(0, 0, orderDay).Format("2006-01-02"),
+		NeedDate:  today.AddDate(0, 0, day).Format("2006-01-02"),
+		Quantity:  roundQuantity(quantity),
+		Late:      late,
+	}
+}
+
+// purchaseLeadTime is the lead time of the supplier the item is bought from,
+// chosen like recommendations choose it, or the item's own lead time when
+// there is no such supplier or it has none for the item.
+func purchaseLeadTime(item *model.Item, chosen *model.SupplierItem) int {
+	if chosen != nil && chosen.LeadTime != nil {
+		return *chosen.LeadTime
+	}
+	return item.LeadTime
+}
+
+// planningDay is the index of the date in a plan starting today; past dates
+// fall on today.
+func planningDay(date, today time.Time) int {
+	return max(int(math.Round(date.Sub(today).Hours()/24)), 0)
+}
+
+func hasDemand(series []float64) bool {
+	for _, value := range series {
+		if value > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func roundQuantity(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
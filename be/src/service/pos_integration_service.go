@@ -0,0 +1,160 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type PosIntegrationService interface {
+	CreateIntegration(c *fiber.Ctx, req *validation.CreatePosIntegration) (*model.PosIntegration, string, error)
+	GetIntegrations(c *fiber.Ctx, params *validation.QueryPosIntegration) ([]model.PosIntegration, error)
+	UpdateIntegration(c *fiber.Ctx, id string, req *validation.UpdatePosIntegration) (*model.PosIntegration, error)
+	RotateSecret(c *fiber.Ctx, id string) (*model.PosIntegration, string, error)
+}
+
+type posIntegrationService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewPosIntegrationService(db *gorm.DB, validate *validator.Validate) PosIntegrationService {
+	return &posIntegrationService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+// CreateIntegration registers a POS and returns its signing secret. The
+// secret is only shown here and when it is rotated.
+func (s *posIntegrationService) CreateIntegration(c *fiber.Ctx, req *validation.CreatePosIntegration) (*model.PosIntegration, string, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, "", err
+	}
+
+	var branch model.Branch
+	if err := s.DB.WithContext(c.Context()).First(&branch, "id = ? AND deleted_at IS NULL", req.BranchID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", fiber.NewError(fiber.StatusNotFound, "Branch not found")
+		}
+		return nil, "", err
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	integration := model.PosIntegration{
+		BranchID: req.BranchID,
+		Name:     req.Name,
+		Source:   req.Source,
+		Secret:   secret,
+		Active:   true,
+	}
+	if err := s.DB.WithContext(c.Context()).Create(&integration).Error; err != nil {
+		return nil, "", err
+	}
+
+	return &integration, secret, nil
+}
+
+func (s *posIntegrationService) GetIntegrations(c *fiber.Ctx, params *validation.QueryPosIntegration) ([]model.PosIntegration, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, err
+	}
+
+	var integrations []model.PosIntegration
+	if err := s.DB.WithContext(c.Context()).
+		Where("branch_id = ?", params.BranchID).
+		Order("name ASC").
+		Find(&integrations).Error; err != nil {
+		return nil, err
+	}
+
+	return integrations, nil
+}
+
+func (s *posIntegrationService) UpdateIntegration(c *fiber.Ctx, id string, req *validation.UpdatePosIntegration) (*model.PosIntegration, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	integration, err := s.findIntegration(c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.Active != nil {
+		updates["active"] = *req.Active
+	}
+
+	if len(updates) > 0 {
+		if err := s.DB.WithContext(c.Context()).Model(integration).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return s.findIntegration(c, id)
+}
+
+// RotateSecret replaces the signing secret; requests signed with the old one
+// are refused from now on.
+func (s *posIntegrationService) RotateSecret(c *fiber.Ctx, id string) (*model.PosIntegration, string, error) {
+	integration, err := s.findIntegration(c, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := s.DB.WithContext(c.Context()).Model(integration).Update("secret", secret).Error; err != nil {
+		return nil, "", err
+	}
+
+	return integration, secret, nil
+}
+
+func (s *posIntegrationService) findIntegration(c *fiber.Ctx, id string) (*model.PosIntegration, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid integration ID")
+	}
+
+	var integration model.PosIntegration
+	if err := s.DB.WithContext(c.Context()).First(&integration, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "POS integration not found")
+		}
+		return nil, err
+	}
+
+	return &integration, nil
+}
+
+func newWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
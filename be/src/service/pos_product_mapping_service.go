@@ -0,0 +1,188 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PosProductMappingService interface {
+	GetMappings(c *fiber.Ctx, params *validation.QueryPosProductMapping) ([]model.PosProductMapping, int64, error)
+	SaveMappings(c *fiber.Ctx, req *validation.SavePosProductMappings) ([]model.PosProductMapping, error)
+	DeleteMapping(c *fiber.Ctx, id string) error
+}
+
+type posProductMappingService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewPosProductMappingService(db *gorm.DB, validate *validator.Validate) PosProductMappingService {
+	return &posProductMappingService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+func (s *posProductMappingService) GetMappings(c *fiber.Ctx, params *validation.QueryPosProductMapping) ([]model.PosProductMapping, int64, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, 0, err
+	}
+
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 {
+		params.Limit = 10
+	}
+
+	query := s.DB.WithContext(c.Context()).Model(&model.PosProductMapping{}).
+		Where("branch_id = ?", params.BranchID)
+
+	if params.Search != "" {
+		search := "%" + strings.ToLower(params.Search) + "%"
+		query = query.Where("LOWER(product_code) LIKE ? OR LOWER(product_name) LIKE ?", search, search)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var mappings []model.PosProductMapping
+	if err := query.Preload("Recipe").
+		Order("product_code ASC").
+		Offset((params.Page - 1) * params.Limit).
+		Limit(params.Limit).
+		Find(&mappings).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return mappings, total, nil
+}
+
+// SaveMappings creates the mappings, or points existing product codes of the
+// branch to the new recipe.
+func (s *posProductMappingService) SaveMappings(c *fiber.Ctx, req *validation.SavePosProductMappings) ([]model.PosProductMapping, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, len(req.Mappings))
+	err := s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		for _, input := range req.Mappings {
+			var count int64
+			if err := tx.Model(&model.Recipe{}).
+				Where("id = ? AND branch_id = ? AND deleted_at IS NULL", input.RecipeID, req.BranchID).
+				Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("recipe %s not found in branch", input.RecipeID))
+			}
+
+			mapping := model.PosProductMapping{
+				BranchID:    req.BranchID,
+				ProductCode: strings.TrimSpace(input.ProductCode),
+				ProductName: input.ProductName,
+				RecipeID:    input.RecipeID,
+			}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "branch_id"}, {Name: "product_code"}},
+				DoUpdates: clause.AssignmentColumns([]string{"product_name", "recipe_id", "updated_at"}),
+			}).Create(&mapping).Error; err != nil {
+				return err
+			}
+
+			codes = append(codes, mapping.ProductCode)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var mappings []model.PosProductMapping
+	if err := s.DB.WithContext(c.Context()).Preload("Recipe").
+		Where("branch_id = ? AND product_code IN ?", req.BranchID, codes).
+		Order("product_code ASC").
+		Find(&mappings).Error; err != nil {
+		return nil, err
+	}
+
+	return mappings, nil
+}
+
+func (s *posProductMappingService) DeleteMapping(c *fiber.Ctx, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid mapping ID")
+	}
+
+	result := s.DB.WithContext(c.Context()).Delete(&model.PosProductMapping{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fiber.NewError(fiber.StatusNotFound, "mapping not found")
+	}
+
+	return nil
+}
+
+// resolvePosProducts maps POS product codes of the branch to recipes: through
+// the mapping table first, then to the recipe with the same code. Codes that
+// match neither are left out.
+func resolvePosProducts(db *gorm.DB, branchID string, codes []string) (map[string]*model.Recipe, error) {
+	recipes := map[string]*model.Recipe{}
+	if len(codes) == 0 {
+		return recipes, nil
+	}
+
+	var mappings []model.PosProductMapping
+	if err := db.Preload("Recipe", "deleted_at IS NULL").
+		Where("branch_id = ? AND product_code IN ?", branchID, codes).
+		Find(&mappings).Error; err != nil {
+		return nil, err
+	}
+	for i := range mappings {
+		if mappings[i].Recipe != nil {
+			recipes[mappings[i].ProductCode] = mappings[i].Recipe
+		}
+	}
+
+	var byCode []model.Recipe
+	if err := db.Where("branch_id = ? AND code IN ? AND deleted_at IS NULL", branchID, codes).
+		Find(&byCode).Error; err != nil {
+		return nil, err
+	}
+	for i := range byCode {
+		if _, ok := recipes[byCode[i].Code]; !ok {
+			recipes[byCode[i].Code] = &byCode[i]
+		}
+	}
+
+	return recipes, nil
+}
+
+// UnmappedProductsError is returned by a POS import that is not forced while
+// some product codes do not map to a recipe.
+type UnmappedProductsError struct {
+	Codes []string
+}
+
+func (e *UnmappedProductsError) Error() string {
+	return fmt.Sprintf("%d product code(s) are not mapped to a recipe", len(e.Codes))
+}
@@ -0,0 +1,468 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"app/src/model"
+	"app/src/response"
+	"app/src/utils"
+	"app/src/validation"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	WebhookStatusPending    = "pending"
+	WebhookStatusProcessing = "processing"
+	WebhookStatusProcessed  = "processed"
+	WebhookStatusFailed     = "failed"
+
+	SaleImportSourceWebhook = "webhook"
+
+	// signed requests older or newer than this are refused as replays
+	webhookTolerance = 5 * time.Minute
+	// pending events the queue missed are picked up by this sweep
+	webhookSweepInterval = time.Minute
+	// events processing for longer than this were interrupted and are requeued
+	webhookProcessingTimeout = 10 * time.Minute
+)
+
+type PosWebhookService interface {
+	ReceiveEvent(c *fiber.Ctx, integrationID string) (*model.PosWebhookEvent, bool, error)
+	GetEvent(c *fiber.Ctx, integrationID, eventID string) (*model.PosWebhookEvent, error)
+	GetEvents(c *fiber.Ctx, integrationID string, params *validation.QueryPosWebhookEvent) ([]model.PosWebhookEvent, int64, error)
+	GetIntegrationEvent(c *fiber.Ctx, integrationID, eventID string) (*model.PosWebhookEvent, error)
+	GetIntegrationEvents(c *fiber.Ctx, integrationID string, params *validation.QueryPosWebhookEvent) ([]model.PosWebhookEvent, int64, error)
+	Start()
+	Stop()
+}
+
+type posWebhookService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+
+	queue    chan uuid.UUID
+	once     sync.Once
+	stopOnce sync.Once
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+func NewPosWebhookService(db *gorm.DB, validate *validator.Validate) PosWebhookService {
+	return &posWebhookService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+		queue:    make(chan uuid.UUID, 256),
+	}
+}
+
+// ReceiveEvent authenticates a pushed sale and stores it for processing. An
+// event ID seen before is not stored again: its delivery status is returned
+// with duplicate set, except for failed events, which are queued again with
+// the new payload so a POS can replay them once the cause is fixed.
+func (s *posWebhookService) ReceiveEvent(c *fiber.Ctx, integrationID string) (*model.PosWebhookEvent, bool, error) {
+	integration, err := s.authenticate(c, integrationID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var req validation.PosWebhookSale
+	if err := json.Unmarshal(c.Body(), &req); err != nil {
+		return nil, false, fiber.NewError(fiber.StatusBadRequest, "invalid JSON payload")
+	}
+	if err := s.Validate.Struct(&req); err != nil {
+		return nil, false, err
+	}
+
+	var event model.PosWebhookEvent
+	duplicate := false
+	err = s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&event, "integration_id = ? AND event_id = ?", integration.ID, req.EventID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			event = model.PosWebhookEvent{
+				IntegrationID: integration.ID.String(),
+				EventID:       req.EventID,
+				Payload:       string(c.Body()),
+				Status:        WebhookStatusPending,
+				ReceivedAt:    time.Now(),
+			}
+			return tx.Create(&event).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		if event.Status != WebhookStatusFailed {
+			duplicate = true
+			return nil
+		}
+
+		event.Payload = string(c.Body())
+		event.Status = WebhookStatusPending
+		event.Error = ""
+		return tx.Model(&event).Updates(map[string]interface{}{
+			"payload": event.Payload,
+			"status":  event.Status,
+			"error":   "",
+		}).Error
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !duplicate {
+		s.enqueue(event.ID)
+	}
+
+	return &event, duplicate, nil
+}
+
+// GetEvent returns one delivery to the POS that sent it; the request is
+// signed like a push.
+func (s *posWebhookService) GetEvent(c *fiber.Ctx, integrationID, eventID string) (*model.PosWebhookEvent, error) {
+	integration, err := s.authenticate(c, integrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.findEvent(c, integration.ID, eventID)
+}
+
+// GetEvents lists the deliveries of the integration, newest first, so a POS
+// can find the events it has to replay. The request is signed like a push.
+func (s *posWebhookService) GetEvents(c *fiber.Ctx, integrationID string, params *validation.QueryPosWebhookEvent) ([]model.PosWebhookEvent, int64, error) {
+	integration, err := s.authenticate(c, integrationID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return s.listEvents(c, integration.ID, params)
+}
+
+// GetIntegrationEvent is GetEvent for signed-in users, who do not hold the
+// integration secret.
+func (s *posWebhookService) GetIntegrationEvent(c *fiber.Ctx, integrationID, eventID string) (*model.PosWebhookEvent, error) {
+	integration, err := s.findIntegration(c, integrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.findEvent(c, integration.ID, eventID)
+}
+
+// GetIntegrationEvents is GetEvents for signed-in users, who do not hold the
+// integration secret.
+func (s *posWebhookService) GetIntegrationEvents(c *fiber.Ctx, integrationID string, params *validation.QueryPosWebhookEvent) ([]model.PosWebhookEvent, int64, error) {
+	integration, err := s.findIntegration(c, integrationID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return s.listEvents(c, integration.ID, params)
+}
+
+func (s *posWebhookService) findEvent(c *fiber.Ctx, integrationID uuid.UUID, eventID string) (*model.PosWebhookEvent, error) {
+	var event model.PosWebhookEvent
+	if err := s.DB.WithContext(c.Context()).
+		First(&event, "integration_id = ? AND event_id = ?", integrationID, eventID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "event not found")
+		}
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+func (s *posWebhookService) listEvents(c *fiber.Ctx, integrationID uuid.UUID, params *validation.QueryPosWebhookEvent) ([]model.PosWebhookEvent, int64, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, 0, err
+	}
+
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 {
+		params.Limit = 10
+	}
+
+	query := s.DB.WithContext(c.Context()).Model(&model.PosWebhookEvent{}).
+		Where("integration_id = ?", integrationID)
+
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+	if params.Since != "" {
+		query = query.Where("received_at >= ?", params.Since)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var events []model.PosWebhookEvent
+	if err := query.Order("received_at DESC").
+		Offset((params.Page - 1) * params.Limit).
+		Limit(params.Limit).
+		Find(&events).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
+
+// Start runs the worker that records queued events as sales, one at a time.
+// Events left pending by a restart or a full queue are swept up periodically.
+func (s *posWebhookService) Start() {
+	s.once.Do(func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		s.cancel = cancel
+		s.done = make(chan struct{})
+		go s.run(ctx)
+	})
+}
+
+// Stop lets the worker finish the event it is processing and waits for it to
+// exit. Queued events stay pending for the next start.
+func (s *posWebhookService) Stop() {
+	s.stopOnce.Do(func() {
+		if s.cancel == nil {
+			return
+		}
+		s.cancel()
+		<-s.done
+	})
+}
+
+func (s *posWebhookService) run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(webhookSweepInterval)
+	defer ticker.Stop()
+
+	s.sweep(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-s.queue:
+			s.process(id)
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+func (s *posWebhookService) enqueue(id uuid.UUID) {
+	select {
+	case s.queue <- id:
+	default:
+		// the sweep picks it up
+	}
+}
+
+func (s *posWebhookService) sweep(ctx context.Context) {
+	// events stuck in processing were interrupted by a restart
+	if err := s.DB.Model(&model.PosWebhookEvent{}).
+		Where("status = ? AND (processing_started_at IS NULL OR processing_started_at < ?)",
+			WebhookStatusProcessing, time.Now().Add(-webhookProcessingTimeout)).
+		Update("status", WebhookStatusPending).Error; err != nil {
+		s.Log.Errorf("Failed to requeue webhook events: %+v", err)
+		return
+	}
+
+	var ids []uuid.UUID
+	if err := s.DB.Model(&model.PosWebhookEvent{}).
+		Where("status = ?", WebhookStatusPending).
+		Order("received_at ASC").
+		Limit(100).
+		Pluck("id", &ids).Error; err != nil {
+		s.Log.Errorf("Failed to load pending webhook events: %+v", err)
+		return
+	}
+
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return
+		}
+		s.process(id)
+	}
+}
+
+// process records the sale of a pending event. Products that do not map fail
+// the event, which the POS replays once they are mapped.
+func (s *posWebhookService) process(id uuid.UUID) {
+	claim := s.DB.Model(&model.PosWebhookEvent{}).
+		Where("id = ? AND status = ?", id, WebhookStatusPending).
+		Updates(map[string]interface{}{
+			"status":                WebhookStatusProcessing,
+			"attempts":              gorm.Expr("attempts + 1"),
+			"processing_started_at": time.Now(),
+		})
+	if claim.Error != nil {
+		s.Log.Errorf("Failed to claim webhook event %s: %+v", id, claim.Error)
+		return
+	}
+	if claim.RowsAffected == 0 {
+		return
+	}
+
+	var event model.PosWebhookEvent
+	if err := s.DB.First(&event, "id = ?", id).Error; err != nil {
+		s.Log.Errorf("Failed to load webhook event %s: %+v", id, err)
+		return
+	}
+
+	report, err := s.recordEvent(&event)
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":       WebhookStatusProcessed,
+		"error":        "",
+		"processed_at": now,
+	}
+	if err != nil {
+		updates["status"] = WebhookStatusFailed
+		updates["error"] = err.Error()
+		var unmapped *UnmappedProductsError
+		if errors.As(err, &unmapped) {
+			updates["error"] = fmt.Sprintf("%s: %v", err.Error(), unmapped.Codes)
+		}
+	} else {
+		updates["import_id"] = report.ImportID
+		updates["sale_count"] = report.Imported
+	}
+
+	if err := s.DB.Model(&event).Updates(updates).Error; err != nil {
+		s.Log.Errorf("Failed to update webhook event %s: %+v", id, err)
+	}
+}
+
+func (s *posWebhookService) recordEvent(event *model.PosWebhookEvent) (*response.SaleImportReport, error) {
+	var integration model.PosIntegration
+	if err := s.DB.First(&integration, "id = ?", event.IntegrationID).Error; err != nil {
+		return nil, err
+	}
+
+	var req validation.PosWebhookSale
+	if err := json.Unmarshal([]byte(event.Payload), &req); err != nil {
+		return nil, err
+	}
+
+	occurredAt, err := time.Parse(time.RFC3339, req.OccurredAt)
+	if err != nil {
+		return nil, err
+	}
+	date := time.Date(occurredAt.Year(), occurredAt.Month(), occurredAt.Day(), 0, 0, 0, 0, time.UTC)
+
+	var lines []utils.PosSaleLine
+	for i, line := range req.Lines {
+		ref := utils.LineRef(integration.ID.String(), event.EventID, strconv.Itoa(i))
+		lines = append(lines, utils.PosSaleLine{
+			Row:         i + 1,
+			Date:        date,
+			ProductCode: line.ProductCode,
+			ProductName: line.ProductName,
+			Variant:     line.Variant,
+			Quantity:    line.Quantity,
+			Price:       line.Price,
+			ExternalRef: ref,
+		})
+
+		for j, modifier := range line.Modifiers {
+			lines = append(lines, utils.PosSaleLine{
+				Row:         i + 1,
+				Date:        date,
+				ProductCode: modifier,
+				ProductName: modifier,
+				Quantity:    line.Quantity,
+				ExternalRef: utils.LineRef(ref, "modifier", strconv.Itoa(j)),
+				Optional:    true,
+			})
+		}
+	}
+
+	var report *response.SaleImportReport
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		batch := model.SalesImport{
+			BranchID: integration.BranchID,
+			Source:   SaleImportSourceWebhook,
+			FileName: fmt.Sprintf("%s event %s", integration.Name, event.EventID),
+		}
+
+		var err error
+		report, err = importSaleLines(tx, &batch, lines)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// findIntegration looks an integration up for a signed-in user, inactive
+// ones included so their past deliveries stay visible.
+func (s *posWebhookService) findIntegration(c *fiber.Ctx, id string) (*model.PosIntegration, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid integration ID")
+	}
+
+	var integration model.PosIntegration
+	if err := s.DB.WithContext(c.Context()).First(&integration, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "POS integration not found")
+		}
+		return nil, err
+	}
+
+	return &integration, nil
+}
+
+// authenticate checks the X-Pos-Timestamp and X-Pos-Signature headers of a
+// request against the secret of the integration. The signature is the hex
+// HMAC-SHA256 of "timestamp.body"; requests without a body sign an empty one.
+func (s *posWebhookService) authenticate(c *fiber.Ctx, integrationID string) (*model.PosIntegration, error) {
+	if _, err := uuid.Parse(integrationID); err != nil {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "invalid signature")
+	}
+
+	var integration model.PosIntegration
+	if err := s.DB.WithContext(c.Context()).
+		First(&integration, "id = ? AND active = ?", integrationID, true).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusUnauthorized, "invalid signature")
+		}
+		return nil, err
+	}
+
+	timestamp := c.Get("X-Pos-Timestamp")
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "missing or invalid X-Pos-Timestamp")
+	}
+	if skew := time.Since(time.Unix(seconds, 0)); skew > webhookTolerance || skew < -webhookTolerance {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "request timestamp is outside the allowed window")
+	}
+
+	if !utils.VerifyPayload(integration.Secret, timestamp, c.Body(), c.Get("X-Pos-Signature")) {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "invalid signature")
+	}
+
+	return &integration, nil
+}
@@ -0,0 +1,404 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	ProductionStatusPlanned   = "planned"
+	ProductionStatusReleased  = "released"
+	ProductionStatusCompleted = "completed"
+	ProductionStatusCancelled = "cancelled"
+)
+
+type ProductionOrderService interface {
+	CreateProductionOrder(c *fiber.Ctx, req *validation.CreateProductionOrder) (*model.ProductionOrder, error)
+	GetProductionOrders(c *fiber.Ctx, params *validation.QueryProductionOrder) ([]model.ProductionOrder, int64, error)
+	GetProductionOrderByID(c *fiber.Ctx, id string) (*model.ProductionOrder, error)
+	ReleaseProductionOrder(c *fiber.Ctx, id string) (*model.ProductionOrder, error)
+	CompleteProductionOrder(c *fiber.Ctx, id string, req *validation.CompleteProductionOrder) (*model.ProductionOrder, error)
+	CancelProductionOrder(c *fiber.Ctx, id string) (*model.ProductionOrder, error)
+}
+
+type productionOrderService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewProductionOrderService(db *gorm.DB, validate *validator.Validate) ProductionOrderService {
+	return &productionOrderService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+func (s *productionOrderService) CreateProductionOrder(c *fiber.Ctx, req *validation.CreateProductionOrder) (*model.ProductionOrder, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	plannedDate, err := time.Parse("2006-01-02", req.PlannedDate)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid planned_date")
+	}
+
+	var recipe model.Recipe
+	if err := s.DB.WithContext(c.Context()).Preload("Ingredients").
+		First(&recipe, "id = ? AND branch_id = ? AND deleted_at IS NULL", req.RecipeID, req.BranchID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "recipe not found in branch")
+		}
+		return nil, err
+	}
+
+	if len(recipe.Ingredients) == 0 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "recipe has no ingredients")
+	}
+
+	// Shortages are only checked when the order is released.
+	requirements, _, err := planRecipeConsumption(s.DB.WithContext(c.Context()), &recipe, float64(req.ServeCount), false, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	order := model.ProductionOrder{
+		BranchID:      req.BranchID,
+		RecipeID:      req.RecipeID,
+		RecipeVersion: recipe.CurrentVersion,
+		PlannedDate:   plannedDate,
+		ServeCount:    req.ServeCount,
+		Status:        ProductionStatusPlanned,
+		Note:          req.Note,
+	}
+	if req.CreatedBy != "" {
+		order.CreatedBy = &req.CreatedBy
+	}
+
+	for _, requirement := range requirements {
+		order.Lines = append(order.Lines, model.ProductionOrderLine{
+			ItemID:          requirement.Item.ID.String(),
+			PlannedQuantity: requirement.Required,
+			Unit:            requirement.Item.Unit,
+		})
+	}
+
+	if err := s.DB.WithContext(c.Context()).Create(&order).Error; err != nil {
+		return nil, err
+	}
+
+	return s.GetProductionOrderByID(c, order.ID.String())
+}
+
+func (s *productionOrderService) GetProductionOrders(c *fiber.Ctx, params *validation.QueryProductionOrder) ([]model.ProductionOrder, int64, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, 0, err
+	}
+
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 {
+		params.Limit = 10
+	}
+
+	query := s.DB.WithContext(c.Context()).Model(&model.ProductionOrder{}).
+		Where("branch_id = ?", params.BranchID)
+
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+	if params.StartDate != "" {
+		query = query.Where("planned_date >= ?", params.StartDate)
+	}
+	if params.EndDate != "" {
+		query = query.Where("planned_date <= ?", params.EndDate)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var orders []model.ProductionOrder
+	if err := query.Preload("Recipe").
+		Order("planned_date DESC, created_at DESC").
+		Offset((params.Page - 1) * params.Limit).
+		Limit(params.Limit).
+		Find(&orders).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return orders, total, nil
+}
+
+func (s *productionOrderService) GetProductionOrderByID(c *fiber.Ctx, id string) (*model.ProductionOrder, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid production order ID")
+	}
+
+	var order model.ProductionOrder
+	if err := s.DB.WithContext(c.Context()).
+		Preload("Recipe").
+		Preload("Lines.Item").
+		First(&order, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "production order not found")
+		}
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// ReleaseProductionOrder reserves the planned quantity of every line so the
+// stock cannot be used elsewhere before the order is completed.
+func (s *productionOrderService) ReleaseProductionOrder(c *fiber.Ctx, id string) (*model.ProductionOrder, error) {
+	err := s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		order, err := lockProductionOrder(tx, id)
+		if err != nil {
+			return err
+		}
+
+		if order.Status != ProductionStatusPlanned {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("cannot release a %s production order", order.Status))
+		}
+
+		source := movementRef{Type: ReservationSourceProductionOrder, ID: order.ID.String()}
+		var insufficientItems []string
+
+		for _, line := range order.Lines {
+			var item model.Item
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				First(&item, "id = ? AND deleted_at IS NULL", line.ItemID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("item %s of the order has been deleted", line.ItemID))
+				}
+				return err
+			}
+
+			if _, err := reserveStock(tx, &item, line.PlannedQuantity, source); err != nil {
+				var fiberErr *fiber.Error
+				if !errors.As(err, &fiberErr) {
+					return err
+				}
+				insufficientItems = append(insufficientItems, fiberErr.Message)
+			}
+		}
+
+		if len(insufficientItems) > 0 {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("insufficient stock for items: %v", insufficientItems))
+		}
+
+		now := time.Now()
+		return tx.Model(order).Updates(map[string]interface{}{
+			"status":      ProductionStatusReleased,
+			"released_at": now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetProductionOrderByID(c, id)
+}
+
+// CompleteProductionOrder consumes the actual quantities used. Lines without
+// an actual quantity are scaled from the plan by the actual serve count. A
+// half-finished recipe also books its output into the item it produces.
+func (s *productionOrderService) CompleteProductionOrder(c *fiber.Ctx, id string, req *validation.CompleteProductionOrder) (*model.ProductionOrder, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	err := s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		order, err := lockProductionOrder(tx, id)
+		if err != nil {
+			return err
+		}
+
+		if order.Status != ProductionStatusReleased {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("cannot complete a %s production order", order.Status))
+		}
+
+		actualServeCount := order.ServeCount
+		if req.ActualServeCount > 0 {
+			actualServeCount = req.ActualServeCount
+		}
+
+		planned := make(map[string]bool, len(order.Lines))
+		for _, line := range order.Lines {
+			planned[line.ItemID] = true
+		}
+		actuals := make(map[string]float64, len(req.Lines))
+		for _, line := range req.Lines {
+			if !planned[line.ItemID] {
+				return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("item %s is not planned in the production order", line.ItemID))
+			}
+			if _, ok := actuals[line.ItemID]; ok {
+				return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("item %s is given more than once", line.ItemID))
+			}
+			actuals[line.ItemID] = line.ActualQuantity
+		}
+
+		source := movementRef{Type: ReservationSourceProductionOrder, ID: order.ID.String()}
+		if err := settleReservations(tx, source, ReservationStatusConsumed); err != nil {
+			return err
+		}
+
+		var recipe model.Recipe
+		if err := tx.First(&recipe, "id = ?", order.RecipeID).Error; err != nil {
+			return err
+		}
+
+		note := fmt.Sprintf("Production: %s (%d servings)", recipe.Name, actualServeCount)
+		var insufficientItems []string
+
+		for i := range order.Lines {
+			line := &order.Lines[i]
+
+			actual, ok := actuals[line.ItemID]
+			if !ok {
+				actual = line.PlannedQuantity * float64(actualServeCount) / float64(order.ServeCount)
+			}
+
+			var item model.Item
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				First(&item, "id = ? AND deleted_at IS NULL", line.ItemID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("item %s of the order has been deleted", line.ItemID))
+				}
+				return err
+			}
+
+			available, err := availableQuantity(tx, &item, nil)
+			if err != nil {
+				return err
+			}
+			if available < actual {
+				insufficientItems = append(insufficientItems, IngredientShortage{
+					ItemName:  item.Name,
+					ItemCode:  item.Code,
+					Required:  actual,
+					Available: available,
+					Shortage:  actual - available,
+					Unit:      item.Unit,
+				}.String())
+				continue
+			}
+
+			if actual > 0 {
+				if _, err := postMovement(tx, &item, "out", actual, note, &source); err != nil {
+					return err
+				}
+			}
+
+			if err := tx.Model(line).Updates(map[string]interface{}{
+				"actual_quantity": actual,
+				"variance":        actual - line.PlannedQuantity,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(insufficientItems) > 0 {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("insufficient stock for items: %v", insufficientItems))
+		}
+
+		if recipe.Type == RecipeTypeHalfFinished {
+			var produced model.Item
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("branch_id = ? AND code = ? AND deleted_at IS NULL", order.BranchID, recipe.Code).
+				First(&produced).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			if err == nil {
+				if _, err := postMovement(tx, &produced, "cook_in", float64(actualServeCount), note, &source); err != nil {
+					return err
+				}
+			}
+		}
+
+		now := time.Now()
+		updates := map[string]interface{}{
+			"status":             ProductionStatusCompleted,
+			"actual_serve_count": actualServeCount,
+			"completed_at":       now,
+		}
+		if req.CompletedBy != "" {
+			updates["completed_by"] = req.CompletedBy
+		}
+
+		return tx.Model(order).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetProductionOrderByID(c, id)
+}
+
+func (s *productionOrderService) CancelProductionOrder(c *fiber.Ctx, id string) (*model.ProductionOrder, error) {
+	err := s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		order, err := lockProductionOrder(tx, id)
+		if err != nil {
+			return err
+		}
+
+		if order.Status != ProductionStatusPlanned && order.Status != ProductionStatusReleased {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("cannot cancel a %s production order", order.Status))
+		}
+
+		source := movementRef{Type: ReservationSourceProductionOrder, ID: order.ID.String()}
+		if err := settleReservations(tx, source, ReservationStatusReleased); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		return tx.Model(order).Updates(map[string]interface{}{
+			"status":       ProductionStatusCancelled,
+			"cancelled_at": now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetProductionOrderByID(c, id)
+}
+
+func lockProductionOrder(tx *gorm.DB, id string) (*model.ProductionOrder, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid production order ID")
+	}
+
+	var order model.ProductionOrder
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&order, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "production order not found")
+		}
+		return nil, err
+	}
+
+	if err := tx.Where("production_order_id = ?", order.ID).Find(&order.Lines).Error; err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
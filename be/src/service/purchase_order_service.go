@@ -0,0 +1,288 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	PurchaseOrderReference = "purchase_order"
+
+	PurchaseStatusOrdered           = "ordered"
+	PurchaseStatusPartiallyReceived = "partially_received"
+	PurchaseStatusReceived          = "received"
+	PurchaseStatusCancelled         = "cancelled"
+)
+
+type PurchaseOrderService interface {
+	CreatePurchaseOrder(c *fiber.Ctx, req *validation.CreatePurchaseOrder) (*model.PurchaseOrder, error)
+	GetPurchaseOrders(c *fiber.Ctx, params *validation.QueryPurchaseOrder) ([]model.PurchaseOrder, int64, error)
+	GetPurchaseOrderByID(c *fiber.Ctx, id string) (*model.PurchaseOrder, error)
+	ReceivePurchaseOrder(c *fiber.Ctx, id string, req *validation.ReceivePurchaseOrder) (*model.PurchaseOrder, error)
+	CancelPurchaseOrder(c *fiber.Ctx, id string) (*model.PurchaseOrder, error)
+}
+
+type purchaseOrderService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewPurchaseOrderService(db *gorm.DB, validate *validator.Validate) PurchaseOrderService {
+	return &purchaseOrderService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+func (s *purchaseOrderService) CreatePurchaseOrder(c *fiber.Ctx, req *validation.CreatePurchaseOrder) (*model.PurchaseOrder, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	orderDate := time.Now()
+	if req.OrderDate != "" {
+		orderDate, _ = time.Parse("2006-01-02", req.OrderDate)
+	}
+	expectedDate, _ := time.Parse("2006-01-02", req.ExpectedDate)
+
+	order := model.PurchaseOrder{
+		BranchID:     req.BranchID,
+		Status:       PurchaseStatusOrdered,
+		OrderDate:    orderDate,
+		ExpectedDate: expectedDate,
+		Note:         req.Note,
+		CreatedBy:    currentUserID(c),
+	}
+
+	db := s.DB.WithContext(c.Context())
+	if req.SupplierID != "" {
+		if _, err := findSupplier(db, req.SupplierID); err != nil {
+			return nil, err
+		}
+		order.SupplierID = &req.SupplierID
+	}
+	for _, line := range req.Lines {
+		var count int64
+		if err := db.Model(&model.Item{}).
+			Where("id = ? AND branch_id = ? AND deleted_at IS NULL", line.ItemID, req.BranchID).
+			Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("item %s not found in branch", line.ItemID))
+		}
+
+		order.Lines = append(order.Lines, model.PurchaseOrderLine{
+			ItemID:   line.ItemID,
+			Quantity: line.Quantity,
+			UnitCost: line.UnitCost,
+		})
+	}
+
+	if err := db.Create(&order).Error; err != nil {
+		return nil, err
+	}
+
+	return s.GetPurchaseOrderByID(c, order.ID.String())
+}
+
+func (s *purchaseOrderService) GetPurchaseOrders(c *fiber.Ctx, params *validation.QueryPurchaseOrder) ([]model.PurchaseOrder, int64, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, 0, err
+	}
+
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 {
+		params.Limit = 10
+	}
+
+	query := s.DB.WithContext(c.Context()).Model(&model.PurchaseOrder{}).
+		Where("branch_id = ?", params.BranchID)
+
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+	if params.StartDate != "" {
+		query = query.Where("expected_date >= ?", params.StartDate)
+	}
+	if params.EndDate != "" {
+		query = query.Where("expected_date <= ?", params.EndDate)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var orders []model.PurchaseOrder
+	if err := query.Preload("Supplier").Preload("Lines.Item").
+		Order("expected_date DESC, created_at DESC").
+		Offset((params.Page - 1) * params.Limit).
+		Limit(params.Limit).
+		Find(&orders).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return orders, total, nil
+}
+
+func (s *purchaseOrderService) GetPurchaseOrderByID(c *fiber.Ctx, id string) (*model.PurchaseOrder, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid purchase order ID")
+	}
+
+	var order model.PurchaseOrder
+	if err := s.DB.WithContext(c.Context()).
+		Preload("Supplier").Preload("Lines.Item").
+		First(&order, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "purchase order not found")
+		}
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// ReceivePurchaseOrder books delivered goods into stock as "in" movements
+// referencing the order. An item may be delivered in several parts but never
+// beyond what was ordered.
+func (s *purchaseOrderService) ReceivePurchaseOrder(c *fiber.Ctx, id string, req *validation.ReceivePurchaseOrder) (*model.PurchaseOrder, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	err := s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		order, err := lockPurchaseOrder(tx, id)
+		if err != nil {
+			return err
+		}
+
+		if order.Status != PurchaseStatusOrdered && order.Status != PurchaseStatusPartiallyReceived {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("cannot receive a %s purchase order", order.Status))
+		}
+
+		received := map[string]float64{}
+		for _, line := range req.Lines {
+			received[line.ItemID] += line.Quantity
+		}
+		if len(req.Lines) == 0 {
+			for _, line := range order.Lines {
+				received[line.ItemID] += line.Quantity - line.ReceivedQuantity
+			}
+		}
+
+		ref := &movementRef{Type: PurchaseOrderReference, ID: order.ID.String()}
+		note := fmt.Sprintf("Purchase order received on %s", time.Now().Format("2006-01-02"))
+		if req.Note != "" {
+			note = fmt.Sprintf("%s: %s", note, req.Note)
+		}
+
+		complete := true
+		for i := range order.Lines {
+			line := &order.Lines[i]
+			quantity := math.Min(received[line.ItemID], line.Quantity-line.ReceivedQuantity)
+			received[line.ItemID] -= quantity
+
+			if quantity > 1e-9 {
+				var item model.Item
+				if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+					First(&item, "id = ?", line.ItemID).Error; err != nil {
+					return err
+				}
+				if _, err := postMovement(tx, &item, "in", quantity, note, ref); err != nil {
+					return err
+				}
+
+				line.ReceivedQuantity += quantity
+				if err := tx.Model(line).Update("received_quantity", line.ReceivedQuantity).Error; err != nil {
+					return err
+				}
+			}
+
+			if line.Quantity-line.ReceivedQuantity > 1e-9 {
+				complete = false
+			}
+		}
+
+		for itemID, left := range received {
+			if left > 1e-9 {
+				return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("item %s: %.2f more received than ordered", itemID, left))
+			}
+		}
+
+		updates := map[string]interface{}{"status": PurchaseStatusPartiallyReceived}
+		if complete {
+			updates = map[string]interface{}{"status": PurchaseStatusReceived, "received_at": time.Now()}
+		}
+		return tx.Model(order).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetPurchaseOrderByID(c, id)
+}
+
+// CancelPurchaseOrder stops waiting for what was not delivered yet. Stock
+// already received stays.
+func (s *purchaseOrderService) CancelPurchaseOrder(c *fiber.Ctx, id string) (*model.PurchaseOrder, error) {
+	err := s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		order, err := lockPurchaseOrder(tx, id)
+		if err != nil {
+			return err
+		}
+
+		if order.Status != PurchaseStatusOrdered && order.Status != PurchaseStatusPartiallyReceived {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("cannot cancel a %s purchase order", order.Status))
+		}
+
+		return tx.Model(order).Updates(map[string]interface{}{
+			"status":       PurchaseStatusCancelled,
+			"cancelled_at": time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetPurchaseOrderByID(c, id)
+}
+
+func lockPurchaseOrder(tx *gorm.DB, id string) (*model.PurchaseOrder, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid purchase order ID")
+	}
+
+	var order model.PurchaseOrder
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&order, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "purchase order not found")
+		}
+		return nil, err
+	}
+
+	if err := tx.Where("purchase_order_id = ?", order.ID).Find(&order.Lines).Error; err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
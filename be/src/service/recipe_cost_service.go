@@ -0,0 +1,396 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"app/src/model"
+	"app/src/response"
+	"app/src/utils"
+	"app/src/validation"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	CostReasonRecipeChange   = "recipe_change"
+	CostReasonItemCostChange = "item_cost_change"
+	CostReasonPriceChange    = "price_change"
+)
+
+type RecipeCostService interface {
+	GetRecipeCost(c *fiber.Ctx, id string, params *validation.QueryRecipeCost) (*response.RecipeCostResponse, error)
+	SetSellingPrice(c *fiber.Ctx, id string, req *validation.SetRecipePrice) (*response.RecipeCostResponse, error)
+	GetCostHistory(c *fiber.Ctx, id string, params *validation.QueryRecipeCostHistory) ([]model.RecipeCostHistory, error)
+}
+
+type recipeCostService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewRecipeCostService(db *gorm.DB, validate *validator.Validate) RecipeCostService {
+	return &recipeCostService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+func (s *recipeCostService) GetRecipeCost(c *fiber.Ctx, id string, params *validation.QueryRecipeCost) (*response.RecipeCostResponse, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, err
+	}
+
+	recipe, err := s.findRecipe(s.DB.WithContext(c.Context()), id)
+	if err != nil {
+		return nil, err
+	}
+
+	branchID := params.BranchID
+	if branchID == "" {
+		branchID = recipe.BranchID
+	}
+
+	return calculateRecipeCost(s.DB.WithContext(c.Context()), recipe, branchID)
+}
+
+func (s *recipeCostService) SetSellingPrice(c *fiber.Ctx, id string, req *validation.SetRecipePrice) (*response.RecipeCostResponse, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	recipe, err := s.findRecipe(s.DB.WithContext(c.Context()), id)
+	if err != nil {
+		return nil, err
+	}
+
+	branchID := req.BranchID
+	if branchID == "" {
+		branchID = recipe.BranchID
+	}
+
+	var cost *response.RecipeCostResponse
+	err = s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		price := model.RecipePrice{
+			RecipeID:     recipe.ID.String(),
+			BranchID:     branchID,
+			SellingPrice: req.SellingPrice,
+		}
+
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "recipe_id"}, {Name: "branch_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"selling_price": req.SellingPrice, "updated_at": time.Now()}),
+		}).Create(&price).Error; err != nil {
+			return err
+		}
+
+		var err error
+		cost, err = recordRecipeCost(tx, recipe, branchID, CostReasonPriceChange)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cost, nil
+}
+
+func (s *recipeCostService) GetCostHistory(c *fiber.Ctx, id string, params *validation.QueryRecipeCostHistory) ([]model.RecipeCostHistory, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, err
+	}
+
+	recipe, err := s.findRecipe(s.DB.WithContext(c.Context()), id)
+	if err != nil {
+		return nil, err
+	}
+
+	branchID := params.BranchID
+	if branchID == "" {
+		branchID = recipe.BranchID
+	}
+
+	query := s.DB.WithContext(c.Context()).
+		Where("recipe_id = ? AND branch_id = ?", recipe.ID, branchID)
+
+	if params.StartDate != "" {
+		query = query.Where("calculated_at >= ?", params.StartDate)
+	}
+	if params.EndDate != "" {
+		query = query.Where("calculated_at < ?::date + INTERVAL '1 day'", params.EndDate)
+	}
+
+	var histories []model.RecipeCostHistory
+	if err := query.Order("calculated_at ASC").Find(&histories).Error; err != nil {
+		return nil, err
+	}
+
+	return histories, nil
+}
+
+func (s *recipeCostService) findRecipe(db *gorm.DB, id string) (*model.Recipe, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid recipe ID")
+	}
+
+	var recipe model.Recipe
+	if err := db.Preload("Ingredients.Item").First(&recipe, "id = ? AND deleted_at IS NULL", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "recipe not found")
+		}
+		return nil, err
+	}
+
+	return &recipe, nil
+}
+
+// calculateRecipeCost computes the theoretical cost of one serving of the
+// recipe. Ingredient quantities are converted into the item's stock unit the
+// way cooking converts them before being multiplied by the item's unit cost.
+// Lines that cannot be costed leave the cost incomplete: it is then too low
+// to be used and the warnings say why.
+func calculateRecipeCost(db *gorm.DB, recipe *model.Recipe, branchID string) (*response.RecipeCostResponse, error) {
+	costPerServing, lines, warnings, err := recipeCostPerServing(db, recipe, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+
+	var price model.RecipePrice
+	if err := db.Where("recipe_id = ? AND branch_id = ?", recipe.ID, branchID).
+		First(&price).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	res := &response.RecipeCostResponse{
+		RecipeID:       recipe.ID.String(),
+		RecipeCode:     recipe.Code,
+		RecipeName:     recipe.Name,
+		BranchID:       branchID,
+		CostPerServing: roundMoney(costPerServing),
+		SellingPrice:   price.SellingPrice,
+		Ingredients:    lines,
+		Incomplete:     len(warnings) > 0,
+		Warnings:       warnings,
+	}
+
+	if price.SellingPrice > 0 {
+		res.FoodCostPct = roundMoney(costPerServing / price.SellingPrice * 100)
+		res.GrossMargin = roundMoney(price.SellingPrice - costPerServing)
+		res.GrossMarginPct = roundMoney(res.GrossMargin / price.SellingPrice * 100)
+	}
+
+	return res, nil
+}
+
+func recipeCostPerServing(db *gorm.DB, recipe *model.Recipe, visited map[string]bool) (float64, []response.RecipeIngredientCost, []string, error) {
+	visited[recipe.ID.String()] = true
+	defer delete(visited, recipe.ID.String())
+
+	if recipe.Ingredients == nil {
+		if err := db.Preload("Item").Where("recipe_id = ?", recipe.ID).
+			Find(&recipe.Ingredients).Error; err != nil {
+			return 0, nil, nil, err
+		}
+	}
+
+	var total float64
+	lines := make([]response.RecipeIngredientCost, 0, len(recipe.Ingredients))
+	var warnings []string
+	yield := recipeYield(recipe)
+
+	for _, ing := range recipe.Ingredients {
+		if ing.Item == nil {
+			warnings = append(warnings, fmt.Sprintf("item %s not found", ing.ItemID))
+			continue
+		}
+
+		perServing := ing.Quantity / yield
+		stockQty, err := stockQuantity(perServing, ing.Unit, ing.Item)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s (%s): %v", ing.Item.Name, ing.Item.Code, err))
+			continue
+		}
+
+		unitCost, source, subWarnings, err := itemUnitCost(db, ing.Item, visited)
+		if err != nil {
+			return 0, nil, nil, err
+		}
+		for _, warning := range subWarnings {
+			warnings = append(warnings, fmt.Sprintf("%s (%s): %s", ing.Item.Name, ing.Item.Code, warning))
+		}
+
+		lineCost := stockQty * unitCost
+		total += lineCost
+
+		lines = append(lines, response.RecipeIngredientCost{
+			ItemID:        ing.ItemID,
+			ItemCode:      ing.Item.Code,
+			ItemName:      ing.Item.Name,
+			Quantity:      perServing,
+			Unit:          ing.Unit,
+			StockQuantity: stockQty,
+			StockUnit:     ing.Item.Unit,
+			UnitCost:      unitCost,
+			TotalCost:     roundMoney(lineCost),
+			CostSource:    source,
+		})
+	}
+
+	return total, lines, warnings, nil
+}
+
+// itemUnitCost returns the cost of one stock unit of the item: today's price
+// of its preferred supplier, or of the cheapest one, and otherwise the item's
+// own cost. Items without either that are produced by a half-finished recipe
+// take the cost of one serving of that recipe.
+func itemUnitCost(db *gorm.DB, item *model.Item, visited map[string]bool) (float64, string, []string, error) {
+	suppliers, err := itemSuppliers(db, []string{item.ID.String()}, "", forecastToday())
+	if err != nil {
+		return 0, "", nil, err
+	}
+	if chosen := chooseSupplier(suppliers[item.ID.String()], SupplierStrategyPreferred); chosen != nil {
+		return *chosen.StockPrice, "supplier", nil, nil
+	}
+
+	if item.UnitCost > 0 {
+		return item.UnitCost, "item", nil, nil
+	}
+
+	subRecipe, err := findSubRecipe(db, item)
+	if err != nil || subRecipe == nil || visited[subRecipe.ID.String()] {
+		return 0, "item", nil, err
+	}
+
+	cost, _, warnings, err := recipeCostPerServing(db, subRecipe, visited)
+	if err != nil {
+		return 0, "", nil, err
+	}
+
+	return cost, "sub_recipe", warnings, nil
+}
+
+// recordRecipeCost calculates the current cost of the recipe for the branch
+// and appends it to the cost history when it differs from the last entry. An
+// incomplete cost is not recorded, so the history never shows a drop that
+// only comes from a line that could not be costed.
+func recordRecipeCost(tx *gorm.DB, recipe *model.Recipe, branchID, reason string) (*response.RecipeCostResponse, error) {
+	cost, err := calculateRecipeCost(tx, recipe, branchID)
+	if err != nil {
+		return nil, err
+	}
+	if cost.Incomplete {
+		return cost, nil
+	}
+
+	var last model.RecipeCostHistory
+	err = tx.Where("recipe_id = ? AND branch_id = ?", recipe.ID, branchID).
+		Order("calculated_at DESC").First(&last).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if err == nil && last.CostPerServing == cost.CostPerServing && last.SellingPrice == cost.SellingPrice {
+		return cost, nil
+	}
+
+	history := model.RecipeCostHistory{
+		RecipeID:       recipe.ID.String(),
+		BranchID:       branchID,
+		CostPerServing: cost.CostPerServing,
+		SellingPrice:   cost.SellingPrice,
+		FoodCostPct:    cost.FoodCostPct,
+		GrossMargin:    cost.GrossMargin,
+		Reason:         reason,
+		CalculatedAt:   time.Now(),
+	}
+
+	if err := tx.Create(&history).Error; err != nil {
+		return nil, err
+	}
+
+	return cost, nil
+}
+
+// recordRecipeCosts records the cost of the recipe for its own branch and for
+// every branch that has a selling price for it.
+func recordRecipeCosts(tx *gorm.DB, recipeID uuid.UUID, reason string) error {
+	var recipe model.Recipe
+	if err := tx.First(&recipe, "id = ?", recipeID).Error; err != nil {
+		return err
+	}
+
+	branchIDs := []string{recipe.BranchID}
+
+	var priceBranches []string
+	if err := tx.Model(&model.RecipePrice{}).
+		Where("recipe_id = ? AND branch_id <> ?", recipe.ID, recipe.BranchID).
+		Pluck("branch_id", &priceBranches).Error; err != nil {
+		return err
+	}
+	branchIDs = append(branchIDs, priceBranches...)
+
+	for _, branchID := range branchIDs {
+		if _, err := recordRecipeCost(tx, &recipe, branchID, reason); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// recalculateCostsForItem refreshes the cost history of every recipe that
+// uses the item, directly or through a half-finished recipe.
+func recalculateCostsForItem(tx *gorm.DB, item *model.Item) error {
+	visited := map[string]bool{}
+	queue := []model.Item{*item}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		var recipes []model.Recipe
+		if err := tx.Where("deleted_at IS NULL AND id IN (?)",
+			tx.Model(&model.RecipeIngredient{}).Select("recipe_id").Where("item_id = ?", current.ID),
+		).Find(&recipes).Error; err != nil {
+			return err
+		}
+
+		for i := range recipes {
+			recipe := &recipes[i]
+			if visited[recipe.ID.String()] {
+				continue
+			}
+			visited[recipe.ID.String()] = true
+
+			if err := recordRecipeCosts(tx, recipe.ID, CostReasonItemCostChange); err != nil {
+				return err
+			}
+
+			if recipe.Type != RecipeTypeHalfFinished {
+				continue
+			}
+
+			var produced []model.Item
+			if err := tx.Where("branch_id = ? AND code = ? AND unit_cost = 0", recipe.BranchID, recipe.Code).
+				Find(&produced).Error; err != nil {
+				return err
+			}
+			queue = append(queue, produced...)
+		}
+	}
+
+	return nil
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
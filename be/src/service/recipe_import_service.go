@@ -0,0 +1,435 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"app/src/model"
+	"app/src/response"
+	"app/src/utils"
+	"app/src/validation"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// recipeCSVHeader is the column layout of exported CSV files: one row per
+// ingredient, recipe columns repeated on every row.
+var recipeCSVHeader = []string{"recipe_code", "recipe_name", "type", "description", "instructions", "yield", "item_code", "quantity", "unit"}
+
+type RecipeImportService interface {
+	ExportRecipes(c *fiber.Ctx, params *validation.QueryRecipeExport) ([]response.RecipeExport, error)
+	ExportRecipesCSV(c *fiber.Ctx, params *validation.QueryRecipeExport) ([]byte, error)
+	ImportRecipes(c *fiber.Ctx, params *validation.ImportRecipes, file io.Reader) (*response.RecipeImportReport, error)
+}
+
+type recipeImportService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewRecipeImportService(db *gorm.DB, validate *validator.Validate) RecipeImportService {
+	return &recipeImportService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+func (s *recipeImportService) ExportRecipes(c *fiber.Ctx, params *validation.QueryRecipeExport) ([]response.RecipeExport, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, err
+	}
+
+	var recipes []model.Recipe
+	if err := s.DB.WithContext(c.Context()).
+		Preload("Ingredients.Item").
+		Where("branch_id = ? AND deleted_at IS NULL", params.BranchID).
+		Order("code ASC").
+		Find(&recipes).Error; err != nil {
+		return nil, err
+	}
+
+	exports := make([]response.RecipeExport, 0, len(recipes))
+	for _, recipe := range recipes {
+		export := response.RecipeExport{
+			Code:        recipe.Code,
+			Name:        recipe.Name,
+			Type:        recipe.Type,
+			Description: recipe.Description,
+			Instruction: recipe.Instruction,
+			Yield:       recipeYield(&recipe),
+			Ingredients: make([]response.RecipeExportIngredient, 0, len(recipe.Ingredients)),
+		}
+
+		for _, ing := range recipe.Ingredients {
+			if ing.Item == nil {
+				continue
+			}
+			export.Ingredients = append(export.Ingredients, response.RecipeExportIngredient{
+				ItemCode: ing.Item.Code,
+				ItemName: ing.Item.Name,
+				Quantity: ing.Quantity,
+				Unit:     ing.Unit,
+			})
+		}
+
+		exports = append(exports, export)
+	}
+
+	return exports, nil
+}
+
+func (s *recipeImportService) ExportRecipesCSV(c *fiber.Ctx, params *validation.QueryRecipeExport) ([]byte, error) {
+	exports, err := s.ExportRecipes(c, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(recipeCSVHeader); err != nil {
+		return nil, err
+	}
+
+	for _, export := range exports {
+		head := []string{
+			export.Code,
+			export.Name,
+			export.Type,
+			export.Description,
+			export.Instruction,
+			strconv.FormatFloat(export.Yield, 'f', -1, 64),
+		}
+
+		if len(export.Ingredients) == 0 {
+			if err := writer.Write(append(head, "", "", "")); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		for _, ing := range export.Ingredients {
+			row := append(append([]string{}, head...), ing.ItemCode, strconv.FormatFloat(ing.Quantity, 'f', -1, 64), ing.Unit)
+			if err := writer.Write(row); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// importedRecipe is a recipe read from an import file together with the
+// line of each part, for error reporting.
+type importedRecipe struct {
+	response.RecipeExport
+	Line            int
+	IngredientLines []int
+}
+
+// ImportRecipes validates every recipe of the file against the branch items
+// and upserts them by code. Nothing is written when the file has errors or
+// on a dry run.
+func (s *recipeImportService) ImportRecipes(c *fiber.Ctx, params *validation.ImportRecipes, file io.Reader) (*response.RecipeImportReport, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, err
+	}
+
+	var recipes []importedRecipe
+	var err error
+	switch params.Format {
+	case "json":
+		recipes, err = parseRecipeJSON(file)
+	default:
+		recipes, err = parseRecipeCSV(file)
+	}
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	db := s.DB.WithContext(c.Context())
+
+	var items []model.Item
+	if err := db.Where("branch_id = ? AND deleted_at IS NULL", params.BranchID).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	itemsByCode := make(map[string]model.Item, len(items))
+	for _, item := range items {
+		itemsByCode[item.Code] = item
+	}
+
+	var existingCodes []string
+	if err := db.Model(&model.Recipe{}).
+		Where("branch_id = ? AND deleted_at IS NULL", params.BranchID).
+		Pluck("code", &existingCodes).Error; err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool, len(existingCodes))
+	for _, code := range existingCodes {
+		existing[code] = true
+	}
+
+	report := &response.RecipeImportReport{
+		DryRun:  params.DryRun,
+		Total:   len(recipes),
+		Created: []string{},
+		Updated: []string{},
+		Errors:  validateImportedRecipes(recipes, itemsByCode),
+	}
+
+	for _, recipe := range recipes {
+		if existing[recipe.Code] {
+			report.Updated = append(report.Updated, recipe.Code)
+		} else {
+			report.Created = append(report.Created, recipe.Code)
+		}
+	}
+
+	if params.DryRun || len(report.Errors) > 0 {
+		return report, nil
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, imported := range recipes {
+			if err := upsertImportedRecipe(tx, params, imported, itemsByCode); err != nil {
+				return fmt.Errorf("line %d (%s): %w", imported.Line, imported.Code, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func validateImportedRecipes(recipes []importedRecipe, itemsByCode map[string]model.Item) []response.RecipeImportError {
+	errs := []response.RecipeImportError{}
+	seen := map[string]int{}
+
+	for _, recipe := range recipes {
+		fail := func(line int, field, message string) {
+			errs = append(errs, response.RecipeImportError{Line: line, RecipeCode: recipe.Code, Field: field, Message: message})
+		}
+
+		if recipe.Code == "" {
+			fail(recipe.Line, "recipe_code", "recipe code is required")
+		} else if line, ok := seen[recipe.Code]; ok {
+			fail(recipe.Line, "recipe_code", fmt.Sprintf("recipe code is duplicated, first defined at line %d", line))
+		} else {
+			seen[recipe.Code] = recipe.Line
+		}
+
+		if recipe.Name == "" {
+			fail(recipe.Line, "recipe_name", "recipe name is required")
+		}
+		if recipe.Type != RecipeTypeHalfFinished && recipe.Type != RecipeTypeFinished {
+			fail(recipe.Line, "type", fmt.Sprintf("type must be %s or %s", RecipeTypeHalfFinished, RecipeTypeFinished))
+		}
+		if recipe.Yield < 0 {
+			fail(recipe.Line, "yield", "yield must be greater than 0")
+		}
+		if len(recipe.Ingredients) == 0 {
+			fail(recipe.Line, "ingredients", "recipe has no ingredients")
+		}
+
+		for i, ing := range recipe.Ingredients {
+			line := recipe.IngredientLines[i]
+
+			item, ok := itemsByCode[ing.ItemCode]
+			if !ok {
+				fail(line, "item_code", fmt.Sprintf("item %q not found in branch", ing.ItemCode))
+				continue
+			}
+			if ing.Quantity <= 0 {
+				fail(line, "quantity", "quantity must be greater than 0")
+			}
+			if !utils.IsConvertibleUnit(ing.Unit, item.Unit) {
+				fail(line, "unit", fmt.Sprintf("unit %q cannot be converted to item unit %q", ing.Unit, item.Unit))
+			}
+		}
+	}
+
+	return errs
+}
+
+func upsertImportedRecipe(tx *gorm.DB, params *validation.ImportRecipes, imported importedRecipe, itemsByCode map[string]model.Item) error {
+	yield := imported.Yield
+	if yield == 0 {
+		yield = 1
+	}
+
+	var recipe model.Recipe
+	err := tx.Where("branch_id = ? AND code = ? AND deleted_at IS NULL", params.BranchID, imported.Code).First(&recipe).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	note := "Imported"
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		recipe = model.Recipe{
+			BranchID:       params.BranchID,
+			Code:           imported.Code,
+			Name:           imported.Name,
+			Type:           imported.Type,
+			Description:    imported.Description,
+			Instruction:    imported.Instruction,
+			Yield:          yield,
+			CurrentVersion: 1,
+			CreatedBy:      params.CreatedBy,
+		}
+		if err := tx.Create(&recipe).Error; err != nil {
+			return err
+		}
+		note = "Initial version (imported)"
+	} else {
+		if err := tx.Model(&recipe).Updates(map[string]interface{}{
+			"name":        imported.Name,
+			"type":        imported.Type,
+			"description": imported.Description,
+			"instruction": imported.Instruction,
+			"yield":       yield,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("recipe_id = ?", recipe.ID).Delete(&model.RecipeIngredient{}).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, ing := range imported.Ingredients {
+		ingredient := model.RecipeIngredient{
+			RecipeID: recipe.ID.String(),
+			ItemID:   itemsByCode[ing.ItemCode].ID.String(),
+			BranchID: params.BranchID,
+			Quantity: ing.Quantity,
+			Unit:     ing.Unit,
+		}
+		if err := tx.Create(&ingredient).Error; err != nil {
+			return err
+		}
+	}
+
+	if _, err := snapshotRecipeVersion(tx, recipe.ID.String(), params.CreatedBy, note); err != nil {
+		return err
+	}
+
+	return recordRecipeCosts(tx, recipe.ID, CostReasonRecipeChange)
+}
+
+// parseRecipeJSON reads the export format. Lines are the 1-based position of
+// the recipe in the file.
+func parseRecipeJSON(file io.Reader) ([]importedRecipe, error) {
+	var exports []response.RecipeExport
+	if err := json.NewDecoder(file).Decode(&exports); err != nil {
+		return nil, fmt.Errorf("failed to read JSON: %v", err)
+	}
+
+	recipes := make([]importedRecipe, 0, len(exports))
+	for i, export := range exports {
+		recipe := importedRecipe{RecipeExport: export, Line: i + 1}
+		for range export.Ingredients {
+			recipe.IngredientLines = append(recipe.IngredientLines, i+1)
+		}
+		recipes = append(recipes, recipe)
+	}
+
+	return recipes, nil
+}
+
+// parseRecipeCSV reads one ingredient per row; consecutive or scattered rows
+// with the same recipe code form one recipe. Columns are matched by header.
+func parseRecipeCSV(file io.Reader) ([]importedRecipe, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %v", err)
+	}
+	if len(records) < 2 {
+		return nil, errors.New("CSV is empty")
+	}
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["recipe_code"]; !ok {
+		return nil, errors.New("CSV header must contain recipe_code")
+	}
+
+	var recipes []importedRecipe
+	index := map[string]int{}
+
+	for idx, row := range records[1:] {
+		line := idx + 2
+		get := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		code := get("recipe_code")
+		i, ok := index[code]
+		if !ok || code == "" {
+			yield, _ := strconv.ParseFloat(get("yield"), 64)
+			if get("yield") != "" && yield <= 0 {
+				yield = -1
+			}
+			recipes = append(recipes, importedRecipe{
+				RecipeExport: response.RecipeExport{
+					Code:        code,
+					Name:        get("recipe_name"),
+					Type:        get("type"),
+					Description: get("description"),
+					Instruction: get("instructions"),
+					Yield:       yield,
+				},
+				Line: line,
+			})
+			i = len(recipes) - 1
+			if code != "" {
+				index[code] = i
+			}
+		}
+
+		itemCode := get("item_code")
+		if itemCode == "" {
+			continue
+		}
+
+		quantity, err := strconv.ParseFloat(get("quantity"), 64)
+		if err != nil {
+			quantity = 0
+		}
+
+		recipes[i].Ingredients = append(recipes[i].Ingredients, response.RecipeExportIngredient{
+			ItemCode: itemCode,
+			Quantity: quantity,
+			Unit:     get("unit"),
+		})
+		recipes[i].IngredientLines = append(recipes[i].IngredientLines, line)
+	}
+
+	return recipes, nil
+}
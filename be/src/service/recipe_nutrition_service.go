@@ -0,0 +1,123 @@
+package service
+
+import (
+	"math"
+
+	"app/src/model"
+	"app/src/utils"
+
+	"gorm.io/gorm"
+)
+
+// withDietaryInfo fills the rolled-up allergens and nutrition panel of each
+// recipe.
+func withDietaryInfo(db *gorm.DB, recipes []*model.Recipe) error {
+	for _, recipe := range recipes {
+		allergens, nutrition, err := recipeDietaryInfo(db, recipe, map[string]bool{})
+		if err != nil {
+			return err
+		}
+
+		nutrition = roundNutrition(nutrition)
+		recipe.Allergens = allergens
+		recipe.Nutrition = &nutrition
+	}
+
+	return nil
+}
+
+// recipeDietaryInfo returns the allergens of the recipe and its nutrition per
+// serving. An ingredient produced by a half-finished recipe contributes that
+// recipe's allergens and, unless the item has its own nutrition values, one
+// serving of its nutrition per stock unit.
+func recipeDietaryInfo(db *gorm.DB, recipe *model.Recipe, visited map[string]bool) ([]string, model.Nutrition, error) {
+	visited[recipe.ID.String()] = true
+	defer delete(visited, recipe.ID.String())
+
+	if recipe.Ingredients == nil {
+		if err := db.Preload("Item").Where("recipe_id = ?", recipe.ID).
+			Find(&recipe.Ingredients).Error; err != nil {
+			return nil, model.Nutrition{}, err
+		}
+	}
+
+	var allergens []string
+	var nutrition model.Nutrition
+	yield := recipeYield(recipe)
+
+	for _, ing := range recipe.Ingredients {
+		if ing.Item == nil {
+			continue
+		}
+
+		perServing := ing.Quantity / yield
+		allergens = append(allergens, utils.SplitAllergens(ing.Item.Allergens)...)
+
+		subRecipe, err := findSubRecipe(db, ing.Item)
+		if err != nil {
+			return nil, model.Nutrition{}, err
+		}
+
+		var subNutrition model.Nutrition
+		if subRecipe != nil && !visited[subRecipe.ID.String()] {
+			var subAllergens []string
+			subAllergens, subNutrition, err = recipeDietaryInfo(db, subRecipe, visited)
+			if err != nil {
+				return nil, model.Nutrition{}, err
+			}
+			allergens = append(allergens, subAllergens...)
+		}
+
+		// items hold their nutrition per base unit of their own stock unit
+		stockQty, err := stockQuantity(perServing, ing.Unit, ing.Item)
+		if err != nil {
+			continue
+		}
+		if !ing.Item.Nutrition.IsZero() || subRecipe == nil {
+			nutrition.Add(ing.Item.Nutrition, utils.ToBaseUnit(stockQty, ing.Item.Unit))
+			continue
+		}
+
+		nutrition.Add(subNutrition, stockQty)
+	}
+
+	return utils.NormalizeAllergens(allergens), nutrition, nil
+}
+
+// recipesWithAllergens selects the IDs of the branch's recipes that contain
+// any of the allergens, through their ingredients or, further down, the
+// ingredients of the half-finished recipes those are made by.
+func recipesWithAllergens(db *gorm.DB, branchID string, allergens []string) *gorm.DB {
+	return db.Raw(`
+		WITH RECURSIVE uses (recipe_id, item_id) AS (
+			SELECT recipe_ingredients.recipe_id, recipe_ingredients.item_id
+			FROM recipe_ingredients
+			JOIN recipes ON recipes.id = recipe_ingredients.recipe_id
+			WHERE recipes.branch_id = ?
+			UNION
+			SELECT uses.recipe_id, sub_ingredients.item_id
+			FROM uses
+			JOIN items ON items.id = uses.item_id
+			JOIN recipes sub ON sub.branch_id = items.branch_id AND sub.code = items.code
+				AND sub.type = ? AND sub.deleted_at IS NULL
+			JOIN recipe_ingredients sub_ingredients ON sub_ingredients.recipe_id = sub.id
+		)
+		SELECT uses.recipe_id
+		FROM uses
+		JOIN items ON items.id = uses.item_id
+		WHERE EXISTS (
+			SELECT 1 FROM unnest(string_to_array(items.allergens, ',')) AS allergen
+			WHERE LOWER(TRIM(allergen)) IN ?
+		)`, branchID, RecipeTypeHalfFinished, allergens)
+}
+
+func roundNutrition(n model.Nutrition) model.Nutrition {
+	round := func(v float64) float64 { return math.Round(v*10) / 10 }
+	return model.Nutrition{
+		Kcal:    round(n.Kcal),
+		Protein: round(n.Protein),
+		Fat:     round(n.Fat),
+		Carbs:   round(n.Carbs),
+		Sodium:  round(n.Sodium),
+	}
+}
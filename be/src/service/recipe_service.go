@@ -3,8 +3,12 @@ package service
 import (
 	"errors"
 	"fmt"
+	"math"
+	"strings"
+	"time"
 
 	"app/src/model"
+	"app/src/response"
 	"app/src/validation"
 
 	"github.com/go-playground/validator/v10"
@@ -17,6 +21,11 @@ import (
 	"app/src/utils"
 )
 
+const (
+	RecipeTypeHalfFinished = "half_finished"
+	RecipeTypeFinished     = "finished"
+)
+
 type RecipeService interface {
 	CreateRecipe(c *fiber.Ctx, req *validation.CreateRecipe) (*model.Recipe, error)
 	GetRecipes(c *fiber.Ctx, params *validation.QueryRecipe) ([]model.Recipe, int64, error)
@@ -24,6 +33,9 @@ type RecipeService interface {
 	UpdateRecipe(c *fiber.Ctx, id string, req *validation.UpdateRecipe) (*model.Recipe, error)
 	DeleteRecipe(c *fiber.Ctx, id string) error
 	CookRecipe(c *fiber.Ctx, id string, req *validation.CookRecipe) (interface{}, error)
+	CheckCookRecipe(c *fiber.Ctx, id string, req *validation.CookRecipe) (*CookCheckResponse, error)
+	GetMaxServings(c *fiber.Ctx, params *validation.QueryMaxServings) ([]MaxServingsResult, error)
+	CloneRecipes(c *fiber.Ctx, req *validation.CloneRecipes) (*response.RecipeCloneReport, error)
 }
 
 type recipeService struct {
@@ -49,14 +61,24 @@ func (r *recipeService) CreateRecipe(c *fiber.Ctx, req *validation.CreateRecipe)
 		return nil, err
 	}
 
+	yield := req.Yield
+	if yield == 0 {
+		yield = 1
+	}
+
 	recipe := model.Recipe{
-		BranchID:    req.BranchID,
-		Code:        req.Code,
-		Name:        req.Name,
-		Type:        req.Type,
-		Description: req.Description,
-		Instruction: req.Instruction,
-		CreatedBy:   req.CreatedBy,
+		BranchID:       req.BranchID,
+		Code:           req.Code,
+		Name:           req.Name,
+		Type:           req.Type,
+		Description:    req.Description,
+		Instruction:    req.Instruction,
+		Yield:          yield,
+		CurrentVersion: 1,
+		CreatedBy:      req.CreatedBy,
+	}
+	if req.SaleDepletionMode != "" {
+		recipe.SaleDepletionMode = &req.SaleDepletionMode
 	}
 
 	err := r.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
@@ -67,21 +89,8 @@ func (r *recipeService) CreateRecipe(c *fiber.Ctx, req *validation.CreateRecipe)
 
 		for _, ing := range req.Ingredients {
 
-			var item model.Item
-			if err := tx.Where("id = ? AND branch_id = ?", ing.ItemID, req.BranchID).
-				First(&item).Error; err != nil {
-				return fmt.Errorf("item %s tidak ditemukan", ing.ItemID)
-			}
-
-			if item.Stock < ing.Quantity {
-				return fmt.Errorf(
-					"stok item '%s' kurang: tersedia %.2f, dibutuhkan %.2f",
-					item.Name, item.Stock, ing.Quantity,
-				)
-			}
-
-			newQty := item.Stock - ing.Quantity
-			if err := tx.Model(&item).Update("stock", newQty).Error; err != nil {
+			// defining a recipe never touches stock, production does
+			if _, err := findIngredientItem(tx, ing.ItemID, req.BranchID); err != nil {
 				return err
 			}
 
@@ -98,7 +107,11 @@ func (r *recipeService) CreateRecipe(c *fiber.Ctx, req *validation.CreateRecipe)
 			}
 		}
 
-		return nil
+		if _, err := snapshotRecipeVersion(tx, recipe.ID.String(), req.CreatedBy, "Initial version"); err != nil {
+			return err
+		}
+
+		return recordRecipeCosts(tx, recipe.ID, CostReasonRecipeChange)
 	})
 
 	if err != nil {
@@ -111,6 +124,10 @@ func (r *recipeService) CreateRecipe(c *fiber.Ctx, req *validation.CreateRecipe)
 		return nil, err
 	}
 
+	if err := withDietaryInfo(r.DB.WithContext(c.Context()), []*model.Recipe{&recipe}); err != nil {
+		return nil, err
+	}
+
 	return &recipe, nil
 }
 
@@ -128,16 +145,29 @@ func (r *recipeService) GetRecipes(c *fiber.Ctx, params *validation.QueryRecipe)
 	var total int64
 
 	q := r.DB.Where("branch_id = ?", params.BranchID)
+	if freeFrom := utils.NormalizeAllergens(splitList(params.FreeFrom)); len(freeFrom) > 0 {
+		q = q.Where("id NOT IN (?)", recipesWithAllergens(r.DB, params.BranchID, freeFrom))
+	}
 
 	if err := q.Model(&model.Recipe{}).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	offset := (params.Page - 1) * params.Limit
-	if err := q.Limit(params.Limit).Offset(offset).Find(&recipes).Error; err != nil {
+	// ingredients are only loaded to roll up the allergens and nutrition
+	find := q.Order("code ASC").Limit(params.Limit).Offset((params.Page - 1) * params.Limit)
+	if params.WithDietary {
+		find = find.Preload("Ingredients.Item")
+	}
+	if err := find.Find(&recipes).Error; err != nil {
 		return nil, 0, err
 	}
 
+	if params.WithDietary {
+		if err := withDietaryInfo(r.DB, recipeRefs(recipes)); err != nil {
+			return nil, 0, err
+		}
+	}
+
 	return recipes, total, nil
 }
 
@@ -154,6 +184,14 @@ func (r *recipeService) GetRecipeByID(c *fiber.Ctx, id string) (*model.Recipe, e
 		return nil, err
 	}
 
+	if err := withDietaryInfo(r.DB.WithContext(c.Context()), []*model.Recipe{&recipe}); err != nil {
+		return nil, err
+	}
+
+	if err := withSubstitutes(r.DB.WithContext(c.Context()), &recipe); err != nil {
+		return nil, err
+	}
+
 	return &recipe, nil
 }
 
@@ -186,6 +224,16 @@ func (r *recipeService) UpdateRecipe(c *fiber.Ctx, id string, req *validation.Up
 		if req.Type != "" {
 			updates["type"] = req.Type
 		}
+		if req.Yield > 0 {
+			updates["yield"] = req.Yield
+		}
+		switch req.SaleDepletionMode {
+		case "":
+		case "inherit":
+			updates["sale_depletion_mode"] = nil
+		default:
+			updates["sale_depletion_mode"] = req.SaleDepletionMode
+		}
 		updates["description"] = req.Description
 		updates["instruction"] = req.Instruction
 
@@ -201,6 +249,10 @@ func (r *recipeService) UpdateRecipe(c *fiber.Ctx, id string, req *validation.Up
 			}
 
 			for _, ing := range req.Ingredients {
+				if _, err := findIngredientItem(tx, ing.ItemID, recipe.BranchID); err != nil {
+					return err
+				}
+
 				ingredient := model.RecipeIngredient{
 					RecipeID: id,
 					ItemID:   ing.ItemID,
@@ -214,7 +266,12 @@ func (r *recipeService) UpdateRecipe(c *fiber.Ctx, id string, req *validation.Up
 			}
 		}
 
-		return nil
+		// every update is kept as an immutable version
+		if _, err := snapshotRecipeVersion(tx, id, req.UpdatedBy, req.ChangeNote); err != nil {
+			return err
+		}
+
+		return recordRecipeCosts(tx, recipe.ID, CostReasonRecipeChange)
 	})
 
 	if err != nil {
@@ -225,6 +282,10 @@ func (r *recipeService) UpdateRecipe(c *fiber.Ctx, id string, req *validation.Up
 		return nil, err
 	}
 
+	if err := withDietaryInfo(r.DB.WithContext(c.Context()), []*model.Recipe{&recipe}); err != nil {
+		return nil, err
+	}
+
 	return &recipe, nil
 }
 
@@ -248,6 +309,289 @@ func (r *recipeService) DeleteRecipe(c *fiber.Ctx, id string) error {
 	return nil
 }
 
+// CloneRecipes copies recipes from one branch to another. Ingredients are
+// re-mapped to the target branch's items by code; items missing there are
+// created when requested. Ingredients that cannot be re-mapped, because their
+// item is missing or deleted or their unit does not convert to the target
+// item's, are reported as unresolved, and so are substitutes that cannot be
+// re-mapped. Recipes whose code already exists in the target branch, or with
+// no ingredient left to clone, are skipped.
+func (r *recipeService) CloneRecipes(c *fiber.Ctx, req *validation.CloneRecipes) (*response.RecipeCloneReport, error) {
+	if err := r.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	db := r.DB.WithContext(c.Context())
+
+	var branchCount int64
+	if err := db.Model(&model.Branch{}).Where("id IN ?", []string{req.SourceBranchID, req.TargetBranchID}).
+		Count(&branchCount).Error; err != nil {
+		return nil, err
+	}
+	if branchCount != 2 {
+		return nil, fiber.NewError(fiber.StatusNotFound, "branch not found")
+	}
+
+	query := db.Preload("Ingredients.Item").
+		Where("branch_id = ? AND deleted_at IS NULL", req.SourceBranchID).
+		Order("code ASC")
+	if len(req.RecipeIDs) > 0 {
+		query = query.Where("id IN ?", req.RecipeIDs)
+	}
+
+	var sources []model.Recipe
+	if err := query.Find(&sources).Error; err != nil {
+		return nil, err
+	}
+	if len(sources) == 0 {
+		return nil, fiber.NewError(fiber.StatusNotFound, "no recipes to clone")
+	}
+
+	report := &response.RecipeCloneReport{
+		SourceBranchID:        req.SourceBranchID,
+		TargetBranchID:        req.TargetBranchID,
+		Cloned:                []response.ClonedRecipe{},
+		Skipped:               []response.SkippedRecipe{},
+		CreatedItems:          []response.ClonedItem{},
+		Unresolved:            []response.UnresolvedIngredient{},
+		UnresolvedSubstitutes: []response.UnresolvedSubstitute{},
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		targetItems := map[string]*model.Item{}
+		resolve := func(source *model.Item) (*model.Item, error) {
+			item, ok := targetItems[source.Code]
+			if !ok {
+				var err error
+				item, err = r.resolveTargetItem(tx, source, req.TargetBranchID, req.CreateMissingItems, report)
+				if err != nil {
+					return nil, err
+				}
+				targetItems[source.Code] = item
+			}
+			return item, nil
+		}
+
+		for _, source := range sources {
+			var exists int64
+			if err := tx.Model(&model.Recipe{}).
+				Where("branch_id = ? AND code = ? AND deleted_at IS NULL", req.TargetBranchID, source.Code).
+				Count(&exists).Error; err != nil {
+				return err
+			}
+			if exists > 0 {
+				report.Skipped = append(report.Skipped, response.SkippedRecipe{
+					SourceRecipeID: source.ID.String(),
+					Code:           source.Code,
+					Reason:         "recipe code already exists in target branch",
+				})
+				continue
+			}
+
+			var ingredients []model.RecipeIngredient
+			mapped := map[string]string{} // source item ID to target item ID
+			for _, ing := range source.Ingredients {
+				unresolved := func(reason string) {
+					report.Unresolved = append(report.Unresolved, response.UnresolvedIngredient{
+						RecipeCode: source.Code,
+						ItemCode:   ing.Item.Code,
+						ItemName:   ing.Item.Name,
+						Quantity:   ing.Quantity,
+						Unit:       ing.Unit,
+						Reason:     reason,
+					})
+				}
+				if ing.Item == nil {
+					continue
+				}
+				if ing.Item.DeletedAt != nil {
+					unresolved("item is deleted in source branch")
+					continue
+				}
+
+				item, err := resolve(ing.Item)
+				if err != nil {
+					return err
+				}
+				if item == nil {
+					unresolved("item not found in target branch")
+					continue
+				}
+				if !utils.IsConvertibleUnit(ing.Unit, item.Unit) {
+					unresolved(fmt.Sprintf("unit %q cannot be converted to target item unit %q", ing.Unit, item.Unit))
+					continue
+				}
+
+				mapped[ing.ItemID] = item.ID.String()
+				ingredients = append(ingredients, model.RecipeIngredient{
+					ItemID:   item.ID.String(),
+					BranchID: req.TargetBranchID,
+					Quantity: ing.Quantity,
+					Unit:     ing.Unit,
+				})
+			}
+			if len(ingredients) == 0 {
+				report.Skipped = append(report.Skipped, response.SkippedRecipe{
+					SourceRecipeID: source.ID.String(),
+					Code:           source.Code,
+					Reason:         "no ingredient could be resolved in target branch",
+				})
+				continue
+			}
+
+			recipe := model.Recipe{
+				BranchID:          req.TargetBranchID,
+				Code:              source.Code,
+				Name:              source.Name,
+				Type:              source.Type,
+				Description:       source.Description,
+				Instruction:       source.Instruction,
+				Yield:             recipeYield(&source),
+				CurrentVersion:    1,
+				SaleDepletionMode: source.SaleDepletionMode,
+				CreatedBy:         req.CreatedBy,
+			}
+			if err := tx.Create(&recipe).Error; err != nil {
+				return err
+			}
+
+			for i := range ingredients {
+				ingredients[i].RecipeID = recipe.ID.String()
+				if err := tx.Create(&ingredients[i]).Error; err != nil {
+					return err
+				}
+			}
+
+			if err := r.cloneSubstitutes(tx, &source, &recipe, mapped, resolve, report); err != nil {
+				return err
+			}
+
+			note := fmt.Sprintf("Cloned from recipe %s (version %d)", source.ID, source.CurrentVersion)
+			if _, err := snapshotRecipeVersion(tx, recipe.ID.String(), req.CreatedBy, note); err != nil {
+				return err
+			}
+
+			if err := recordRecipeCosts(tx, recipe.ID, CostReasonRecipeChange); err != nil {
+				return err
+			}
+
+			report.Cloned = append(report.Cloned, response.ClonedRecipe{
+				SourceRecipeID: source.ID.String(),
+				RecipeID:       recipe.ID.String(),
+				Code:           recipe.Code,
+				Name:           recipe.Name,
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// cloneSubstitutes copies the substitutes of the source recipe whose primary
+// ingredient was cloned and whose substitute item resolves in the target
+// branch.
+func (r *recipeService) cloneSubstitutes(tx *gorm.DB, source, recipe *model.Recipe, mapped map[string]string, resolve func(*model.Item) (*model.Item, error), report *response.RecipeCloneReport) error {
+	var substitutes []model.RecipeSubstitute
+	if err := tx.Preload("SubstituteItem").Where("recipe_id = ?", source.ID).
+		Order("item_id, rank ASC").Find(&substitutes).Error; err != nil {
+		return err
+	}
+
+	for _, substitute := range substitutes {
+		if substitute.SubstituteItem == nil {
+			continue
+		}
+		unresolved := func(reason string) {
+			report.UnresolvedSubstitutes = append(report.UnresolvedSubstitutes, response.UnresolvedSubstitute{
+				RecipeCode:         source.Code,
+				SubstituteItemCode: substitute.SubstituteItem.Code,
+				SubstituteItemName: substitute.SubstituteItem.Name,
+				Reason:             reason,
+			})
+		}
+
+		itemID, ok := mapped[substitute.ItemID]
+		if !ok {
+			unresolved("its ingredient was not cloned")
+			continue
+		}
+		if substitute.SubstituteItem.DeletedAt != nil {
+			unresolved("item is deleted in source branch")
+			continue
+		}
+		item, err := resolve(substitute.SubstituteItem)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			unresolved("item not found in target branch")
+			continue
+		}
+		if item.ID.String() == itemID {
+			unresolved("item is the ingredient itself in target branch")
+			continue
+		}
+
+		clone := model.RecipeSubstitute{
+			RecipeID:         recipe.ID.String(),
+			ItemID:           itemID,
+			SubstituteItemID: item.ID.String(),
+			Rank:             substitute.Rank,
+			Ratio:            substitute.Ratio,
+		}
+		if err := tx.Create(&clone).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolveTargetItem finds the item with the same code in the target branch,
+// creating an empty-stock copy of the source item when create is set. It
+// returns nil when the item cannot be resolved.
+func (r *recipeService) resolveTargetItem(tx *gorm.DB, source *model.Item, branchID string, create bool, report *response.RecipeCloneReport) (*model.Item, error) {
+	var item model.Item
+	err := tx.Where("branch_id = ? AND code = ? AND deleted_at IS NULL", branchID, source.Code).First(&item).Error
+	if err == nil {
+		return &item, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if !create {
+		return nil, nil
+	}
+
+	item = model.Item{
+		BranchID:  branchID,
+		Code:      source.Code,
+		Name:      source.Name,
+		Type:      source.Type,
+		Unit:      source.Unit,
+		LeadTime:  source.LeadTime,
+		UnitCost:  source.UnitCost,
+		Allergens: source.Allergens,
+		Nutrition: source.Nutrition,
+	}
+	if err := tx.Create(&item).Error; err != nil {
+		return nil, err
+	}
+
+	report.CreatedItems = append(report.CreatedItems, response.ClonedItem{
+		ItemID: item.ID.String(),
+		Code:   item.Code,
+		Name:   item.Name,
+	})
+
+	return &item, nil
+}
+
 type StockChange struct {
 	ItemID      string  `json:"item_id"`
 	ItemCode    string  `json:"item_code"`
@@ -260,12 +604,16 @@ type StockChange struct {
 }
 
 type CookRecipeResponse struct {
-	RecipeID     string        `json:"recipe_id"`
-	RecipeName   string        `json:"recipe_name"`
-	ServeCount   int           `json:"serve_count"`
-	StockChanges []StockChange `json:"stock_changes"`
-	Success      bool          `json:"success"`
-	Message      string        `json:"message"`
+	CookRecordID  string        `json:"cook_record_id"`
+	RecipeID      string        `json:"recipe_id"`
+	RecipeName    string        `json:"recipe_name"`
+	RecipeVersion int           `json:"recipe_version"`
+	ServeCount    int           `json:"serve_count"`
+	StockChanges  []StockChange `json:"stock_changes"`
+	// substitutes that covered a shortage, if any
+	SubstitutesUsed []SubstituteUsage `json:"substitutes_used"`
+	Success         bool              `json:"success"`
+	Message         string            `json:"message"`
 }
 
 func (r *recipeService) CookRecipe(c *fiber.Ctx, id string, req *validation.CookRecipe) (interface{}, error) {
@@ -273,63 +621,76 @@ func (r *recipeService) CookRecipe(c *fiber.Ctx, id string, req *validation.Cook
 		return nil, err
 	}
 
-	if _, err := uuid.Parse(id); err != nil {
-		return nil, errors.New("invalid recipe ID")
-	}
-
-	var recipe model.Recipe
-	if err := r.DB.WithContext(c.Context()).Preload("Ingredients.Item").First(&recipe, "id = ? AND deleted_at IS NULL", id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("recipe not found")
-		}
+	recipe, err := r.findCookableRecipe(c, id)
+	if err != nil {
 		return nil, err
 	}
 
-	if len(recipe.Ingredients) == 0 {
-		return nil, errors.New("recipe has no ingredients")
+	var reservation *movementRef
+	if req.ReservationSourceType != "" {
+		reservation = &movementRef{Type: req.ReservationSourceType, ID: req.ReservationSourceID}
 	}
 
 	var stockChanges []StockChange
-	var insufficientItems []string
+	record := model.CookRecord{
+		BranchID:      recipe.BranchID,
+		RecipeID:      recipe.ID.String(),
+		RecipeVersion: recipe.CurrentVersion,
+		ServeCount:    req.ServeCount,
+		Status:        CookStatusCooked,
+		CookedAt:      time.Now(),
+	}
+	if req.CookedBy != "" {
+		record.CookedBy = &req.CookedBy
+	}
 
-	err := r.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
-		for _, ingredient := range recipe.Ingredients {
-			requiredQuantity := ingredient.Quantity * float64(req.ServeCount)
-
-			var item model.Item
-			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
-				Where("id = ? AND branch_id = ?", ingredient.ItemID, ingredient.BranchID).
-				First(&item).Error; err != nil {
-				if errors.Is(err, gorm.ErrRecordNotFound) {
-					return fmt.Errorf("item %s not found in branch", ingredient.ItemID)
-				}
-				return err
-			}
+	var substitutesUsed []SubstituteUsage
 
-			if item.Stock < requiredQuantity {
-				insufficientItems = append(insufficientItems, fmt.Sprintf("%s (%s): need %.2f %s, available %.2f %s (short by %.2f)",
-					item.Name, item.Code, requiredQuantity, item.Unit, item.Stock, item.Unit, requiredQuantity-item.Stock))
+	err = r.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		requirements, shortages, usages, err := planCook(tx, recipe, req, true, reservation)
+		if err != nil {
+			return err
+		}
+		substitutesUsed = usages
+
+		if len(shortages) > 0 {
+			return insufficientStockError(shortages)
+		}
+
+		if err := tx.Create(&record).Error; err != nil {
+			return err
+		}
+		ref := &movementRef{Type: CookReference, ID: record.ID.String()}
+
+		note := fmt.Sprintf("Recipe: %s (Cook %d servings)", recipe.Name, req.ServeCount)
+		for _, requirement := range requirements {
+			if requirement.Required <= 0 {
 				continue
 			}
 
+			item := requirement.Item
 			oldStock := item.Stock
-			item.Stock -= requiredQuantity
 
-			if err := tx.Save(&item).Error; err != nil {
+			transaction, err := postMovement(tx, &item, "out", requirement.Required, note, ref)
+			if err != nil {
 				return err
 			}
 
-			transactionReq := &validation.CreateItemTransaction{
-				ItemID:   item.ID,
-				BranchID: item.BranchID,
-				Type:     "out",
-				Amount:   requiredQuantity,
-				Note:     fmt.Sprintf("Recipe: %s (Cook %d servings)", recipe.Name, req.ServeCount),
-			}
-
-			transaction, err := r.ItemTransactionService.CreateTransaction(c, transactionReq)
-			if err != nil {
-				return err
+			if reservation != nil {
+				draws, err := consumeReservations(tx, *reservation, item.ID.String(), requirement.Required)
+				if err != nil {
+					return err
+				}
+				// kept so a reversal of the cook can reserve the stock again
+				for _, draw := range draws {
+					if err := tx.Create(&model.CookRecordReservation{
+						CookRecordID:  record.ID.String(),
+						ReservationID: draw.ReservationID,
+						Quantity:      draw.Quantity,
+					}).Error; err != nil {
+						return err
+					}
+				}
 			}
 
 			stockChanges = append(stockChanges, StockChange{
@@ -338,16 +699,12 @@ func (r *recipeService) CookRecipe(c *fiber.Ctx, id string, req *validation.Cook
 				ItemName:    item.Name,
 				OldStock:    oldStock,
 				NewStock:    item.Stock,
-				Consumed:    requiredQuantity,
+				Consumed:    requirement.Required,
 				Unit:        item.Unit,
 				Transaction: transaction.ID.String(),
 			})
 		}
 
-		if len(insufficientItems) > 0 {
-			return fmt.Errorf("insufficient stock for items: %v", insufficientItems)
-		}
-
 		return nil
 	})
 
@@ -356,13 +713,479 @@ func (r *recipeService) CookRecipe(c *fiber.Ctx, id string, req *validation.Cook
 	}
 
 	response := CookRecipeResponse{
-		RecipeID:     recipe.ID.String(),
-		RecipeName:   recipe.Name,
-		ServeCount:   req.ServeCount,
-		StockChanges: stockChanges,
-		Success:      true,
-		Message:      fmt.Sprintf("Successfully cooked %d servings of %s", req.ServeCount, recipe.Name),
+		CookRecordID:    record.ID.String(),
+		RecipeID:        recipe.ID.String(),
+		RecipeName:      recipe.Name,
+		RecipeVersion:   recipe.CurrentVersion,
+		ServeCount:      req.ServeCount,
+		StockChanges:    stockChanges,
+		SubstitutesUsed: substitutesUsed,
+		Success:         true,
+		Message:         fmt.Sprintf("Successfully cooked %d servings of %s", req.ServeCount, recipe.Name),
 	}
 
 	return response, nil
 }
+
+type CookCheckResponse struct {
+	RecipeID        string                  `json:"recipe_id"`
+	RecipeName      string                  `json:"recipe_name"`
+	ServeCount      int                     `json:"serve_count"`
+	CanCook         bool                    `json:"can_cook"`
+	MaxServings     int                     `json:"max_servings"`
+	Requirements    []IngredientRequirement `json:"requirements"`
+	Shortages       []IngredientShortage    `json:"shortages"`
+	SubstitutesUsed []SubstituteUsage       `json:"substitutes_used"`
+}
+
+// CheckCookRecipe is a dry run of CookRecipe: it reports the stock the cook
+// would consume and every shortage without changing anything.
+func (r *recipeService) CheckCookRecipe(c *fiber.Ctx, id string, req *validation.CookRecipe) (*CookCheckResponse, error) {
+	if err := r.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	recipe, err := r.findCookableRecipe(c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var reservation *movementRef
+	if req.ReservationSourceType != "" {
+		reservation = &movementRef{Type: req.ReservationSourceType, ID: req.ReservationSourceID}
+	}
+
+	db := r.DB.WithContext(c.Context())
+
+	requirements, shortages, usages, err := planCook(db, recipe, req, false, reservation)
+	if err != nil {
+		return nil, err
+	}
+
+	perServing, _, err := planRecipeConsumption(db, recipe, 1, false, reservation)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &CookCheckResponse{
+		RecipeID:        recipe.ID.String(),
+		RecipeName:      recipe.Name,
+		ServeCount:      req.ServeCount,
+		CanCook:         len(shortages) == 0,
+		MaxServings:     maxServings(perServing, nil),
+		Requirements:    make([]IngredientRequirement, 0, len(requirements)),
+		Shortages:       shortages,
+		SubstitutesUsed: usages,
+	}
+	if res.Shortages == nil {
+		res.Shortages = []IngredientShortage{}
+	}
+
+	for _, requirement := range requirements {
+		res.Requirements = append(res.Requirements, requirement.toResponse())
+	}
+
+	return res, nil
+}
+
+type MaxServingsResult struct {
+	RecipeID     string `json:"recipe_id"`
+	RecipeCode   string `json:"recipe_code"`
+	RecipeName   string `json:"recipe_name"`
+	MaxServings  int    `json:"max_servings"`
+	LimitingItem string `json:"limiting_item,omitempty"`
+	Problem      string `json:"problem,omitempty"` // why the ingredients could not be checked
+}
+
+// GetMaxServings computes how many servings of each recipe the branch stock
+// allows. With Competing set the recipes share one stock pool and servings are
+// allocated one at a time, round robin, until no recipe can be cooked anymore.
+// A recipe whose ingredients cannot be checked reports zero servings and why,
+// without taking anything from the pool.
+func (r *recipeService) GetMaxServings(c *fiber.Ctx, params *validation.QueryMaxServings) ([]MaxServingsResult, error) {
+	if err := r.Validate.Struct(params); err != nil {
+		return nil, err
+	}
+
+	db := r.DB.WithContext(c.Context())
+
+	query := db.Preload("Ingredients.Item").
+		Where("branch_id = ? AND deleted_at IS NULL", params.BranchID).
+		Order("code ASC")
+	if ids := splitList(params.RecipeIDs); len(ids) > 0 {
+		for _, id := range ids {
+			if _, err := uuid.Parse(id); err != nil {
+				return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("invalid recipe ID %q in recipe_ids", id))
+			}
+		}
+		query = query.Where("id IN ?", ids)
+	}
+
+	var recipes []model.Recipe
+	if err := query.Find(&recipes).Error; err != nil {
+		return nil, err
+	}
+
+	plans := make([][]ingredientRequirement, len(recipes))
+	results := make([]MaxServingsResult, len(recipes))
+	for i := range recipes {
+		perServing, _, err := planRecipeConsumption(db, &recipes[i], 1, false, nil)
+		if err != nil {
+			if !recipeProblem(err) {
+				return nil, err
+			}
+			results[i] = MaxServingsResult{
+				RecipeID:   recipes[i].ID.String(),
+				RecipeCode: recipes[i].Code,
+				RecipeName: recipes[i].Name,
+				Problem:    err.Error(),
+			}
+			continue
+		}
+
+		plans[i] = perServing
+		results[i] = MaxServingsResult{
+			RecipeID:     recipes[i].ID.String(),
+			RecipeCode:   recipes[i].Code,
+			RecipeName:   recipes[i].Name,
+			MaxServings:  maxServings(perServing, nil),
+			LimitingItem: limitingItem(perServing, nil),
+		}
+	}
+
+	if !params.Competing {
+		return results, nil
+	}
+
+	pool := map[string]float64{}
+	for _, plan := range plans {
+		for _, requirement := range plan {
+			pool[requirement.Item.ID.String()] = requirement.Available
+		}
+	}
+
+	allocated := make([]int, len(recipes))
+	for progress := true; progress; {
+		progress = false
+		for i, plan := range plans {
+			if len(plan) == 0 || maxServings(plan, pool) < 1 {
+				continue
+			}
+			for _, requirement := range plan {
+				pool[requirement.Item.ID.String()] -= requirement.Required
+			}
+			allocated[i]++
+			progress = true
+		}
+	}
+
+	for i := range results {
+		if results[i].Problem != "" {
+			continue
+		}
+		results[i].MaxServings = allocated[i]
+		results[i].LimitingItem = limitingItem(plans[i], pool)
+	}
+
+	return results, nil
+}
+
+// planCook plans the consumption of a cook request and, when substitution is
+// requested and something is short, covers the shortages with substitutes.
+func planCook(db *gorm.DB, recipe *model.Recipe, req *validation.CookRecipe, lock bool, reservation *movementRef) ([]ingredientRequirement, []IngredientShortage, []SubstituteUsage, error) {
+	requirements, shortages, err := planRecipeConsumption(db, recipe, float64(req.ServeCount), lock, reservation)
+	if err != nil || len(shortages) == 0 || req.Substitution == "" || req.Substitution == SubstitutionNone {
+		return requirements, shortages, []SubstituteUsage{}, err
+	}
+
+	var allowed map[string]map[string]bool
+	if req.Substitution == SubstitutionExplicit {
+		if allowed, err = checkExplicitSubstitutes(db, recipe.ID.String(), req.Substitutes); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	requirements, usages, err := applySubstitutes(db, recipe, requirements, allowed, lock, reservation)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if usages == nil {
+		usages = []SubstituteUsage{}
+	}
+
+	return requirements, findShortages(requirements), usages, nil
+}
+
+func (r *recipeService) findCookableRecipe(c *fiber.Ctx, id string) (*model.Recipe, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid recipe ID")
+	}
+
+	var recipe model.Recipe
+	if err := r.DB.WithContext(c.Context()).Preload("Ingredients.Item").First(&recipe, "id = ? AND deleted_at IS NULL", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "recipe not found")
+		}
+		return nil, err
+	}
+
+	if len(recipe.Ingredients) == 0 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "recipe has no ingredients")
+	}
+
+	return &recipe, nil
+}
+
+// ingredientRequirement is the stock a cook needs from one item, expressed in
+// the item's stock unit.
+type ingredientRequirement struct {
+	Item      model.Item
+	Required  float64
+	Available float64 // on hand minus what is reserved for others
+}
+
+func (i ingredientRequirement) toResponse() IngredientRequirement {
+	return IngredientRequirement{
+		ItemID:    i.Item.ID.String(),
+		ItemCode:  i.Item.Code,
+		ItemName:  i.Item.Name,
+		Required:  i.Required,
+		Available: i.Available,
+		Unit:      i.Item.Unit,
+	}
+}
+
+type IngredientRequirement struct {
+	ItemID    string  `json:"item_id"`
+	ItemCode  string  `json:"item_code"`
+	ItemName  string  `json:"item_name"`
+	Required  float64 `json:"required"`
+	Available float64 `json:"available"`
+	Unit      string  `json:"unit"`
+}
+
+type IngredientShortage struct {
+	ItemID    string  `json:"item_id"`
+	ItemCode  string  `json:"item_code"`
+	ItemName  string  `json:"item_name"`
+	Required  float64 `json:"required"`
+	Available float64 `json:"available"`
+	Shortage  float64 `json:"shortage"`
+	Unit      string  `json:"unit"`
+}
+
+func (s IngredientShortage) String() string {
+	return fmt.Sprintf("%s (%s): need %.2f %s, available %.2f %s (short by %.2f)",
+		s.ItemName, s.ItemCode, s.Required, s.Unit, s.Available, s.Unit, s.Shortage)
+}
+
+func insufficientStockError(shortages []IngredientShortage) error {
+	insufficientItems := make([]string, 0, len(shortages))
+	for _, shortage := range shortages {
+		insufficientItems = append(insufficientItems, shortage.String())
+	}
+
+	return fmt.Errorf("insufficient stock for items: %v", insufficientItems)
+}
+
+// planRecipeConsumption resolves the stock needed to cook serveCount servings
+// of the recipe, one entry per item, and lists every item that is short.
+// Items are locked for update when lock is set. Stock reserved for anyone but
+// the excluded source is not available.
+func planRecipeConsumption(db *gorm.DB, recipe *model.Recipe, serveCount float64, lock bool, exclude *movementRef) ([]ingredientRequirement, []IngredientShortage, error) {
+	var requirements []ingredientRequirement
+	index := map[string]int{}
+
+	for _, ingredient := range recipe.Ingredients {
+		var item model.Item
+		query := db
+		if lock {
+			query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+		}
+		if err := query.Where("id = ? AND branch_id = ? AND deleted_at IS NULL", ingredient.ItemID, ingredient.BranchID).
+			First(&item).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil, fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("item %s not found in branch", ingredient.ItemID))
+			}
+			return nil, nil, err
+		}
+
+		required, err := stockQuantity(ingredient.Quantity*serveCount/recipeYield(recipe), ingredient.Unit, &item)
+		if err != nil {
+			return nil, nil, fiber.NewError(fiber.StatusUnprocessableEntity, fmt.Sprintf("%s (%s): %v", item.Name, item.Code, err))
+		}
+
+		if i, ok := index[ingredient.ItemID]; ok {
+			requirements[i].Required += required
+			continue
+		}
+
+		available, err := availableQuantity(db, &item, exclude)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		index[ingredient.ItemID] = len(requirements)
+		requirements = append(requirements, ingredientRequirement{Item: item, Required: required, Available: available})
+	}
+
+	return requirements, findShortages(requirements), nil
+}
+
+// findIngredientItem returns the item a recipe line refers to. Items of other
+// branches and deleted items cannot be used.
+func findIngredientItem(db *gorm.DB, itemID, branchID string) (*model.Item, error) {
+	if _, err := uuid.Parse(itemID); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("invalid item ID %q", itemID))
+	}
+
+	var item model.Item
+	if err := db.Where("id = ? AND branch_id = ? AND deleted_at IS NULL", itemID, branchID).
+		First(&item).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("item %s not found in branch", itemID))
+		}
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+// stockQuantity expresses an ingredient quantity in the item's stock unit. A
+// unit that is not known is taken to be the item's own unit; only known units
+// of different kinds (mass and volume, say) cannot be used.
+func stockQuantity(quantity float64, unit string, item *model.Item) (float64, error) {
+	converted, err := utils.ConvertUnit(quantity, unit, item.Unit)
+	if err != nil && utils.IsKnownUnit(unit) && utils.IsKnownUnit(item.Unit) {
+		return 0, err
+	}
+	if err != nil {
+		return quantity, nil
+	}
+
+	return converted, nil
+}
+
+// recipeProblem tells whether an error of planRecipeConsumption lies in the
+// recipe itself, an ingredient that is gone or a unit that does not convert,
+// rather than in the database.
+func recipeProblem(err error) bool {
+	var e *fiber.Error
+	return errors.As(err, &e)
+}
+
+func findShortages(requirements []ingredientRequirement) []IngredientShortage {
+	var shortages []IngredientShortage
+	for _, requirement := range requirements {
+		if requirement.Available >= requirement.Required-1e-9 {
+			continue
+		}
+
+		shortages = append(shortages, IngredientShortage{
+			ItemID:    requirement.Item.ID.String(),
+			ItemCode:  requirement.Item.Code,
+			ItemName:  requirement.Item.Name,
+			Required:  requirement.Required,
+			Available: requirement.Available,
+			Shortage:  requirement.Required - requirement.Available,
+			Unit:      requirement.Item.Unit,
+		})
+	}
+
+	return shortages
+}
+
+// maxServings returns how many times the per-serving requirements fit in the
+// stock. When pool is nil the available stock of each item is used.
+func maxServings(perServing []ingredientRequirement, pool map[string]float64) int {
+	if len(perServing) == 0 {
+		return 0
+	}
+
+	limit := math.MaxInt
+	for _, requirement := range perServing {
+		if requirement.Required <= 0 {
+			continue
+		}
+
+		available := requirement.Available
+		if pool != nil {
+			available = pool[requirement.Item.ID.String()]
+		}
+
+		servings := int(math.Floor(available/requirement.Required + 1e-9))
+		if servings < limit {
+			limit = servings
+		}
+	}
+
+	if limit == math.MaxInt || limit < 0 {
+		return 0
+	}
+
+	return limit
+}
+
+func limitingItem(perServing []ingredientRequirement, pool map[string]float64) string {
+	limit := math.Inf(1)
+	name := ""
+	for _, requirement := range perServing {
+		if requirement.Required <= 0 {
+			continue
+		}
+
+		available := requirement.Available
+		if pool != nil {
+			available = pool[requirement.Item.ID.String()]
+		}
+
+		if servings := available / requirement.Required; servings < limit {
+			limit = servings
+			name = requirement.Item.Name
+		}
+	}
+
+	return name
+}
+
+func splitList(value string) []string {
+	var values []string
+	for _, v := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' }) {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
+
+// findSubRecipe returns the half-finished recipe that produces the item, i.e.
+// the recipe in the item's branch whose code equals the item code.
+func findSubRecipe(db *gorm.DB, item *model.Item) (*model.Recipe, error) {
+	var recipe model.Recipe
+	err := db.Where("branch_id = ? AND code = ? AND type = ? AND deleted_at IS NULL", item.BranchID, item.Code, RecipeTypeHalfFinished).
+		First(&recipe).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &recipe, nil
+}
+
+func recipeRefs(recipes []model.Recipe) []*model.Recipe {
+	refs := make([]*model.Recipe, len(recipes))
+	for i := range recipes {
+		refs[i] = &recipes[i]
+	}
+	return refs
+}
+
+func recipeYield(recipe *model.Recipe) float64 {
+	if recipe.Yield <= 0 {
+		return 1
+	}
+	return recipe.Yield
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	SubstitutionNone     = "none"
+	SubstitutionAuto     = "auto"
+	SubstitutionExplicit = "explicit"
+)
+
+type RecipeSubstituteService interface {
+	GetSubstitutes(c *fiber.Ctx, recipeID string) ([]model.RecipeSubstitute, error)
+	SetSubstitutes(c *fiber.Ctx, recipeID string, req *validation.SetRecipeSubstitutes) ([]model.RecipeSubstitute, error)
+}
+
+type recipeSubstituteService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewRecipeSubstituteService(db *gorm.DB, validate *validator.Validate) RecipeSubstituteService {
+	return &recipeSubstituteService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+func (s *recipeSubstituteService) GetSubstitutes(c *fiber.Ctx, recipeID string) ([]model.RecipeSubstitute, error) {
+	if _, err := uuid.Parse(recipeID); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid recipe ID")
+	}
+
+	var substitutes []model.RecipeSubstitute
+	if err := s.DB.WithContext(c.Context()).
+		Preload("SubstituteItem").
+		Where("recipe_id = ?", recipeID).
+		Order("item_id ASC, rank ASC").
+		Find(&substitutes).Error; err != nil {
+		return nil, err
+	}
+
+	return substitutes, nil
+}
+
+// SetSubstitutes replaces every substitute of the recipe. Primary items must
+// be ingredients of the recipe and substitutes must be items of its branch.
+func (s *recipeSubstituteService) SetSubstitutes(c *fiber.Ctx, recipeID string, req *validation.SetRecipeSubstitutes) ([]model.RecipeSubstitute, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	if _, err := uuid.Parse(recipeID); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid recipe ID")
+	}
+
+	err := s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		var recipe model.Recipe
+		if err := tx.Preload("Ingredients").First(&recipe, "id = ? AND deleted_at IS NULL", recipeID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fiber.NewError(fiber.StatusNotFound, "recipe not found")
+			}
+			return err
+		}
+
+		ingredients := map[string]bool{}
+		for _, ing := range recipe.Ingredients {
+			ingredients[ing.ItemID] = true
+		}
+
+		if err := tx.Where("recipe_id = ?", recipeID).Delete(&model.RecipeSubstitute{}).Error; err != nil {
+			return err
+		}
+
+		for _, input := range req.Substitutes {
+			if !ingredients[input.ItemID] {
+				return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("item %s is not an ingredient of the recipe", input.ItemID))
+			}
+
+			var count int64
+			if err := tx.Model(&model.Item{}).
+				Where("id = ? AND branch_id = ? AND deleted_at IS NULL", input.SubstituteItemID, recipe.BranchID).
+				Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("substitute item %s not found in branch", input.SubstituteItemID))
+			}
+
+			substitute := model.RecipeSubstitute{
+				RecipeID:         recipeID,
+				ItemID:           input.ItemID,
+				SubstituteItemID: input.SubstituteItemID,
+				Rank:             input.Rank,
+				Ratio:            input.Ratio,
+			}
+			if substitute.Rank == 0 {
+				substitute.Rank = 1
+			}
+			if substitute.Ratio == 0 {
+				substitute.Ratio = 1
+			}
+
+			if err := tx.Create(&substitute).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetSubstitutes(c, recipeID)
+}
+
+type SubstituteUsage struct {
+	ItemID             string  `json:"item_id"`
+	ItemCode           string  `json:"item_code"`
+	ItemName           string  `json:"item_name"`
+	ReplacedQuantity   float64 `json:"replaced_quantity"` // in the primary item's unit
+	ItemUnit           string  `json:"item_unit"`
+	SubstituteItemID   string  `json:"substitute_item_id"`
+	SubstituteItemCode string  `json:"substitute_item_code"`
+	SubstituteItemName string  `json:"substitute_item_name"`
+	SubstituteQuantity float64 `json:"substitute_quantity"` // in the substitute item's unit
+	SubstituteUnit     string  `json:"substitute_unit"`
+}
+
+// withSubstitutes attaches the substitutes of the recipe to its ingredients.
+func withSubstitutes(db *gorm.DB, recipe *model.Recipe) error {
+	var substitutes []model.RecipeSubstitute
+	if err := db.Preload("SubstituteItem").
+		Where("recipe_id = ?", recipe.ID).
+		Order("rank ASC").
+		Find(&substitutes).Error; err != nil {
+		return err
+	}
+
+	for i := range recipe.Ingredients {
+		for _, substitute := range substitutes {
+			if substitute.ItemID == recipe.Ingredients[i].ItemID {
+				recipe.Ingredients[i].Substitutes = append(recipe.Ingredients[i].Substitutes, substitute)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkExplicitSubstitutes makes sure every requested substitute is approved
+// for the recipe and returns them keyed by primary item.
+func checkExplicitSubstitutes(db *gorm.DB, recipeID string, requested []validation.CookSubstitute) (map[string]map[string]bool, error) {
+	allowed := map[string]map[string]bool{}
+	for _, pair := range requested {
+		var count int64
+		if err := db.Model(&model.RecipeSubstitute{}).
+			Where("recipe_id = ? AND item_id = ? AND substitute_item_id = ?", recipeID, pair.ItemID, pair.SubstituteItemID).
+			Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return nil, fiber.NewError(fiber.StatusBadRequest,
+				fmt.Sprintf("item %s is not an approved substitute for %s", pair.SubstituteItemID, pair.ItemID))
+		}
+
+		if allowed[pair.ItemID] == nil {
+			allowed[pair.ItemID] = map[string]bool{}
+		}
+		allowed[pair.ItemID][pair.SubstituteItemID] = true
+	}
+
+	return allowed, nil
+}
+
+// applySubstitutes covers the shortfall of every short item with its
+// substitutes, best rank first. The primary item keeps what it has available
+// and the substitutes take the rest. When allowed is not nil only the listed
+// substitutes are used.
+func applySubstitutes(db *gorm.DB, recipe *model.Recipe, requirements []ingredientRequirement, allowed map[string]map[string]bool, lock bool, exclude *movementRef) ([]ingredientRequirement, []SubstituteUsage, error) {
+	var usages []SubstituteUsage
+
+	primaries := len(requirements)
+	for i := 0; i < primaries; i++ {
+		shortfall := requirements[i].Required - math.Max(requirements[i].Available, 0)
+		if shortfall <= 1e-9 {
+			continue
+		}
+
+		primary := requirements[i].Item
+		var substitutes []model.RecipeSubstitute
+		if err := db.Where("recipe_id = ? AND item_id = ?", recipe.ID, primary.ID).
+			Order("rank ASC").
+			Find(&substitutes).Error; err != nil {
+			return nil, nil, err
+		}
+
+		for _, substitute := range substitutes {
+			if shortfall <= 1e-9 {
+				break
+			}
+			if allowed != nil && !allowed[primary.ID.String()][substitute.SubstituteItemID] {
+				continue
+			}
+
+			j := -1
+			for k := range requirements {
+				if requirements[k].Item.ID.String() == substitute.SubstituteItemID {
+					j = k
+					break
+				}
+			}
+
+			if j < 0 {
+				var item model.Item
+				query := db
+				if lock {
+					query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+				}
+				if err := query.Where("id = ? AND branch_id = ? AND deleted_at IS NULL", substitute.SubstituteItemID, recipe.BranchID).
+					First(&item).Error; err != nil {
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						continue
+					}
+					return nil, nil, err
+				}
+
+				available, err := availableQuantity(db, &item, exclude)
+				if err != nil {
+					return nil, nil, err
+				}
+
+				requirements = append(requirements, ingredientRequirement{Item: item, Available: available})
+				j = len(requirements) - 1
+			}
+
+			// quantity of the substitute, in its own unit, per unit of the
+			// primary item; units that cannot be converted take the ratio as is
+			factor := substitute.Ratio
+			if converted, err := utils.ConvertUnit(substitute.Ratio, primary.Unit, requirements[j].Item.Unit); err == nil {
+				factor = converted
+			}
+			if factor <= 0 {
+				continue
+			}
+
+			spare := requirements[j].Available - requirements[j].Required
+			if spare <= 1e-9 {
+				continue
+			}
+
+			replaced := math.Min(shortfall, spare/factor)
+			requirements[j].Required += replaced * factor
+			requirements[i].Required -= replaced
+			shortfall -= replaced
+
+			usages = append(usages, SubstituteUsage{
+				ItemID:             primary.ID.String(),
+				ItemCode:           primary.Code,
+				ItemName:           primary.Name,
+				ReplacedQuantity:   replaced,
+				ItemUnit:           primary.Unit,
+				SubstituteItemID:   requirements[j].Item.ID.String(),
+				SubstituteItemCode: requirements[j].Item.Code,
+				SubstituteItemName: requirements[j].Item.Name,
+				SubstituteQuantity: replaced * factor,
+				SubstituteUnit:     requirements[j].Item.Unit,
+			})
+		}
+	}
+
+	return requirements, usages, nil
+}
@@ -0,0 +1,419 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+
+	"app/src/model"
+	"app/src/response"
+	"app/src/utils"
+	"app/src/validation"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RecipeVersionService interface {
+	GetVersions(c *fiber.Ctx, recipeID string) ([]model.RecipeVersion, error)
+	GetVersion(c *fiber.Ctx, recipeID string, version int) (*model.RecipeVersion, error)
+	DiffVersions(c *fiber.Ctx, recipeID string, params *validation.QueryRecipeVersionDiff) (*response.RecipeVersionDiffResponse, error)
+	RestoreVersion(c *fiber.Ctx, recipeID string, version int, req *validation.RestoreRecipeVersion) (*model.Recipe, error)
+}
+
+type recipeVersionService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewRecipeVersionService(db *gorm.DB, validate *validator.Validate) RecipeVersionService {
+	return &recipeVersionService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+func (s *recipeVersionService) GetVersions(c *fiber.Ctx, recipeID string) ([]model.RecipeVersion, error) {
+	if _, err := uuid.Parse(recipeID); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid recipe ID")
+	}
+
+	// the history of a deleted recipe stays readable
+	var count int64
+	if err := s.DB.WithContext(c.Context()).Model(&model.Recipe{}).
+		Where("id = ?", recipeID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, fiber.NewError(fiber.StatusNotFound, "recipe not found")
+	}
+
+	var versions []model.RecipeVersion
+	if err := s.DB.WithContext(c.Context()).
+		Preload("Ingredients").
+		Preload("Substitutes").
+		Where("recipe_id = ?", recipeID).
+		Order("version DESC").
+		Find(&versions).Error; err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+func (s *recipeVersionService) GetVersion(c *fiber.Ctx, recipeID string, version int) (*model.RecipeVersion, error) {
+	if _, err := uuid.Parse(recipeID); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid recipe ID")
+	}
+
+	return findRecipeVersion(s.DB.WithContext(c.Context()), recipeID, version)
+}
+
+func (s *recipeVersionService) DiffVersions(c *fiber.Ctx, recipeID string, params *validation.QueryRecipeVersionDiff) (*response.RecipeVersionDiffResponse, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, err
+	}
+
+	from, err := s.GetVersion(c, recipeID, params.From)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := s.GetVersion(c, recipeID, params.To)
+	if err != nil {
+		return nil, err
+	}
+
+	return diffRecipeVersions(from, to), nil
+}
+
+func (s *recipeVersionService) RestoreVersion(c *fiber.Ctx, recipeID string, version int, req *validation.RestoreRecipeVersion) (*model.Recipe, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	target, err := s.GetVersion(c, recipeID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	var recipe model.Recipe
+	err = s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&recipe, "id = ? AND deleted_at IS NULL", recipeID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fiber.NewError(fiber.StatusNotFound, "recipe not found")
+			}
+			return err
+		}
+
+		if err := tx.Model(&recipe).Updates(map[string]interface{}{
+			"code":        target.Code,
+			"name":        target.Name,
+			"type":        target.Type,
+			"description": target.Description,
+			"instruction": target.Instruction,
+			"yield":       target.Yield,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("recipe_id = ?", recipeID).Delete(&model.RecipeIngredient{}).Error; err != nil {
+			return err
+		}
+
+		for _, ing := range target.Ingredients {
+			// a version may name an item deleted since
+			if _, err := findIngredientItem(tx, ing.ItemID, recipe.BranchID); err != nil {
+				var fiberErr *fiber.Error
+				if !errors.As(err, &fiberErr) {
+					return err
+				}
+				return fiber.NewError(fiber.StatusBadRequest,
+					fmt.Sprintf("cannot restore version %d: %s (%s) is no longer in stock", version, ing.ItemName, ing.ItemCode))
+			}
+
+			ingredient := model.RecipeIngredient{
+				RecipeID: recipeID,
+				ItemID:   ing.ItemID,
+				BranchID: recipe.BranchID,
+				Quantity: ing.Quantity,
+				Unit:     ing.Unit,
+			}
+			if err := tx.Create(&ingredient).Error; err != nil {
+				return fmt.Errorf("failed to restore ingredient %s: %w", ing.ItemCode, err)
+			}
+		}
+
+		if target.SubstitutesRecorded {
+			if err := restoreVersionSubstitutes(tx, &recipe, target); err != nil {
+				return err
+			}
+		}
+
+		note := req.ChangeNote
+		if note == "" {
+			note = fmt.Sprintf("Restored from version %d", version)
+		}
+
+		if _, err := snapshotRecipeVersion(tx, recipeID, req.RestoredBy, note); err != nil {
+			return err
+		}
+
+		return recordRecipeCosts(tx, recipe.ID, CostReasonRecipeChange)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.DB.WithContext(c.Context()).Preload("Ingredients.Item").First(&recipe, "id = ?", recipeID).Error; err != nil {
+		return nil, err
+	}
+
+	return &recipe, nil
+}
+
+func findRecipeVersion(db *gorm.DB, recipeID string, version int) (*model.RecipeVersion, error) {
+	var recipeVersion model.RecipeVersion
+	if err := db.Preload("Ingredients").Preload("Substitutes").
+		First(&recipeVersion, "recipe_id = ? AND version = ?", recipeID, version).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("recipe version %d not found", version))
+		}
+		return nil, err
+	}
+
+	return &recipeVersion, nil
+}
+
+// restoreVersionSubstitutes replaces the substitutes of the recipe with those
+// of the version. Substitutes whose item has been deleted since are left out.
+func restoreVersionSubstitutes(tx *gorm.DB, recipe *model.Recipe, target *model.RecipeVersion) error {
+	if err := tx.Where("recipe_id = ?", recipe.ID).Delete(&model.RecipeSubstitute{}).Error; err != nil {
+		return err
+	}
+
+	for _, substitute := range target.Substitutes {
+		var count int64
+		if err := tx.Model(&model.Item{}).
+			Where("id = ? AND branch_id = ? AND deleted_at IS NULL", substitute.SubstituteItemID, recipe.BranchID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			continue
+		}
+
+		restored := model.RecipeSubstitute{
+			RecipeID:         recipe.ID.String(),
+			ItemID:           substitute.ItemID,
+			SubstituteItemID: substitute.SubstituteItemID,
+			Rank:             substitute.Rank,
+			Ratio:            substitute.Ratio,
+		}
+		if err := tx.Create(&restored).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// snapshotRecipeVersion stores the current state of the recipe, its
+// ingredients and substitutes as the next version and makes it the recipe's
+// current version. The recipe row is locked so concurrent edits number their
+// versions one after the other. When nothing changed since the latest version
+// no version is written and the latest is returned.
+func snapshotRecipeVersion(tx *gorm.DB, recipeID, createdBy, note string) (*model.RecipeVersion, error) {
+	var recipe model.Recipe
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&recipe, "id = ?", recipeID).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Preload("Item").Where("recipe_id = ?", recipeID).Find(&recipe.Ingredients).Error; err != nil {
+		return nil, err
+	}
+
+	var substitutes []model.RecipeSubstitute
+	if err := tx.Preload("SubstituteItem").Where("recipe_id = ?", recipeID).
+		Order("item_id, rank ASC").Find(&substitutes).Error; err != nil {
+		return nil, err
+	}
+
+	version := model.RecipeVersion{
+		RecipeID:            recipeID,
+		Code:                recipe.Code,
+		Name:                recipe.Name,
+		Type:                recipe.Type,
+		Description:         recipe.Description,
+		Instruction:         recipe.Instruction,
+		Yield:               recipe.Yield,
+		ChangeNote:          note,
+		SubstitutesRecorded: true,
+	}
+	if createdBy != "" {
+		version.CreatedBy = &createdBy
+	}
+
+	codes := map[string]string{}
+	for _, ing := range recipe.Ingredients {
+		versionIngredient := model.RecipeVersionIngredient{
+			ItemID:   ing.ItemID,
+			Quantity: ing.Quantity,
+			Unit:     ing.Unit,
+		}
+		if ing.Item != nil {
+			versionIngredient.ItemCode = ing.Item.Code
+			versionIngredient.ItemName = ing.Item.Name
+			codes[ing.ItemID] = ing.Item.Code
+		}
+		version.Ingredients = append(version.Ingredients, versionIngredient)
+	}
+
+	for _, substitute := range substitutes {
+		versionSubstitute := model.RecipeVersionSubstitute{
+			ItemID:           substitute.ItemID,
+			ItemCode:         codes[substitute.ItemID],
+			SubstituteItemID: substitute.SubstituteItemID,
+			Rank:             substitute.Rank,
+			Ratio:            substitute.Ratio,
+		}
+		if substitute.SubstituteItem != nil {
+			versionSubstitute.SubstituteItemCode = substitute.SubstituteItem.Code
+			versionSubstitute.SubstituteItemName = substitute.SubstituteItem.Name
+		}
+		version.Substitutes = append(version.Substitutes, versionSubstitute)
+	}
+
+	var latest model.RecipeVersion
+	err := tx.Preload("Ingredients").Preload("Substitutes").
+		Where("recipe_id = ?", recipeID).Order("version DESC").First(&latest).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err == nil && sameFormulation(&latest, &version) {
+		return &latest, nil
+	}
+	version.Version = latest.Version + 1
+
+	if err := tx.Create(&version).Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Model(&model.Recipe{}).Where("id = ?", recipeID).
+		Update("current_version", version.Version).Error; err != nil {
+		return nil, err
+	}
+
+	return &version, nil
+}
+
+// sameFormulation tells whether two versions describe the same recipe,
+// ignoring line order, notes and authorship. A version without recorded
+// substitutes only matches one with none.
+func sameFormulation(a, b *model.RecipeVersion) bool {
+	if a.Code != b.Code || a.Name != b.Name || a.Type != b.Type ||
+		a.Description != b.Description || a.Instruction != b.Instruction || a.Yield != b.Yield {
+		return false
+	}
+
+	ingredients := func(version *model.RecipeVersion) []string {
+		lines := make([]string, 0, len(version.Ingredients))
+		for _, ing := range version.Ingredients {
+			lines = append(lines, fmt.Sprintf("%s|%g|%s", ing.ItemID, ing.Quantity, ing.Unit))
+		}
+		sort.Strings(lines)
+		return lines
+	}
+	substitutes := func(version *model.RecipeVersion) []string {
+		lines := make([]string, 0, len(version.Substitutes))
+		for _, substitute := range version.Substitutes {
+			lines = append(lines, fmt.Sprintf("%s|%s|%d|%g", substitute.ItemID, substitute.SubstituteItemID, substitute.Rank, substitute.Ratio))
+		}
+		sort.Strings(lines)
+		return lines
+	}
+
+	return slices.Equal(ingredients(a), ingredients(b)) && slices.Equal(substitutes(a), substitutes(b))
+}
+
+func diffRecipeVersions(from, to *model.RecipeVersion) *response.RecipeVersionDiffResponse {
+	diff := &response.RecipeVersionDiffResponse{
+		RecipeID:    from.RecipeID,
+		FromVersion: from.Version,
+		ToVersion:   to.Version,
+		Fields:      []response.RecipeFieldChange{},
+		Ingredients: []response.RecipeIngredientChange{},
+	}
+
+	fields := []struct {
+		name     string
+		from, to interface{}
+	}{
+		{"code", from.Code, to.Code},
+		{"name", from.Name, to.Name},
+		{"type", from.Type, to.Type},
+		{"description", from.Description, to.Description},
+		{"instructions", from.Instruction, to.Instruction},
+		{"yield", from.Yield, to.Yield},
+	}
+	for _, f := range fields {
+		if f.from != f.to {
+			diff.Fields = append(diff.Fields, response.RecipeFieldChange{Field: f.name, From: f.from, To: f.to})
+		}
+	}
+
+	fromIngredients := make(map[string]model.RecipeVersionIngredient, len(from.Ingredients))
+	for _, ing := range from.Ingredients {
+		fromIngredients[ing.ItemID] = ing
+	}
+
+	for _, ing := range to.Ingredients {
+		old, ok := fromIngredients[ing.ItemID]
+		delete(fromIngredients, ing.ItemID)
+
+		change := response.RecipeIngredientChange{
+			ItemID:     ing.ItemID,
+			ItemCode:   ing.ItemCode,
+			ItemName:   ing.ItemName,
+			ToQuantity: ing.Quantity,
+			ToUnit:     ing.Unit,
+		}
+
+		switch {
+		case !ok:
+			change.Change = "added"
+		case old.Quantity != ing.Quantity || old.Unit != ing.Unit:
+			change.Change = "changed"
+			change.FromQuantity = old.Quantity
+			change.FromUnit = old.Unit
+		default:
+			continue
+		}
+
+		diff.Ingredients = append(diff.Ingredients, change)
+	}
+
+	for _, ing := range fromIngredients {
+		diff.Ingredients = append(diff.Ingredients, response.RecipeIngredientChange{
+			ItemID:       ing.ItemID,
+			ItemCode:     ing.ItemCode,
+			ItemName:     ing.ItemName,
+			Change:       "removed",
+			FromQuantity: ing.Quantity,
+			FromUnit:     ing.Unit,
+		})
+	}
+
+	sort.Slice(diff.Ingredients, func(i, j int) bool {
+		return diff.Ingredients[i].ItemCode < diff.Ingredients[j].ItemCode
+	})
+
+	return diff
+}
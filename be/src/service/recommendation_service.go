@@ -0,0 +1,414 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"app/src/model"
+	"app/src/response"
+	"app/src/utils"
+	"app/src/validation"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultRecommendationHorizon = 7
+	recommendationAccuracyDays   = 28
+
+	UrgencyLow      = utils.UrgencyLow
+	UrgencyMedium   = utils.UrgencyMedium
+	UrgencyHigh     = utils.UrgencyHigh
+	UrgencyCritical = utils.UrgencyCritical
+
+	// reported for suppliers there is no data about, halfway on each scale
+	neutralQualityRating    = 3.0
+	neutralReliabilityScore = 50.0
+)
+
+type RecommendationService interface {
+	GetIngredientRecommendations(c *fiber.Ctx, req *validation.IngredientRecommendation) (*response.PurchaseRecommendation, error)
+}
+
+type recommendationService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewRecommendationService(db *gorm.DB, validate *validator.Validate) RecommendationService {
+	return &recommendationService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+// GetIngredientRecommendations tells what to buy for the recipes. It plans
+// their ingredients from the forecast (see GetRequirements) and recommends
+// every purchased item the stock, reservations and open purchase orders do
+// not cover, with the cost, how urgent it is and why.
+func (s *recommendationService) GetIngredientRecommendations(c *fiber.Ctx, req *validation.IngredientRecommendation) (*response.PurchaseRecommendation, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	db := s.DB.WithContext(c.Context())
+	today := forecastToday()
+
+	branchID, err := s.recommendationBranch(c, req.BranchID)
+	if err != nil {
+		return nil, err
+	}
+
+	horizon := req.Horizon
+	if horizon <= 0 {
+		for _, day := range req.ForecastData {
+			if day.Type == "forecast" {
+				horizon++
+			}
+		}
+	}
+	if horizon <= 0 {
+		horizon = defaultRecommendationHorizon
+	}
+	horizon = min(horizon, 90)
+
+	plan, err := planRequirements(db, branchID, horizon, req.RecipeCodes)
+	if err != nil {
+		return nil, err
+	}
+
+	var itemIDs, recipeCodes []string
+	codes := map[string]bool{}
+	for _, requirement := range plan.Items {
+		if requirement.Source != "purchase" || requirement.Net <= 0 {
+			continue
+		}
+		itemIDs = append(itemIDs, requirement.ItemID)
+		for _, code := range requirement.Recipes {
+			if !codes[code] {
+				codes[code] = true
+				recipeCodes = append(recipeCodes, code)
+			}
+		}
+	}
+
+	var items []model.Item
+	if err := db.Where("id IN ?", itemIDs).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	itemsByID := make(map[string]*model.Item, len(items))
+	for i := range items {
+		itemsByID[items[i].ID.String()] = &items[i]
+	}
+
+	suppliers, err := itemSuppliers(db, itemIDs, "", today)
+	if err != nil {
+		return nil, err
+	}
+	reliability, err := supplierReliability(db, suppliers)
+	if err != nil {
+		return nil, err
+	}
+
+	var recipes []model.Recipe
+	if err := db.Where("branch_id = ? AND code IN ?", branchID, recipeCodes).Find(&recipes).Error; err != nil {
+		return nil, err
+	}
+	recipeNames := make(map[string]string, len(recipes))
+	for _, recipe := range recipes {
+		recipeNames[recipe.Code] = recipe.Name
+	}
+
+	end := today.AddDate(0, 0, horizon-1)
+	result := &response.PurchaseRecommendation{
+		RecommendationDate: time.Now().Format(time.RFC3339),
+		ValidityPeriod:     fmt.Sprintf("Valid for today (%s); the forecast is renewed daily", today.Format("2006-01-02")),
+		Ingredients:        []response.IngredientRecommendation{},
+		Summary: response.PurchaseRecommendationSummary{
+			TotalRecipesAnalyzed: plan.RecipeCount,
+			ForecastPeriod:       fmt.Sprintf("%d days (%s to %s)", horizon, today.Format("2006-01-02"), end.Format("2006-01-02")),
+			Notes: []string{
+				"Quantities are in each ingredient's stock unit, net of available stock, reservations and open purchase orders",
+				"Half-finished items are produced in-house; their ingredients are recommended instead",
+				"Supplier prices and minimum orders are per stock unit",
+				"Delivery time is the supplier's lead time for the item, or the item's own lead time when the supplier has none; order dates and urgency use the lead time of the supplier bought from",
+				fmt.Sprintf("Reliability is the share of a supplier's received purchase orders that arrived by the expected date, %.0f%% for suppliers without received orders", neutralReliabilityScore),
+				fmt.Sprintf("Quality is not tracked yet; every supplier is rated a neutral %.1f/5.0", neutralQualityRating),
+			},
+		},
+	}
+
+	uncosted := 0
+	for _, requirement := range plan.Items {
+		item, ok := itemsByID[requirement.ItemID]
+		if !ok || len(requirement.Orders) == 0 {
+			continue
+		}
+
+		recommendation := ingredientRecommendation(requirement, item, suppliers[requirement.ItemID], reliability, recipeNames, horizon, today)
+		if recommendation.EstimatedCost == 0 {
+			uncosted++
+		}
+		result.TotalEstimatedCost += recommendation.EstimatedCost
+		if recommendation.UrgencyLevel == UrgencyHigh || recommendation.UrgencyLevel == UrgencyCritical {
+			result.UrgentItemsCount++
+		}
+		result.Ingredients = append(result.Ingredients, recommendation)
+	}
+	result.TotalEstimatedCost = roundMoney(result.TotalEstimatedCost)
+
+	sort.SliceStable(result.Ingredients, func(a, b int) bool {
+		x, y := result.Ingredients[a], result.Ingredients[b]
+		if urgencyRank(x.UrgencyLevel) != urgencyRank(y.UrgencyLevel) {
+			return urgencyRank(x.UrgencyLevel) > urgencyRank(y.UrgencyLevel)
+		}
+		if x.OrderDate != y.OrderDate {
+			return x.OrderDate < y.OrderDate
+		}
+		return x.IngredientCode < y.IngredientCode
+	})
+
+	if len(result.Ingredients) == 0 {
+		result.Summary.Notes = append(result.Summary.Notes, "Stock and open purchase orders cover the forecast; nothing needs to be bought")
+	}
+	if uncosted > 0 {
+		result.Summary.Notes = append(result.Summary.Notes,
+			fmt.Sprintf("%d ingredient(s) have no supplier price or unit cost and are left out of the estimated cost", uncosted))
+	}
+
+	confidence, err := recommendationConfidence(db, branchID, req.RecipeCodes, today)
+	if err != nil {
+		return nil, err
+	}
+	if confidence == nil {
+		result.Summary.Notes = append(result.Summary.Notes, "No past forecasts to measure the confidence against yet")
+	} else {
+		result.Summary.ConfidenceScore = *confidence
+		result.Summary.Notes = append(result.Summary.Notes,
+			fmt.Sprintf("Confidence is the share of days of the last %d the forecast was within 20%% of sales", recommendationAccuracyDays))
+	}
+
+	return result, nil
+}
+
+// recommendationBranch returns the requested branch, or the active branch of
+// the signed in user.
+func (s *recommendationService) recommendationBranch(c *fiber.Ctx, branchID string) (string, error) {
+	if branchID != "" {
+		return branchID, nil
+	}
+
+	user, ok := c.Locals("user").(*model.User)
+	if !ok || user == nil {
+		return "", fiber.NewError(fiber.StatusBadRequest, "branch_id is required")
+	}
+
+	var active model.ActiveBranch
+	if err := s.DB.WithContext(c.Context()).Where("user_id = ?", user.ID).First(&active).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", fiber.NewError(fiber.StatusBadRequest, "branch_id is required without an active branch")
+		}
+		return "", err
+	}
+
+	return active.BranchID.String(), nil
+}
+
+// ingredientRecommendation explains what the plan of a purchased item asks
+// to buy: all its planned orders together, as urgent as the first one. With
+// a supplier price the quantity is rounded up to whole purchase units and the
+// supplier's minimum order, and costed at the preferred or cheapest supplier;
+// otherwise at the item's unit cost.
+func ingredientRecommendation(requirement response.ItemRequirement, item *model.Item, suppliers []model.SupplierItem, reliability map[string]float64, recipeNames map[string]string, horizon int, today time.Time) response.IngredientRecommendation {
+	first := requirement.Orders[0]
+	recommendation := response.IngredientRecommendation{
+		IngredientCode:          requirement.ItemCode,
+		IngredientName:          requirement.ItemName,
+		RequiredQuantity:        requirement.Net,
+		Unit:                    requirement.Unit,
+		UrgencyLevel:            orderUrgency(first, today),
+		EstimatedCost:           roundMoney(requirement.Net * item.UnitCost),
+		OrderDate:               first.OrderDate,
+		NeedDate:                first.NeedDate,
+		SupplierRecommendations: make([]response.SupplierRecommendation, 0, len(suppliers)),
+		AffectedRecipes:         make([]string, 0, len(requirement.Recipes)),
+	}
+	for _, code := range requirement.Recipes {
+		recommendation.AffectedRecipes = append(recommendation.AffectedRecipes, code+" - "+recipeNames[code])
+	}
+
+	available := requirement.OnHand - requirement.Reserved
+	reasons := []string{
+		fmt.Sprintf("The forecast needs %.2f %s over the next %d days for %d recipe(s)", requirement.Gross, requirement.Unit, horizon, len(requirement.Recipes)),
+	}
+	if available > 0 {
+		reasons = append(reasons, fmt.Sprintf("%.2f %s available (%.2f on hand, %.2f reserved), which runs out on %s",
+			available, requirement.Unit, requirement.OnHand, requirement.Reserved, first.NeedDate))
+	} else {
+		reasons = append(reasons, fmt.Sprintf("Nothing available in stock (%.2f on hand, %.2f reserved)", requirement.OnHand, requirement.Reserved))
+	}
+	if requirement.OnOrder > 0 {
+		reasons = append(reasons, fmt.Sprintf("%.2f %s already on order is taken into account", requirement.OnOrder, requirement.Unit))
+	}
+	if first.Late {
+		reasons = append(reasons, fmt.Sprintf("The lead time of %d day(s) has already passed for %s; order today", requirement.LeadTime, first.NeedDate))
+	} else {
+		reasons = append(reasons, fmt.Sprintf("Order by %s to cover the lead time of %d day(s)", first.OrderDate, requirement.LeadTime))
+	}
+	if len(requirement.Orders) > 1 {
+		reasons = append(reasons, fmt.Sprintf("Needed on %d days up to %s; the quantity covers all of them", len(requirement.Orders), requirement.Orders[len(requirement.Orders)-1].NeedDate))
+	}
+
+	for _, supplier := range suppliers {
+		if supplier.StockPrice == nil {
+			continue
+		}
+		contact := supplier.Supplier.WhatsappNumber
+		if contact == "" {
+			contact = supplier.Supplier.PhoneNumber
+		}
+		leadTime := item.LeadTime
+		if supplier.LeadTime != nil {
+			leadTime = *supplier.LeadTime
+		}
+		supplierRecommendation := response.SupplierRecommendation{
+			SupplierName:     supplier.Supplier.Name,
+			Contact:          contact,
+			PricePerUnit:     *supplier.StockPrice,
+			MinimumOrder:     roundQuantity(supplier.MOQ * supplier.StockPerUnit),
+			DeliveryTime:     fmt.Sprintf("%d day(s)", leadTime),
+			QualityRating:    neutralQualityRating,
+			ReliabilityScore: neutralReliabilityScore,
+		}
+		if score, ok := reliability[supplier.SupplierID]; ok {
+			supplierRecommendation.ReliabilityScore = score
+		}
+		recommendation.SupplierRecommendations = append(recommendation.SupplierRecommendations, supplierRecommendation)
+	}
+
+	switch chosen := chooseSupplier(suppliers, SupplierStrategyPreferred); {
+	case chosen != nil:
+		units := utils.PurchaseUnits(requirement.Net, chosen.StockPerUnit, chosen.MOQ)
+		recommendation.RequiredQuantity = roundQuantity(units * chosen.StockPerUnit)
+		recommendation.EstimatedCost = roundMoney(units * *chosen.Price)
+		reasons = append(reasons, fmt.Sprintf("%s: %.0f %s of %g %s at %.2f each (price from %s)",
+			chosen.Supplier.Name, units, chosen.PurchaseUnit, chosen.PackSize, chosen.PackUnit, *chosen.Price, *chosen.EffectiveFrom))
+		if chosen.Preferred {
+			reasons = append(reasons, "Bought from the preferred supplier")
+		} else {
+			reasons = append(reasons, "Bought from the cheapest supplier per "+requirement.Unit)
+		}
+		if chosen.MOQ > 0 && units == chosen.MOQ && units*chosen.StockPerUnit > requirement.Net {
+			reasons = append(reasons, fmt.Sprintf("Raised to the minimum order of %g %s", chosen.MOQ, chosen.PurchaseUnit))
+		}
+	case item.UnitCost > 0:
+		reasons = append(reasons, fmt.Sprintf("No supplier price; cost estimated at the item's unit cost of %.2f per %s", item.UnitCost, requirement.Unit))
+	default:
+		reasons = append(reasons, "No supplier price and no unit cost; the cost could not be estimated")
+	}
+	recommendation.Reasons = reasons
+
+	return recommendation
+}
+
+// supplierReliability returns, per supplier with received purchase orders,
+// the percentage of them that were received by their expected date.
+func supplierReliability(db *gorm.DB, suppliers map[string][]model.SupplierItem) (map[string]float64, error) {
+	var supplierIDs []string
+	for _, list := range suppliers {
+		for _, supplier := range list {
+			supplierIDs = append(supplierIDs, supplier.SupplierID)
+		}
+	}
+	reliability := map[string]float64{}
+	if len(supplierIDs) == 0 {
+		return reliability, nil
+	}
+
+	var rows []struct {
+		SupplierID string
+		Received   int
+		OnTime     int
+	}
+	if err := db.Model(&model.PurchaseOrder{}).
+		Select("supplier_id, COUNT(*) AS received, COUNT(*) FILTER (WHERE received_at::date <= expected_date) AS on_time").
+		Where("supplier_id IN ? AND status = ? AND received_at IS NOT NULL", supplierIDs, PurchaseStatusReceived).
+		Group("supplier_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		reliability[row.SupplierID] = math.Round(float64(row.OnTime)/float64(row.Received)*10000) / 100
+	}
+
+	return reliability, nil
+}
+
+// orderUrgency rates the planned order, see utils.OrderUrgency.
+func orderUrgency(order response.PlannedOrder, today time.Time) string {
+	orderDate, err := time.ParseInLocation("2006-01-02", order.OrderDate, today.Location())
+	if err != nil {
+		return utils.OrderUrgency(order.Late, 0)
+	}
+	return utils.OrderUrgency(order.Late, int(math.Round(orderDate.Sub(today).Hours()/24)))
+}
+
+func urgencyRank(level string) int {
+	switch level {
+	case UrgencyCritical:
+		return 3
+	case UrgencyHigh:
+		return 2
+	case UrgencyMedium:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// recommendationConfidence is the hit rate of the forecasts of the recipes
+// over the last days, or nil when none of them can be measured yet.
+func recommendationConfidence(db *gorm.DB, branchID string, recipeCodes []string, today time.Time) (*float64, error) {
+	start := today.AddDate(0, 0, -recommendationAccuracyDays)
+	points, err := workedForecastPoints(db, branchID, start, today.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(recipeCodes) > 0 {
+		var ids []string
+		if err := db.Model(&model.Recipe{}).Where("branch_id = ? AND code IN ?", branchID, recipeCodes).
+			Pluck("id", &ids).Error; err != nil {
+			return nil, err
+		}
+		selected := make(map[string]bool, len(ids))
+		for _, id := range ids {
+			selected[id] = true
+		}
+		kept := points[:0]
+		for _, point := range points {
+			if selected[point.RecipeID] {
+				kept = append(kept, point)
+			}
+		}
+		points = kept
+	}
+
+	report, err := forecastAccuracy(db, branchID, points, start, today.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+	if report.Overall.Points == 0 {
+		return nil, nil
+	}
+
+	return &report.Overall.HitRate, nil
+}
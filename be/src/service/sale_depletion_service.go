@@ -0,0 +1,166 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"app/src/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	SaleDepletionAtSale    = "deplete_at_sale"
+	SaleDepletionPreCooked = "pre_cooked"
+
+	SaleReference = "sale"
+)
+
+// saleDepletionMode resolves how sales of the recipe affect stock: the
+// recipe's own setting, or its branch's when the recipe has none.
+func saleDepletionMode(db *gorm.DB, recipe *model.Recipe) (string, error) {
+	if recipe.SaleDepletionMode != nil && *recipe.SaleDepletionMode != "" {
+		return *recipe.SaleDepletionMode, nil
+	}
+
+	var branch model.Branch
+	if err := db.Select("sale_depletion_mode").First(&branch, "id = ?", recipe.BranchID).Error; err != nil {
+		return "", err
+	}
+	if branch.SaleDepletionMode == "" {
+		return SaleDepletionPreCooked, nil
+	}
+
+	return branch.SaleDepletionMode, nil
+}
+
+// depleteSale deducts the ingredients of the sold servings through the
+// recipe's bill of materials, as if the dish were cooked at the point of sale.
+// The sale already happened, so nothing in the recipe blocks it: stock may go
+// negative and shows up in the stock count variance instead, and lines whose
+// item is gone or whose unit does not convert are skipped and listed in the
+// sale's depletion note. Nothing is deducted for recipes sold from pre-cooked
+// stock.
+func depleteSale(tx *gorm.DB, sale *model.Sale, recipe *model.Recipe) error {
+	mode, err := saleDepletionMode(tx, recipe)
+	if err != nil {
+		return err
+	}
+	if mode != SaleDepletionAtSale {
+		return nil
+	}
+
+	var ingredients []model.RecipeIngredient
+	if err := tx.Where("recipe_id = ?", recipe.ID).Find(&ingredients).Error; err != nil {
+		return err
+	}
+
+	// items are locked because postMovement writes the stock it read
+	var order []string
+	items := map[string]*model.Item{}
+	required := map[string]float64{}
+	var skipped []string
+	for _, ingredient := range ingredients {
+		item, ok := items[ingredient.ItemID]
+		if !ok {
+			item = &model.Item{}
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ? AND branch_id = ? AND deleted_at IS NULL", ingredient.ItemID, ingredient.BranchID).
+				First(item).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				skipped = append(skipped, fmt.Sprintf("item %s not found in branch", ingredient.ItemID))
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		quantity, err := stockQuantity(ingredient.Quantity*float64(sale.Quantity)/recipeYield(recipe), ingredient.Unit, item)
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s (%s): %v", item.Name, item.Code, err))
+			continue
+		}
+
+		if !ok {
+			items[ingredient.ItemID] = item
+			order = append(order, ingredient.ItemID)
+		}
+		required[ingredient.ItemID] += quantity
+	}
+
+	ref := &movementRef{Type: SaleReference, ID: sale.ID.String()}
+	note := fmt.Sprintf("Sale: %s (%d servings on %s)", recipe.Name, sale.Quantity, sale.Date.Format("2006-01-02"))
+	for _, id := range order {
+		if required[id] <= 0 {
+			continue
+		}
+
+		if _, err := postMovement(tx, items[id], "out", required[id], note, ref); err != nil {
+			return err
+		}
+	}
+
+	sale.Depleted = true
+	sale.DepletionNote = strings.Join(skipped, "; ")
+	return tx.Model(sale).Updates(map[string]interface{}{
+		"depleted":       true,
+		"depletion_note": sale.DepletionNote,
+	}).Error
+}
+
+// restoreSale puts back what the sale still holds deducted. Movements of the
+// sale are netted per item, so a sale edited several times is restored once.
+func restoreSale(tx *gorm.DB, sale *model.Sale, reason string) error {
+	if !sale.Depleted {
+		return nil
+	}
+
+	var movements []model.ItemTransaction
+	if err := tx.Where("reference_type = ? AND reference_id = ?", SaleReference, sale.ID).
+		Order("transaction_date ASC").
+		Find(&movements).Error; err != nil {
+		return err
+	}
+
+	var order []string
+	net := map[string]float64{}
+	for _, movement := range movements {
+		id := movement.ItemID.String()
+		if _, ok := net[id]; !ok {
+			order = append(order, id)
+		}
+
+		if isInboundMovement(movement.Type) {
+			net[id] -= movement.Amount
+		} else {
+			net[id] += movement.Amount
+		}
+	}
+
+	ref := &movementRef{Type: SaleReference, ID: sale.ID.String()}
+	note := fmt.Sprintf("Reversal of sale %s: %s", sale.ID, reason)
+	for _, id := range order {
+		if net[id] <= 1e-9 {
+			continue
+		}
+
+		var item model.Item
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&item, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		if _, err := postMovement(tx, &item, "in", net[id], note, ref); err != nil {
+			return err
+		}
+	}
+
+	sale.Depleted = false
+	sale.DepletionNote = ""
+	return tx.Model(sale).Updates(map[string]interface{}{
+		"depleted":       false,
+		"depletion_note": "",
+	}).Error
+}
@@ -0,0 +1,557 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"app/src/model"
+	"app/src/response"
+	"app/src/utils"
+	"app/src/validation"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	SaleImportSourceQuinos  = "quinos"
+	SaleImportSourceIseller = "iseller"
+	SaleImportSourceCSV     = "csv"
+)
+
+type SaleImportService interface {
+	ImportQuinos(c *fiber.Ctx, params *validation.ImportQuinosSales, fileName string, file io.Reader) (*response.SaleImportReport, error)
+	ImportIseller(c *fiber.Ctx, params *validation.ImportIsellerSales, fileName string, file io.Reader) (*response.SaleImportReport, error)
+	ImportCSV(c *fiber.Ctx, params *validation.ImportCSVSales, fileName string, file io.Reader) (*response.SaleImportReport, error)
+	GetTemplates(c *fiber.Ctx, params *validation.QuerySalesImportTemplate) ([]model.SalesImportTemplate, error)
+	CreateTemplate(c *fiber.Ctx, req *validation.SalesImportTemplate) (*model.SalesImportTemplate, error)
+	UpdateTemplate(c *fiber.Ctx, id string, req *validation.SalesImportTemplate) (*model.SalesImportTemplate, error)
+	DeleteTemplate(c *fiber.Ctx, id string, params *validation.QuerySalesImportTemplate) error
+}
+
+type saleImportService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewSaleImportService(db *gorm.DB, validate *validator.Validate) SaleImportService {
+	return &saleImportService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+// ImportQuinos imports a Quinos item sales export as the sales of one day.
+// Unmapped product codes fail the import unless it is forced, in which case
+// only the mapped lines are imported. A day that already has sales imported
+// from Quinos is refused; void those sales first to import the day again.
+func (s *saleImportService) ImportQuinos(c *fiber.Ctx, params *validation.ImportQuinosSales, fileName string, file io.Reader) (*response.SaleImportReport, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, err
+	}
+
+	lines, err := utils.ParseQuinosSales(file)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if len(lines) == 0 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "the Quinos export has no product lines")
+	}
+
+	date, _ := time.Parse("2006-01-02", params.Date)
+	for i := range lines {
+		lines[i].Date = date
+	}
+
+	var report *response.SaleImportReport
+	err = s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := checkImportBranch(tx, params.BranchID); err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&model.Sale{}).
+			Joins("JOIN sales_imports ON sales_imports.id = sales.import_id").
+			Where("sales_imports.branch_id = ? AND sales_imports.source = ? AND sales_imports.date = ? AND sales.status = ?",
+				params.BranchID, SaleImportSourceQuinos, params.Date, SaleStatusActive).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return fiber.NewError(fiber.StatusConflict, fmt.Sprintf("sales from Quinos for %s were already imported", params.Date))
+		}
+
+		batch := model.SalesImport{
+			BranchID: params.BranchID,
+			Source:   SaleImportSourceQuinos,
+			Date:     &date,
+			FileName: fileName,
+			Forced:   params.IsForced,
+		}
+
+		report, err = importSaleLines(tx, &batch, lines)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// ImportIseller imports an iSeller sales export, which may span several days.
+// Every line carries a reference derived from its content, so uploading a
+// file again, or a file overlapping an earlier one, only adds the lines not
+// imported yet.
+func (s *saleImportService) ImportIseller(c *fiber.Ctx, params *validation.ImportIsellerSales, fileName string, file io.Reader) (*response.SaleImportReport, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, err
+	}
+
+	lines, err := utils.ParseIsellerSales(file)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if len(lines) == 0 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "the iSeller export has no product lines")
+	}
+
+	var report *response.SaleImportReport
+	err = s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := checkImportBranch(tx, params.BranchID); err != nil {
+			return err
+		}
+
+		batch := model.SalesImport{
+			BranchID: params.BranchID,
+			Source:   SaleImportSourceIseller,
+			FileName: fileName,
+			Forced:   params.IsForced,
+		}
+
+		report, err = importSaleLines(tx, &batch, lines)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// ImportCSV imports a sales CSV through a column mapping: a saved template,
+// columns named in the request, or the columns of the sample file. Rows are
+// validated first and any invalid row fails the whole import, which then
+// commits in a single transaction.
+func (s *saleImportService) ImportCSV(c *fiber.Ctx, params *validation.ImportCSVSales, fileName string, file io.Reader) (*response.SaleImportReport, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, err
+	}
+
+	mapping := utils.SalesColumnMapping{
+		DateColumn:        params.DateColumn,
+		ProductCodeColumn: params.ProductCodeColumn,
+		ProductNameColumn: params.ProductNameColumn,
+		QuantityColumn:    params.QuantityColumn,
+		PriceColumn:       params.PriceColumn,
+		DateFormat:        params.DateFormat,
+	}
+
+	if params.TemplateID != "" {
+		var template model.SalesImportTemplate
+		if err := s.DB.WithContext(c.Context()).
+			First(&template, "id = ? AND branch_id = ?", params.TemplateID, params.BranchID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fiber.NewError(fiber.StatusNotFound, "import template not found in branch")
+			}
+			return nil, err
+		}
+		mapping = templateMapping(&template)
+	} else if mapping.DateColumn == "" && mapping.ProductCodeColumn == "" && mapping.QuantityColumn == "" {
+		mapping = utils.SalesColumnMapping{
+			DateColumn:        "date",
+			ProductCodeColumn: "product_code",
+			ProductNameColumn: "product_name",
+			QuantityColumn:    "quantity",
+			PriceColumn:       "price",
+			DateFormat:        params.DateFormat,
+		}
+	} else if mapping.DateColumn == "" || mapping.ProductCodeColumn == "" || mapping.QuantityColumn == "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "date_column, product_code_column and quantity_column are required")
+	}
+
+	lines, rowErrors, err := utils.ParseMappedSales(file, mapping)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if len(rowErrors) > 0 {
+		report := &response.SaleImportReport{Source: SaleImportSourceCSV, Forced: params.IsForced}
+		for _, rowError := range rowErrors {
+			report.Errors = append(report.Errors, response.SaleImportError{
+				Line:    rowError.Line,
+				Field:   rowError.Field,
+				Message: rowError.Message,
+			})
+		}
+		return report, nil
+	}
+	if len(lines) == 0 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "the file has no sales lines")
+	}
+
+	var report *response.SaleImportReport
+	err = s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := checkImportBranch(tx, params.BranchID); err != nil {
+			return err
+		}
+
+		if params.SaveTemplateAs != "" && params.TemplateID == "" {
+			template := model.SalesImportTemplate{BranchID: params.BranchID, Name: params.SaveTemplateAs}
+			setTemplateMapping(&template, mapping)
+			if err := createTemplate(tx, &template); err != nil {
+				return err
+			}
+		}
+
+		batch := model.SalesImport{
+			BranchID: params.BranchID,
+			Source:   SaleImportSourceCSV,
+			FileName: fileName,
+			Forced:   params.IsForced,
+		}
+
+		report, err = importSaleLines(tx, &batch, lines)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (s *saleImportService) GetTemplates(c *fiber.Ctx, params *validation.QuerySalesImportTemplate) ([]model.SalesImportTemplate, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, err
+	}
+
+	var templates []model.SalesImportTemplate
+	if err := s.DB.WithContext(c.Context()).
+		Where("branch_id = ?", params.BranchID).
+		Order("name ASC").
+		Find(&templates).Error; err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+func (s *saleImportService) CreateTemplate(c *fiber.Ctx, req *validation.SalesImportTemplate) (*model.SalesImportTemplate, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	template := model.SalesImportTemplate{BranchID: req.BranchID, Name: req.Name}
+	setTemplateMapping(&template, requestMapping(req))
+	if err := createTemplate(s.DB.WithContext(c.Context()), &template); err != nil {
+		return nil, err
+	}
+
+	return &template, nil
+}
+
+func (s *saleImportService) UpdateTemplate(c *fiber.Ctx, id string, req *validation.SalesImportTemplate) (*model.SalesImportTemplate, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid template ID")
+	}
+
+	var template model.SalesImportTemplate
+	if err := s.DB.WithContext(c.Context()).First(&template, "id = ? AND branch_id = ?", id, req.BranchID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "import template not found in branch")
+		}
+		return nil, err
+	}
+
+	if req.Name != template.Name {
+		var count int64
+		if err := s.DB.WithContext(c.Context()).Model(&model.SalesImportTemplate{}).
+			Where("branch_id = ? AND name = ? AND id <> ?", req.BranchID, req.Name, id).
+			Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			return nil, fiber.NewError(fiber.StatusConflict, fmt.Sprintf("import template %q already exists", req.Name))
+		}
+	}
+
+	template.Name = req.Name
+	setTemplateMapping(&template, requestMapping(req))
+	if template.DateFormat != "" {
+		if _, err := utils.DateLayout(template.DateFormat); err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	}
+
+	if err := s.DB.WithContext(c.Context()).Save(&template).Error; err != nil {
+		return nil, err
+	}
+
+	return &template, nil
+}
+
+func (s *saleImportService) DeleteTemplate(c *fiber.Ctx, id string, params *validation.QuerySalesImportTemplate) error {
+	if err := s.Validate.Struct(params); err != nil {
+		return err
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid template ID")
+	}
+
+	result := s.DB.WithContext(c.Context()).
+		Delete(&model.SalesImportTemplate{}, "id = ? AND branch_id = ?", id, params.BranchID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fiber.NewError(fiber.StatusNotFound, "import template not found in branch")
+	}
+
+	return nil
+}
+
+func createTemplate(db *gorm.DB, template *model.SalesImportTemplate) error {
+	if template.DateFormat != "" {
+		if _, err := utils.DateLayout(template.DateFormat); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	}
+
+	var count int64
+	if err := db.Model(&model.SalesImportTemplate{}).
+		Where("branch_id = ? AND name = ?", template.BranchID, template.Name).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return fiber.NewError(fiber.StatusConflict, fmt.Sprintf("import template %q already exists", template.Name))
+	}
+
+	return db.Create(template).Error
+}
+
+func requestMapping(req *validation.SalesImportTemplate) utils.SalesColumnMapping {
+	return utils.SalesColumnMapping{
+		DateColumn:        req.DateColumn,
+		ProductCodeColumn: req.ProductCodeColumn,
+		ProductNameColumn: req.ProductNameColumn,
+		QuantityColumn:    req.QuantityColumn,
+		PriceColumn:       req.PriceColumn,
+		DateFormat:        req.DateFormat,
+	}
+}
+
+func templateMapping(template *model.SalesImportTemplate) utils.SalesColumnMapping {
+	return utils.SalesColumnMapping{
+		DateColumn:        template.DateColumn,
+		ProductCodeColumn: template.ProductCodeColumn,
+		ProductNameColumn: template.ProductNameColumn,
+		QuantityColumn:    template.QuantityColumn,
+		PriceColumn:       template.PriceColumn,
+		DateFormat:        template.DateFormat,
+	}
+}
+
+func setTemplateMapping(template *model.SalesImportTemplate, mapping utils.SalesColumnMapping) {
+	template.DateColumn = mapping.DateColumn
+	template.ProductCodeColumn = mapping.ProductCodeColumn
+	template.ProductNameColumn = mapping.ProductNameColumn
+	template.QuantityColumn = mapping.QuantityColumn
+	template.PriceColumn = mapping.PriceColumn
+	template.DateFormat = mapping.DateFormat
+}
+
+func checkImportBranch(tx *gorm.DB, branchID string) error {
+	var branch model.Branch
+	if err := tx.First(&branch, "id = ? AND deleted_at IS NULL", branchID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Branch not found")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// importSaleLines records the batch and a sale for every line whose product
+// maps to a recipe of the branch; a variant is looked up under its variant
+// code before its product code. Lines imported before (same external
+// reference) are skipped, as are lines with nothing sold (zero, or refunds
+// printed as negative quantities) and optional lines that do not map. When
+// other products are not mapped and the batch is not forced nothing is
+// imported.
+func importSaleLines(tx *gorm.DB, batch *model.SalesImport, lines []utils.PosSaleLine) (*response.SaleImportReport, error) {
+	imported, err := importedRefs(tx, batch.BranchID, lines)
+	if err != nil {
+		return nil, err
+	}
+
+	recipes, err := resolvePosProducts(tx, batch.BranchID, utils.ProductCodes(lines))
+	if err != nil {
+		return nil, err
+	}
+
+	report := &response.SaleImportReport{
+		Source:               batch.Source,
+		Dates:                []string{},
+		LinesRead:            len(lines),
+		Forced:               batch.Forced,
+		NotFoundProductCodes: []string{},
+		IgnoredModifiers:     []string{},
+	}
+
+	type saleLine struct {
+		line   utils.PosSaleLine
+		recipe *model.Recipe
+	}
+	var pending []saleLine
+	unmapped, ignored := map[string]bool{}, map[string]bool{}
+	for _, line := range lines {
+		if line.ExternalRef != "" && imported[line.ExternalRef] {
+			report.Duplicates++
+			continue
+		}
+
+		recipe, ok := recipes[line.VariantCode()]
+		if !ok {
+			recipe, ok = recipes[line.ProductCode]
+		}
+		if !ok {
+			report.SkippedLines++
+			if line.Optional {
+				ignored[line.ProductCode] = true
+			} else {
+				unmapped[line.ProductCode] = true
+			}
+			continue
+		}
+
+		if math.Round(line.Quantity) <= 0 {
+			report.SkippedLines++
+			continue
+		}
+
+		pending = append(pending, saleLine{line: line, recipe: recipe})
+	}
+
+	report.NotFoundProductCodes = sortedKeys(unmapped)
+	report.IgnoredModifiers = sortedKeys(ignored)
+	if len(unmapped) > 0 && !batch.Forced {
+		return nil, &UnmappedProductsError{Codes: report.NotFoundProductCodes}
+	}
+
+	batch.LinesRead = len(lines)
+	batch.SkippedCodes = strings.Join(report.NotFoundProductCodes, ",")
+	if err := tx.Create(batch).Error; err != nil {
+		return nil, err
+	}
+	importID := batch.ID.String()
+	report.ImportID = importID
+
+	dates := map[string]bool{}
+	for _, next := range pending {
+		line := next.line
+
+		sale := model.Sale{
+			BranchID: batch.BranchID,
+			RecipeID: next.recipe.ID.String(),
+			Quantity: int(math.Round(line.Quantity)),
+			Date:     line.Date,
+			Note:     fmt.Sprintf("Imported from %s: %s %s", batch.Source, line.VariantCode(), line.ProductName),
+			Status:   SaleStatusActive,
+			ImportID: &importID,
+		}
+		if line.ExternalRef != "" {
+			ref := line.ExternalRef
+			sale.ExternalRef = &ref
+		}
+		if line.Price != 0 {
+			amount := line.Price
+			sale.Amount = &amount
+		}
+		if err := tx.Create(&sale).Error; err != nil {
+			return nil, err
+		}
+		if err := depleteSale(tx, &sale, next.recipe); err != nil {
+			return nil, err
+		}
+
+		report.Imported++
+		if day := line.Date.Format("2006-01-02"); !dates[day] {
+			dates[day] = true
+			report.Dates = append(report.Dates, day)
+		}
+	}
+	sort.Strings(report.Dates)
+
+	if err := tx.Model(batch).Update("imported_count", report.Imported).Error; err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// importedRefs returns the external references of the lines that the branch
+// already has active sales for; a voided line can be imported again.
+func importedRefs(tx *gorm.DB, branchID string, lines []utils.PosSaleLine) (map[string]bool, error) {
+	var refs []string
+	for _, line := range lines {
+		if line.ExternalRef != "" {
+			refs = append(refs, line.ExternalRef)
+		}
+	}
+
+	imported := map[string]bool{}
+	for start := 0; start < len(refs); start += 1000 {
+		end := start + 1000
+		if end > len(refs) {
+			end = len(refs)
+		}
+
+		var found []string
+		if err := tx.Model(&model.Sale{}).
+			Where("branch_id = ? AND status = ? AND external_ref IN ?", branchID, SaleStatusActive, refs[start:end]).
+			Pluck("external_ref", &found).Error; err != nil {
+			return nil, err
+		}
+		for _, ref := range found {
+			imported[ref] = true
+		}
+	}
+
+	return imported, nil
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
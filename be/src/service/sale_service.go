@@ -0,0 +1,354 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	SaleStatusActive = "active"
+	SaleStatusVoid   = "void"
+)
+
+type SaleService interface {
+	GetSales(c *fiber.Ctx, params *validation.QuerySale) ([]model.Sale, int64, error)
+	GetSaleByID(c *fiber.Ctx, id string) (*model.Sale, error)
+	GetSalesByDateRange(c *fiber.Ctx, params *validation.QuerySale) ([]model.Sale, error)
+	GetSalesByRecipe(c *fiber.Ctx, recipeID string, params *validation.QuerySale) ([]model.Sale, error)
+	CreateSales(c *fiber.Ctx, req *validation.CreateSales) ([]model.Sale, error)
+	UpdateSale(c *fiber.Ctx, id string, req *validation.UpdateSale) (*model.Sale, error)
+	VoidSale(c *fiber.Ctx, id string, req *validation.VoidSale) (*model.Sale, error)
+}
+
+type saleService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewSaleService(db *gorm.DB, validate *validator.Validate) SaleService {
+	return &saleService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+func (s *saleService) GetSales(c *fiber.Ctx, params *validation.QuerySale) ([]model.Sale, int64, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, 0, err
+	}
+
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 {
+		params.Limit = 10
+	}
+
+	query := s.salesQuery(c, params)
+
+	var total int64
+	if err := query.Model(&model.Sale{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var sales []model.Sale
+	if err := query.Preload("Recipe").
+		Order("date DESC, created_at DESC").
+		Offset((params.Page - 1) * params.Limit).
+		Limit(params.Limit).
+		Find(&sales).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return sales, total, nil
+}
+
+func (s *saleService) GetSaleByID(c *fiber.Ctx, id string) (*model.Sale, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid sale ID")
+	}
+
+	var sale model.Sale
+	if err := s.DB.WithContext(c.Context()).Preload("Recipe").First(&sale, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "Sale not found")
+		}
+		return nil, err
+	}
+
+	if err := s.DB.WithContext(c.Context()).
+		Where("reference_type = ? AND reference_id = ?", SaleReference, sale.ID).
+		Order("transaction_date ASC").
+		Find(&sale.Movements).Error; err != nil {
+		return nil, err
+	}
+
+	return &sale, nil
+}
+
+func (s *saleService) GetSalesByDateRange(c *fiber.Ctx, params *validation.QuerySale) ([]model.Sale, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, err
+	}
+
+	if params.StartDate == "" || params.EndDate == "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "start_date and end_date are required")
+	}
+	if params.StartDate > params.EndDate {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "start_date must not be after end_date")
+	}
+
+	var sales []model.Sale
+	if err := s.salesQuery(c, params).Preload("Recipe").
+		Order("date ASC, created_at ASC").
+		Find(&sales).Error; err != nil {
+		return nil, err
+	}
+
+	return sales, nil
+}
+
+func (s *saleService) GetSalesByRecipe(c *fiber.Ctx, recipeID string, params *validation.QuerySale) ([]model.Sale, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, err
+	}
+
+	if _, err := uuid.Parse(recipeID); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid recipe ID")
+	}
+
+	var sales []model.Sale
+	if err := s.salesQuery(c, params).Preload("Recipe").
+		Where("recipe_id = ?", recipeID).
+		Order("date ASC, created_at ASC").
+		Find(&sales).Error; err != nil {
+		return nil, err
+	}
+
+	return sales, nil
+}
+
+// CreateSales records a batch of sales lines in one transaction. Lines of
+// recipes that deplete at sale deduct their ingredients right away.
+func (s *saleService) CreateSales(c *fiber.Ctx, req *validation.CreateSales) ([]model.Sale, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	sales := make([]model.Sale, 0, len(req.Sales))
+	err := s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		recipes := map[string]*model.Recipe{}
+
+		for i, line := range req.Sales {
+			recipe, ok := recipes[line.RecipeID]
+			if !ok {
+				var err error
+				if recipe, err = findSaleRecipe(tx, line.RecipeID); err != nil {
+					return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("line %d: %s", i+1, err.Error()))
+				}
+				recipes[line.RecipeID] = recipe
+			}
+
+			if line.BranchID != "" && line.BranchID != recipe.BranchID {
+				return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("line %d: recipe does not belong to branch", i+1))
+			}
+
+			date, _ := time.Parse("2006-01-02", line.Date)
+			sale := model.Sale{
+				BranchID: recipe.BranchID,
+				RecipeID: line.RecipeID,
+				Quantity: line.Quantity,
+				Date:     date,
+				Note:     line.Note,
+				Status:   SaleStatusActive,
+			}
+			if err := tx.Create(&sale).Error; err != nil {
+				return err
+			}
+			if err := depleteSale(tx, &sale, recipe); err != nil {
+				return err
+			}
+
+			sale.Recipe = recipe
+			sale.RecipeName = recipe.Name
+			sales = append(sales, sale)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sales, nil
+}
+
+func (s *saleService) UpdateSale(c *fiber.Ctx, id string, req *validation.UpdateSale) (*model.Sale, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid sale ID")
+	}
+
+	err := s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		sale, err := lockActiveSale(tx, id)
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{}
+		var recipe *model.Recipe
+		if req.RecipeID != nil && *req.RecipeID != sale.RecipeID {
+			recipe, err = findSaleRecipe(tx, *req.RecipeID)
+			if err != nil {
+				return fiber.NewError(fiber.StatusBadRequest, err.Error())
+			}
+			if recipe.BranchID != sale.BranchID {
+				return fiber.NewError(fiber.StatusBadRequest, "recipe does not belong to the sale's branch")
+			}
+			updates["recipe_id"] = recipe.ID.String()
+		}
+		if req.Quantity != nil && *req.Quantity != sale.Quantity {
+			updates["quantity"] = *req.Quantity
+		}
+		_, recipeChanged := updates["recipe_id"]
+		_, quantityChanged := updates["quantity"]
+		if req.Date != nil {
+			updates["date"] = *req.Date
+		}
+		if req.Note != nil {
+			updates["note"] = *req.Note
+		}
+
+		if len(updates) == 0 {
+			return nil
+		}
+
+		// what was sold changed: put the old depletion back and deplete again
+		if recipeChanged || quantityChanged {
+			if err := restoreSale(tx, sale, "sale edited"); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(sale).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		if recipeChanged || quantityChanged {
+			if err := tx.First(sale, "id = ?", sale.ID).Error; err != nil {
+				return err
+			}
+			if recipe == nil {
+				if recipe, err = findSaleRecipe(tx, sale.RecipeID); err != nil {
+					return err
+				}
+			}
+			return depleteSale(tx, sale, recipe)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetSaleByID(c, id)
+}
+
+// VoidSale keeps the sale for audit but excludes it from listings and
+// reports. Ingredients deducted at sale are put back.
+func (s *saleService) VoidSale(c *fiber.Ctx, id string, req *validation.VoidSale) (*model.Sale, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid sale ID")
+	}
+
+	err := s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		sale, err := lockActiveSale(tx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := restoreSale(tx, sale, req.Reason); err != nil {
+			return err
+		}
+
+		return tx.Model(sale).Updates(map[string]interface{}{
+			"status":      SaleStatusVoid,
+			"void_reason": req.Reason,
+			"voided_at":   time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetSaleByID(c, id)
+}
+
+func (s *saleService) salesQuery(c *fiber.Ctx, params *validation.QuerySale) *gorm.DB {
+	query := s.DB.WithContext(c.Context())
+
+	if params.BranchID != "" {
+		query = query.Where("branch_id = ?", params.BranchID)
+	}
+	if params.StartDate != "" {
+		query = query.Where("date >= ?", params.StartDate)
+	}
+	if params.EndDate != "" {
+		query = query.Where("date <= ?", params.EndDate)
+	}
+	if !params.IncludeVoided {
+		query = query.Where("status = ?", SaleStatusActive)
+	}
+
+	return query
+}
+
+func findSaleRecipe(db *gorm.DB, recipeID string) (*model.Recipe, error) {
+	var recipe model.Recipe
+	if err := db.First(&recipe, "id = ? AND deleted_at IS NULL", recipeID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("recipe %s not found", recipeID)
+		}
+		return nil, err
+	}
+
+	return &recipe, nil
+}
+
+func lockActiveSale(tx *gorm.DB, id string) (*model.Sale, error) {
+	var sale model.Sale
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&sale, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "Sale not found")
+		}
+		return nil, err
+	}
+
+	if sale.Status == SaleStatusVoid {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "sale is void")
+	}
+
+	return &sale, nil
+}
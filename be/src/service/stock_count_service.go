@@ -0,0 +1,186 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const StockCountReference = "stock_count"
+
+type StockCountService interface {
+	CreateStockCount(c *fiber.Ctx, req *validation.CreateStockCount) (*model.StockCount, error)
+	GetStockCounts(c *fiber.Ctx, params *validation.QueryStockCount) ([]model.StockCount, int64, error)
+	GetStockCountByID(c *fiber.Ctx, id string) (*model.StockCount, error)
+}
+
+type stockCountService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewStockCountService(db *gorm.DB, validate *validator.Validate) StockCountService {
+	return &stockCountService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+// CreateStockCount records what was physically counted and sets the book stock
+// of every counted item to it. Differences are posted as count_in / count_out
+// movements referencing the count.
+func (s *stockCountService) CreateStockCount(c *fiber.Ctx, req *validation.CreateStockCount) (*model.StockCount, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	for _, line := range req.Lines {
+		if seen[line.ItemID] {
+			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("item %s is counted more than once", line.ItemID))
+		}
+		seen[line.ItemID] = true
+	}
+
+	count := model.StockCount{
+		BranchID:  req.BranchID,
+		CountedAt: time.Now(),
+		Note:      req.Note,
+	}
+	if req.CountedBy != "" {
+		count.CountedBy = &req.CountedBy
+	}
+
+	err := s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		var branch model.Branch
+		if err := tx.First(&branch, "id = ? AND deleted_at IS NULL", req.BranchID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fiber.NewError(fiber.StatusNotFound, "Branch not found")
+			}
+			return err
+		}
+
+		if err := tx.Create(&count).Error; err != nil {
+			return err
+		}
+
+		ref := &movementRef{Type: StockCountReference, ID: count.ID.String()}
+		note := "Stock count"
+		if req.Note != "" {
+			note = fmt.Sprintf("Stock count: %s", req.Note)
+		}
+
+		for _, input := range req.Lines {
+			var item model.Item
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ? AND branch_id = ? AND deleted_at IS NULL", input.ItemID, req.BranchID).
+				First(&item).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("item %s not found in branch", input.ItemID))
+				}
+				return err
+			}
+
+			line := model.StockCountLine{
+				StockCountID:    count.ID.String(),
+				ItemID:          input.ItemID,
+				SystemQuantity:  item.Stock,
+				CountedQuantity: *input.Quantity,
+				Difference:      *input.Quantity - item.Stock,
+				UnitCost:        item.UnitCost,
+			}
+			if err := tx.Create(&line).Error; err != nil {
+				return err
+			}
+
+			if math.Abs(line.Difference) < 1e-9 {
+				continue
+			}
+			txType := "count_in"
+			if line.Difference < 0 {
+				txType = "count_out"
+			}
+			if _, err := postMovement(tx, &item, txType, math.Abs(line.Difference), note, ref); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetStockCountByID(c, count.ID.String())
+}
+
+func (s *stockCountService) GetStockCounts(c *fiber.Ctx, params *validation.QueryStockCount) ([]model.StockCount, int64, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, 0, err
+	}
+
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 {
+		params.Limit = 10
+	}
+
+	query := s.DB.WithContext(c.Context()).Model(&model.StockCount{}).
+		Where("branch_id = ?", params.BranchID)
+
+	if params.StartDate != "" {
+		query = query.Where("counted_at >= ?", params.StartDate)
+	}
+	if params.EndDate != "" {
+		query = query.Where("counted_at < ?::date + INTERVAL '1 day'", params.EndDate)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var counts []model.StockCount
+	if err := query.Order("counted_at DESC").
+		Offset((params.Page - 1) * params.Limit).
+		Limit(params.Limit).
+		Find(&counts).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return counts, total, nil
+}
+
+func (s *stockCountService) GetStockCountByID(c *fiber.Ctx, id string) (*model.StockCount, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid stock count ID")
+	}
+
+	var count model.StockCount
+	if err := s.DB.WithContext(c.Context()).
+		Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Preload("Lines.Item").
+		First(&count, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "stock count not found")
+		}
+		return nil, err
+	}
+
+	return &count, nil
+}
@@ -0,0 +1,416 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	ReservationSourceProductionOrder = "production_order"
+	ReservationSourceCateringOrder   = "catering_order"
+	ReservationSourceManual          = "manual"
+
+	ReservationStatusActive   = "active"
+	ReservationStatusConsumed = "consumed"
+	ReservationStatusReleased = "released"
+)
+
+type StockReservationService interface {
+	CreateReservation(c *fiber.Ctx, req *validation.CreateStockReservation) (*model.StockReservation, error)
+	GetReservations(c *fiber.Ctx, params *validation.QueryStockReservation) ([]model.StockReservation, int64, error)
+	GetReservationByID(c *fiber.Ctx, id string) (*model.StockReservation, error)
+	ReleaseReservation(c *fiber.Ctx, id string) (*model.StockReservation, error)
+}
+
+type stockReservationService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewStockReservationService(db *gorm.DB, validate *validator.Validate) StockReservationService {
+	return &stockReservationService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+func (s *stockReservationService) CreateReservation(c *fiber.Ctx, req *validation.CreateStockReservation) (*model.StockReservation, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresAt != "" {
+		t, err := time.Parse(time.RFC3339, req.ExpiresAt)
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "expires_at must be an RFC3339 timestamp")
+		}
+		if !t.After(time.Now()) {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "expires_at must be in the future")
+		}
+		expiresAt = &t
+	}
+
+	// A manual hold is its own source.
+	id := uuid.New()
+	source := movementRef{Type: req.SourceType, ID: req.SourceID}
+	if req.SourceType == ReservationSourceManual || source.ID == "" {
+		source.ID = id.String()
+	}
+
+	var reservation *model.StockReservation
+	err := s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		var item model.Item
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND branch_id = ? AND deleted_at IS NULL", req.ItemID, req.BranchID).
+			First(&item).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fiber.NewError(fiber.StatusNotFound, "Item not found in branch")
+			}
+			return err
+		}
+
+		var err error
+		reservation, err = reserveStock(tx, &item, req.Quantity, source, func(r *model.StockReservation) {
+			r.ID = id
+			r.ExpiresAt = expiresAt
+			r.Note = req.Note
+			if req.CreatedBy != "" {
+				r.CreatedBy = &req.CreatedBy
+			}
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetReservationByID(c, reservation.ID.String())
+}
+
+func (s *stockReservationService) GetReservations(c *fiber.Ctx, params *validation.QueryStockReservation) ([]model.StockReservation, int64, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, 0, err
+	}
+
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 {
+		params.Limit = 10
+	}
+
+	query := s.DB.WithContext(c.Context()).Model(&model.StockReservation{}).
+		Where("branch_id = ?", params.BranchID)
+
+	if params.ItemID != "" {
+		query = query.Where("item_id = ?", params.ItemID)
+	}
+	if params.SourceType != "" {
+		query = query.Where("source_type = ?", params.SourceType)
+	}
+	if params.SourceID != "" {
+		query = query.Where("source_id = ?", params.SourceID)
+	}
+
+	switch params.Status {
+	case "":
+	case ReservationStatusActive:
+		query = query.Where("status = ? AND (expires_at IS NULL OR expires_at > NOW())", ReservationStatusActive)
+	case "expired":
+		query = query.Where("status = ? AND expires_at <= NOW()", ReservationStatusActive)
+	default:
+		query = query.Where("status = ?", params.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var reservations []model.StockReservation
+	if err := query.Preload("Item").
+		Order("created_at DESC").
+		Offset((params.Page - 1) * params.Limit).
+		Limit(params.Limit).
+		Find(&reservations).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return reservations, total, nil
+}
+
+func (s *stockReservationService) GetReservationByID(c *fiber.Ctx, id string) (*model.StockReservation, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid reservation ID")
+	}
+
+	var reservation model.StockReservation
+	if err := s.DB.WithContext(c.Context()).Preload("Item").First(&reservation, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "reservation not found")
+		}
+		return nil, err
+	}
+
+	if reservation.Item != nil {
+		if err := withAvailability(s.DB.WithContext(c.Context()), []*model.Item{reservation.Item}); err != nil {
+			return nil, err
+		}
+	}
+
+	return &reservation, nil
+}
+
+func (s *stockReservationService) ReleaseReservation(c *fiber.Ctx, id string) (*model.StockReservation, error) {
+	reservation, err := s.GetReservationByID(c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if reservation.Status != ReservationStatusActive {
+		return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("reservation is already %s", reservation.Status))
+	}
+
+	// Production order reservations follow the order's lifecycle.
+	if reservation.SourceType == ReservationSourceProductionOrder {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "cancel the production order to release its reservation")
+	}
+
+	if err := s.DB.WithContext(c.Context()).Model(&model.StockReservation{}).
+		Where("id = ? AND status = ?", id, ReservationStatusActive).
+		Update("status", ReservationStatusReleased).Error; err != nil {
+		return nil, err
+	}
+
+	return s.GetReservationByID(c, id)
+}
+
+// activeReservations limits a reservation query to the ones still holding
+// stock: active and not yet expired.
+func activeReservations(db *gorm.DB) *gorm.DB {
+	return db.Where("status = ? AND (expires_at IS NULL OR expires_at > NOW())", ReservationStatusActive)
+}
+
+// reservedQuantity returns how much of the item is held by active
+// reservations. Reservations of the excluded source are not counted.
+func reservedQuantity(db *gorm.DB, itemID string, exclude *movementRef) (float64, error) {
+	query := activeReservations(db.Model(&model.StockReservation{})).Where("item_id = ?", itemID)
+	if exclude != nil {
+		query = query.Where("NOT (source_type = ? AND source_id = ?)", exclude.Type, exclude.ID)
+	}
+
+	var reserved float64
+	if err := query.Select("COALESCE(SUM(quantity), 0)").Scan(&reserved).Error; err != nil {
+		return 0, err
+	}
+
+	return reserved, nil
+}
+
+// availableQuantity is the on-hand stock that is not reserved for someone else.
+func availableQuantity(db *gorm.DB, item *model.Item, exclude *movementRef) (float64, error) {
+	reserved, err := reservedQuantity(db, item.ID.String(), exclude)
+	if err != nil {
+		return 0, err
+	}
+
+	return item.Stock - reserved, nil
+}
+
+// withAvailability fills the reserved and available quantities of the items.
+func withAvailability(db *gorm.DB, items []*model.Item) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID.String())
+	}
+
+	var rows []struct {
+		ItemID   string
+		Reserved float64
+	}
+	if err := activeReservations(db.Model(&model.StockReservation{})).
+		Select("item_id, SUM(quantity) AS reserved").
+		Where("item_id IN ?", ids).
+		Group("item_id").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	reserved := make(map[string]float64, len(rows))
+	for _, row := range rows {
+		reserved[row.ItemID] = row.Reserved
+	}
+
+	for _, item := range items {
+		item.Reserved = reserved[item.ID.String()]
+		item.Available = item.Stock - item.Reserved
+	}
+
+	return nil
+}
+
+func itemRefs(items []model.Item) []*model.Item {
+	refs := make([]*model.Item, len(items))
+	for i := range items {
+		refs[i] = &items[i]
+	}
+	return refs
+}
+
+// reserveStock sets quantity of an already locked item aside for the source.
+func reserveStock(tx *gorm.DB, item *model.Item, quantity float64, source movementRef, opts ...func(*model.StockReservation)) (*model.StockReservation, error) {
+	available, err := availableQuantity(tx, item, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if available < quantity {
+		return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("%s (%s): need %.2f %s, available %.2f %s (short by %.2f)",
+			item.Name, item.Code, quantity, item.Unit, available, item.Unit, quantity-available))
+	}
+
+	reservation := &model.StockReservation{
+		BranchID:   item.BranchID,
+		ItemID:     item.ID.String(),
+		Quantity:   quantity,
+		SourceType: source.Type,
+		SourceID:   source.ID,
+		Status:     ReservationStatusActive,
+	}
+	for _, opt := range opts {
+		opt(reservation)
+	}
+
+	if err := tx.Create(reservation).Error; err != nil {
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
+// settleReservations closes every active reservation of the source with the
+// given status (consumed or released).
+func settleReservations(tx *gorm.DB, source movementRef, status string) error {
+	return tx.Model(&model.StockReservation{}).
+		Where("source_type = ? AND source_id = ? AND status = ?", source.Type, source.ID, ReservationStatusActive).
+		Update("status", status).Error
+}
+
+// reservationDraw is what a movement drew from one reservation.
+type reservationDraw struct {
+	ReservationID string
+	Quantity      float64
+}
+
+// consumeReservations draws amount of the item from the active reservations
+// of the source, oldest first. Fully drawn reservations become consumed.
+func consumeReservations(tx *gorm.DB, source movementRef, itemID string, amount float64) ([]reservationDraw, error) {
+	var reservations []model.StockReservation
+	if err := activeReservations(tx.Clauses(clause.Locking{Strength: "UPDATE"})).
+		Where("source_type = ? AND source_id = ? AND item_id = ?", source.Type, source.ID, itemID).
+		Order("created_at ASC").
+		Find(&reservations).Error; err != nil {
+		return nil, err
+	}
+
+	var draws []reservationDraw
+	for _, reservation := range reservations {
+		if amount <= 0 {
+			break
+		}
+
+		updates := map[string]interface{}{"status": ReservationStatusConsumed}
+		drawn := reservation.Quantity
+		if reservation.Quantity > amount {
+			updates = map[string]interface{}{"quantity": reservation.Quantity - amount}
+			drawn = amount
+		}
+		amount -= reservation.Quantity
+
+		if err := tx.Model(&reservation).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+		draws = append(draws, reservationDraw{ReservationID: reservation.ID.String(), Quantity: drawn})
+	}
+
+	return draws, nil
+}
+
+// reinstateReservations reserves again what was drawn from the reservations.
+// A reservation that is still active takes it back; one consumed since is
+// followed by a new one for the same source. Released reservations stay
+// released, their source no longer needs the stock.
+func reinstateReservations(tx *gorm.DB, draws []reservationDraw, note string) error {
+	for _, draw := range draws {
+		var reservation model.StockReservation
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&reservation, "id = ?", draw.ReservationID).Error; err != nil {
+			return err
+		}
+
+		switch reservation.Status {
+		case ReservationStatusActive:
+			if err := tx.Model(&reservation).
+				Update("quantity", gorm.Expr("quantity + ?", draw.Quantity)).Error; err != nil {
+				return err
+			}
+		case ReservationStatusConsumed:
+			if err := tx.Create(&model.StockReservation{
+				BranchID:   reservation.BranchID,
+				ItemID:     reservation.ItemID,
+				Quantity:   draw.Quantity,
+				SourceType: reservation.SourceType,
+				SourceID:   reservation.SourceID,
+				Status:     ReservationStatusActive,
+				ExpiresAt:  reservation.ExpiresAt,
+				Note:       note,
+				CreatedBy:  reservation.CreatedBy,
+			}).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// reservationSource resolves the source of an active reservation on the item,
+// so a movement can draw on the stock held for it.
+func reservationSource(tx *gorm.DB, reservationID, itemID string) (*movementRef, error) {
+	if reservationID == "" {
+		return nil, nil
+	}
+	if _, err := uuid.Parse(reservationID); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid reservation ID")
+	}
+
+	var reservation model.StockReservation
+	if err := activeReservations(tx).
+		First(&reservation, "id = ? AND item_id = ?", reservationID, itemID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "reservation is not active for this item")
+		}
+		return nil, err
+	}
+
+	return &movementRef{Type: reservation.SourceType, ID: reservation.SourceID}, nil
+}
@@ -0,0 +1,538 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	SupplierStrategyPreferred = "preferred"
+	SupplierStrategyCheapest  = "cheapest"
+)
+
+type SupplierItemService interface {
+	CreateSupplierItem(c *fiber.Ctx, supplierID string, req *validation.CreateSupplierItem) (*model.SupplierItem, error)
+	GetSupplierItems(c *fiber.Ctx, supplierID string, params *validation.QuerySupplierItem) ([]model.SupplierItem, int64, error)
+	GetSupplierItem(c *fiber.Ctx, supplierID, itemID string) (*model.SupplierItem, error)
+	UpdateSupplierItem(c *fiber.Ctx, supplierID, itemID string, req *validation.UpdateSupplierItem) (*model.SupplierItem, error)
+	DeleteSupplierItem(c *fiber.Ctx, supplierID, itemID string) error
+	GetPriceHistory(c *fiber.Ctx, supplierID, itemID string) ([]model.SupplierItemPrice, error)
+	GetSuppliersForItem(c *fiber.Ctx, itemID string, params *validation.QuerySuppliersForItem) ([]model.SupplierItem, error)
+	GetItemSupplier(c *fiber.Ctx, itemID string, params *validation.QueryItemSupplier) (*model.SupplierItem, error)
+}
+
+type supplierItemService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewSupplierItemService(db *gorm.DB, validate *validator.Validate) SupplierItemService {
+	return &supplierItemService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+// CreateSupplierItem adds an item to the supplier's price list with its first
+// price. The pack unit has to convert to the item's stock unit.
+func (s *supplierItemService) CreateSupplierItem(c *fiber.Ctx, supplierID string, req *validation.CreateSupplierItem) (*model.SupplierItem, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	db := s.DB.WithContext(c.Context())
+	if _, err := findSupplier(db, supplierID); err != nil {
+		return nil, err
+	}
+
+	var item model.Item
+	if err := db.First(&item, "id = ? AND deleted_at IS NULL", req.ItemID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "Item not found")
+		}
+		return nil, err
+	}
+
+	supplierItem := model.SupplierItem{
+		SupplierID:   supplierID,
+		ItemID:       req.ItemID,
+		PurchaseUnit: strings.TrimSpace(req.PurchaseUnit),
+		PackSize:     req.PackSize,
+		PackUnit:     strings.TrimSpace(req.PackUnit),
+		MOQ:          req.MOQ,
+		LeadTime:     req.LeadTime,
+		Preferred:    req.Preferred,
+		CreatedBy:    currentUserID(c),
+		UpdatedBy:    currentUserID(c),
+	}
+	if supplierItem.PurchaseUnit == "" {
+		supplierItem.PurchaseUnit = item.Unit
+	}
+	if supplierItem.PackSize == 0 {
+		supplierItem.PackSize = 1
+	}
+	if supplierItem.PackUnit == "" {
+		supplierItem.PackUnit = item.Unit
+	}
+	if err := checkPackUnit(&supplierItem, &item); err != nil {
+		return nil, err
+	}
+
+	effectiveFrom, err := priceDate(req.EffectiveFrom)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&model.SupplierItem{}).
+			Where("supplier_id = ? AND item_id = ? AND deleted_at IS NULL", supplierID, req.ItemID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return fiber.NewError(fiber.StatusConflict, "The supplier already sells this item")
+		}
+
+		if err := tx.Create(&supplierItem).Error; err != nil {
+			return err
+		}
+		if err := setSupplierItemPrice(tx, c, &supplierItem, req.Price, effectiveFrom); err != nil {
+			return err
+		}
+		if supplierItem.Preferred {
+			if err := unsetPreferredSuppliers(tx, &supplierItem); err != nil {
+				return err
+			}
+		}
+		return recalculateCostsForItem(tx, &item)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetSupplierItem(c, supplierID, req.ItemID)
+}
+
+func (s *supplierItemService) GetSupplierItems(c *fiber.Ctx, supplierID string, params *validation.QuerySupplierItem) ([]model.SupplierItem, int64, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, 0, err
+	}
+
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 {
+		params.Limit = 10
+	}
+
+	date, err := priceDate(params.Date)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	db := s.DB.WithContext(c.Context())
+	if _, err := findSupplier(db, supplierID); err != nil {
+		return nil, 0, err
+	}
+
+	query := db.Model(&model.SupplierItem{}).
+		Joins("JOIN items ON items.id = supplier_items.item_id").
+		Where("supplier_items.supplier_id = ? AND supplier_items.deleted_at IS NULL", supplierID)
+	if params.ItemID != "" {
+		query = query.Where("supplier_items.item_id = ?", params.ItemID)
+	}
+	if params.Search != "" {
+		search := "%" + strings.ToLower(params.Search) + "%"
+		query = query.Where("LOWER(items.code) LIKE ? OR LOWER(items.name) LIKE ?", search, search)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var supplierItems []model.SupplierItem
+	if err := query.Preload("Item").
+		Order("items.name ASC").
+		Offset((params.Page - 1) * params.Limit).
+		Limit(params.Limit).
+		Find(&supplierItems).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := withSupplierPrices(db, supplierItems, date); err != nil {
+		return nil, 0, err
+	}
+
+	return supplierItems, total, nil
+}
+
+func (s *supplierItemService) GetSupplierItem(c *fiber.Ctx, supplierID, itemID string) (*model.SupplierItem, error) {
+	db := s.DB.WithContext(c.Context())
+	supplierItem, err := findSupplierItem(db, supplierID, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	supplierItems := []model.SupplierItem{*supplierItem}
+	if err := withSupplierPrices(db, supplierItems, forecastToday()); err != nil {
+		return nil, err
+	}
+
+	return &supplierItems[0], nil
+}
+
+func (s *supplierItemService) UpdateSupplierItem(c *fiber.Ctx, supplierID, itemID string, req *validation.UpdateSupplierItem) (*model.SupplierItem, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	effectiveFrom, err := priceDate(req.EffectiveFrom)
+	if err != nil {
+		return nil, err
+	}
+
+	db := s.DB.WithContext(c.Context())
+	supplierItem, err := findSupplierItem(db, supplierID, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{"updated_by": currentUserID(c)}
+	if req.MOQ != nil {
+		updates["moq"] = *req.MOQ
+	}
+	if req.LeadTime != nil {
+		updates["lead_time"] = *req.LeadTime
+	}
+	if req.PurchaseUnit != nil {
+		updates["purchase_unit"] = strings.TrimSpace(*req.PurchaseUnit)
+	}
+	if req.PackSize != nil {
+		supplierItem.PackSize = *req.PackSize
+		updates["pack_size"] = *req.PackSize
+	}
+	if req.PackUnit != nil {
+		supplierItem.PackUnit = strings.TrimSpace(*req.PackUnit)
+		updates["pack_unit"] = supplierItem.PackUnit
+	}
+	if req.Preferred != nil {
+		supplierItem.Preferred = *req.Preferred
+		updates["preferred"] = *req.Preferred
+	}
+	if err := checkPackUnit(supplierItem, supplierItem.Item); err != nil {
+		return nil, err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(supplierItem).Updates(updates).Error; err != nil {
+			return err
+		}
+		if req.Price != nil {
+			if err := setSupplierItemPrice(tx, c, supplierItem, *req.Price, effectiveFrom); err != nil {
+				return err
+			}
+		}
+		if req.Preferred != nil && *req.Preferred {
+			if err := unsetPreferredSuppliers(tx, supplierItem); err != nil {
+				return err
+			}
+		}
+
+		// the price, the pack or the preferred supplier the recipe costs use may
+		// have changed
+		if supplierItem.Item != nil && (req.Price != nil || req.Preferred != nil || req.PackSize != nil || req.PackUnit != nil) {
+			return recalculateCostsForItem(tx, supplierItem.Item)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetSupplierItem(c, supplierID, itemID)
+}
+
+// DeleteSupplierItem soft deletes the item from the supplier's price list.
+// Its price history is kept.
+func (s *supplierItemService) DeleteSupplierItem(c *fiber.Ctx, supplierID, itemID string) error {
+	db := s.DB.WithContext(c.Context())
+	supplierItem, err := findSupplierItem(db, supplierID, itemID)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(supplierItem).Updates(map[string]interface{}{
+			"preferred":  false,
+			"deleted_at": time.Now(),
+			"deleted_by": currentUserID(c),
+		}).Error; err != nil {
+			return err
+		}
+
+		if supplierItem.Item == nil {
+			return nil
+		}
+		return recalculateCostsForItem(tx, supplierItem.Item)
+	})
+}
+
+func (s *supplierItemService) GetPriceHistory(c *fiber.Ctx, supplierID, itemID string) ([]model.SupplierItemPrice, error) {
+	db := s.DB.WithContext(c.Context())
+	supplierItem, err := findSupplierItem(db, supplierID, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	var prices []model.SupplierItemPrice
+	if err := db.Where("supplier_item_id = ?", supplierItem.ID).
+		Order("effective_from DESC").
+		Find(&prices).Error; err != nil {
+		return nil, err
+	}
+
+	return prices, nil
+}
+
+// GetSuppliersForItem lists the suppliers that sell the item with their
+// price at the date: the preferred one first, then the cheapest per stock
+// unit.
+func (s *supplierItemService) GetSuppliersForItem(c *fiber.Ctx, itemID string, params *validation.QuerySuppliersForItem) ([]model.SupplierItem, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, err
+	}
+	if _, err := uuid.Parse(itemID); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid item ID")
+	}
+
+	date, err := priceDate(params.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	supplierItems, err := itemSuppliers(s.DB.WithContext(c.Context()), []string{itemID}, params.Search, date)
+	if err != nil {
+		return nil, err
+	}
+
+	return supplierItems[itemID], nil
+}
+
+// GetItemSupplier returns the supplier to buy the item from at the date: the
+// preferred one when it has a price then, otherwise the cheapest per stock
+// unit. With the cheapest strategy the preferred flag is ignored.
+func (s *supplierItemService) GetItemSupplier(c *fiber.Ctx, itemID string, params *validation.QueryItemSupplier) (*model.SupplierItem, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, err
+	}
+	if _, err := uuid.Parse(itemID); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid item ID")
+	}
+
+	date, err := priceDate(params.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	supplierItems, err := itemSuppliers(s.DB.WithContext(c.Context()), []string{itemID}, "", date)
+	if err != nil {
+		return nil, err
+	}
+
+	chosen := chooseSupplier(supplierItems[itemID], params.Strategy)
+	if chosen == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "No supplier has a price for the item at that date")
+	}
+
+	return chosen, nil
+}
+
+func findSupplierItem(db *gorm.DB, supplierID, itemID string) (*model.SupplierItem, error) {
+	if _, err := uuid.Parse(supplierID); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid supplier ID")
+	}
+	if _, err := uuid.Parse(itemID); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid item ID")
+	}
+
+	var supplierItem model.SupplierItem
+	if err := db.Preload("Item").Preload("Supplier").
+		First(&supplierItem, "supplier_id = ? AND item_id = ? AND deleted_at IS NULL", supplierID, itemID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "Supplier item not found")
+		}
+		return nil, err
+	}
+
+	return &supplierItem, nil
+}
+
+// itemSuppliers returns the supplier items of the items, by item, priced at
+// the date and ordered preferred first, then cheapest per stock unit. Deleted
+// suppliers are left out.
+func itemSuppliers(db *gorm.DB, itemIDs []string, search string, date time.Time) (map[string][]model.SupplierItem, error) {
+	query := db.Preload("Item").Preload("Supplier").
+		Joins("JOIN suppliers ON suppliers.id = supplier_items.supplier_id AND suppliers.deleted_at IS NULL").
+		Where("supplier_items.item_id IN ? AND supplier_items.deleted_at IS NULL", itemIDs)
+	if search != "" {
+		query = query.Where("LOWER(suppliers.name) LIKE ?", "%"+strings.ToLower(search)+"%")
+	}
+
+	var supplierItems []model.SupplierItem
+	if err := query.Find(&supplierItems).Error; err != nil {
+		return nil, err
+	}
+	if err := withSupplierPrices(db, supplierItems, date); err != nil {
+		return nil, err
+	}
+
+	byItem := map[string][]model.SupplierItem{}
+	for _, supplierItem := range supplierItems {
+		byItem[supplierItem.ItemID] = append(byItem[supplierItem.ItemID], supplierItem)
+	}
+	for _, list := range byItem {
+		sort.SliceStable(list, func(a, b int) bool {
+			if list[a].Preferred != list[b].Preferred {
+				return list[a].Preferred
+			}
+			if (list[a].StockPrice == nil) != (list[b].StockPrice == nil) {
+				return list[a].StockPrice != nil
+			}
+			if list[a].StockPrice != nil && *list[a].StockPrice != *list[b].StockPrice {
+				return *list[a].StockPrice < *list[b].StockPrice
+			}
+			return list[a].Supplier.Name < list[b].Supplier.Name
+		})
+	}
+
+	return byItem, nil
+}
+
+// chooseSupplier picks from supplier items ordered by itemSuppliers, among
+// the ones with a price.
+func chooseSupplier(supplierItems []model.SupplierItem, strategy string) *model.SupplierItem {
+	offers := make([]utils.SupplierOffer, len(supplierItems))
+	for i, supplierItem := range supplierItems {
+		offers[i] = utils.SupplierOffer{Preferred: supplierItem.Preferred, StockPrice: supplierItem.StockPrice}
+	}
+
+	i := utils.ChooseSupplier(offers, strategy == SupplierStrategyCheapest)
+	if i < 0 {
+		return nil
+	}
+	return &supplierItems[i]
+}
+
+// withSupplierPrices fills in the price effective at the date of each
+// supplier item, and what it comes to per stock unit of the item. The items
+// have to be preloaded.
+func withSupplierPrices(db *gorm.DB, supplierItems []model.SupplierItem, date time.Time) error {
+	if len(supplierItems) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(supplierItems))
+	for _, supplierItem := range supplierItems {
+		ids = append(ids, supplierItem.ID.String())
+	}
+
+	var prices []model.SupplierItemPrice
+	if err := db.Raw(`SELECT DISTINCT ON (supplier_item_id) *
+		FROM supplier_item_prices
+		WHERE supplier_item_id IN ? AND effective_from <= ?
+		ORDER BY supplier_item_id, effective_from DESC`,
+		ids, date.Format("2006-01-02")).
+		Scan(&prices).Error; err != nil {
+		return err
+	}
+	byID := make(map[string]model.SupplierItemPrice, len(prices))
+	for _, price := range prices {
+		byID[price.SupplierItemID] = price
+	}
+
+	for i := range supplierItems {
+		supplierItem := &supplierItems[i]
+		if supplierItem.Item != nil {
+			if perUnit, err := utils.ConvertUnit(supplierItem.PackSize, supplierItem.PackUnit, supplierItem.Item.Unit); err == nil {
+				supplierItem.StockPerUnit = perUnit
+			}
+		}
+
+		price, ok := byID[supplierItem.ID.String()]
+		if !ok {
+			continue
+		}
+		effectiveFrom := price.EffectiveFrom.Format("2006-01-02")
+		supplierItem.Price = &price.Price
+		supplierItem.EffectiveFrom = &effectiveFrom
+		if supplierItem.StockPerUnit > 0 {
+			stockPrice := roundMoney(price.Price / supplierItem.StockPerUnit)
+			supplierItem.StockPrice = &stockPrice
+		}
+	}
+
+	return nil
+}
+
+// setSupplierItemPrice records the price from the date on, replacing the one
+// already recorded for that day.
+func setSupplierItemPrice(tx *gorm.DB, c *fiber.Ctx, supplierItem *model.SupplierItem, price float64, effectiveFrom time.Time) error {
+	entry := model.SupplierItemPrice{
+		SupplierItemID: supplierItem.ID.String(),
+		Price:          price,
+		EffectiveFrom:  effectiveFrom,
+		CreatedBy:      currentUserID(c),
+	}
+
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "supplier_item_id"}, {Name: "effective_from"}},
+		DoUpdates: clause.AssignmentColumns([]string{"price", "created_by", "updated_at"}),
+	}).Create(&entry).Error
+}
+
+// unsetPreferredSuppliers leaves the supplier item the only preferred one for
+// its item.
+func unsetPreferredSuppliers(tx *gorm.DB, supplierItem *model.SupplierItem) error {
+	return tx.Model(&model.SupplierItem{}).
+		Where("item_id = ? AND id <> ? AND preferred", supplierItem.ItemID, supplierItem.ID).
+		Update("preferred", false).Error
+}
+
+func checkPackUnit(supplierItem *model.SupplierItem, item *model.Item) error {
+	if item == nil || utils.IsConvertibleUnit(supplierItem.PackUnit, item.Unit) {
+		return nil
+	}
+
+	return fiber.NewError(fiber.StatusBadRequest,
+		fmt.Sprintf("pack unit %s does not convert to the item's unit %s", supplierItem.PackUnit, item.Unit))
+}
+
+func priceDate(value string) (time.Time, error) {
+	if value == "" {
+		return forecastToday(), nil
+	}
+
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fiber.NewError(fiber.StatusBadRequest, "invalid date, expected YYYY-MM-DD")
+	}
+
+	return date, nil
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type SupplierService interface {
+	CreateSupplier(c *fiber.Ctx, req *validation.CreateSupplier) (*model.Supplier, error)
+	GetSuppliers(c *fiber.Ctx, params *validation.QuerySupplier) ([]model.Supplier, int64, error)
+	GetSupplierByID(c *fiber.Ctx, id string) (*model.Supplier, error)
+	UpdateSupplier(c *fiber.Ctx, id string, req *validation.UpdateSupplier) (*model.Supplier, error)
+	DeleteSupplier(c *fiber.Ctx, id string) error
+}
+
+type supplierService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewSupplierService(db *gorm.DB, validate *validator.Validate) SupplierService {
+	return &supplierService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+// CreateSupplier adds a supplier on behalf of the signed in user. Without a
+// slug one is made from the name.
+func (s *supplierService) CreateSupplier(c *fiber.Ctx, req *validation.CreateSupplier) (*model.Supplier, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	slug := strings.ToLower(strings.TrimSpace(req.Slug))
+	if slug == "" {
+		slug = slugify(req.Name)
+	}
+	if slug == "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "slug is required when the name has no letters or digits")
+	}
+
+	db := s.DB.WithContext(c.Context())
+	if err := ensureSupplierSlug(db, slug, ""); err != nil {
+		return nil, err
+	}
+
+	supplier := model.Supplier{
+		Name:           strings.TrimSpace(req.Name),
+		Slug:           slug,
+		Address:        strings.TrimSpace(req.Address),
+		WhatsappNumber: strings.TrimSpace(req.WhatsappNumber),
+		PhoneNumber:    strings.TrimSpace(req.PhoneNumber),
+		CreatedBy:      currentUserID(c),
+		UpdatedBy:      currentUserID(c),
+	}
+	if err := db.Create(&supplier).Error; err != nil {
+		return nil, err
+	}
+
+	return &supplier, nil
+}
+
+func (s *supplierService) GetSuppliers(c *fiber.Ctx, params *validation.QuerySupplier) ([]model.Supplier, int64, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, 0, err
+	}
+
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 {
+		params.Limit = 10
+	}
+
+	query := s.DB.WithContext(c.Context()).Model(&model.Supplier{}).Where("deleted_at IS NULL")
+
+	if params.Search != "" {
+		search := "%" + strings.ToLower(params.Search) + "%"
+		query = query.Where("LOWER(name) LIKE ? OR LOWER(slug) LIKE ? OR whatsapp_number LIKE ? OR phone_number LIKE ?",
+			search, search, search, search)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var suppliers []model.Supplier
+	if err := query.Order("name ASC").
+		Offset((params.Page - 1) * params.Limit).
+		Limit(params.Limit).
+		Find(&suppliers).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return suppliers, total, nil
+}
+
+func (s *supplierService) GetSupplierByID(c *fiber.Ctx, id string) (*model.Supplier, error) {
+	return findSupplier(s.DB.WithContext(c.Context()), id)
+}
+
+func (s *supplierService) UpdateSupplier(c *fiber.Ctx, id string, req *validation.UpdateSupplier) (*model.Supplier, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	db := s.DB.WithContext(c.Context())
+	supplier, err := findSupplier(db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{"updated_by": currentUserID(c)}
+	if req.Name != nil {
+		updates["name"] = strings.TrimSpace(*req.Name)
+	}
+	if req.Slug != nil && *req.Slug != "" {
+		slug := strings.ToLower(strings.TrimSpace(*req.Slug))
+		if err := ensureSupplierSlug(db, slug, id); err != nil {
+			return nil, err
+		}
+		updates["slug"] = slug
+	}
+	if req.Address != nil {
+		updates["address"] = strings.TrimSpace(*req.Address)
+	}
+	if req.WhatsappNumber != nil {
+		updates["whatsapp_number"] = strings.TrimSpace(*req.WhatsappNumber)
+	}
+	if req.PhoneNumber != nil {
+		updates["phone_number"] = strings.TrimSpace(*req.PhoneNumber)
+	}
+
+	if err := db.Model(supplier).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+
+	return findSupplier(db, id)
+}
+
+// DeleteSupplier soft deletes the supplier; its slug can be used again. The
+// recipes costed with its prices are costed again.
+func (s *supplierService) DeleteSupplier(c *fiber.Ctx, id string) error {
+	db := s.DB.WithContext(c.Context())
+	supplier, err := findSupplier(db, id)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(supplier).Updates(map[string]interface{}{
+			"deleted_at": time.Now(),
+			"deleted_by": currentUserID(c),
+		}).Error; err != nil {
+			return err
+		}
+
+		var items []model.Item
+		if err := tx.Where("id IN (?)",
+			tx.Model(&model.SupplierItem{}).Select("item_id").Where("supplier_id = ? AND deleted_at IS NULL", id),
+		).Find(&items).Error; err != nil {
+			return err
+		}
+		for i := range items {
+			if err := recalculateCostsForItem(tx, &items[i]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func findSupplier(db *gorm.DB, id string) (*model.Supplier, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid supplier ID")
+	}
+
+	var supplier model.Supplier
+	if err := db.First(&supplier, "id = ? AND deleted_at IS NULL", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "Supplier not found")
+		}
+		return nil, err
+	}
+
+	return &supplier, nil
+}
+
+// ensureSupplierSlug fails when another supplier that is not deleted already
+// uses the slug.
+func ensureSupplierSlug(db *gorm.DB, slug, exceptID string) error {
+	query := db.Model(&model.Supplier{}).Where("slug = ? AND deleted_at IS NULL", slug)
+	if exceptID != "" {
+		query = query.Where("id <> ?", exceptID)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return fiber.NewError(fiber.StatusConflict, "Supplier slug already exists")
+	}
+
+	return nil
+}
+
+// currentUserID returns the ID of the user the auth middleware signed in.
+func currentUserID(c *fiber.Ctx) *string {
+	user, ok := c.Locals("user").(*model.User)
+	if !ok || user == nil {
+		return nil
+	}
+
+	id := user.ID.String()
+	return &id
+}
+
+var slugSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+func slugify(name string) string {
+	return strings.Trim(slugSeparators.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
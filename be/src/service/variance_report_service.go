@@ -0,0 +1,404 @@
+package service
+
+import (
+	"time"
+
+	"app/src/model"
+	"app/src/response"
+	"app/src/utils"
+	"app/src/validation"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type VarianceReportService interface {
+	GetUsageVariance(c *fiber.Ctx, params *validation.QueryUsageVariance) (*response.UsageVarianceReport, error)
+}
+
+type varianceReportService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewVarianceReportService(db *gorm.DB, validate *validator.Validate) VarianceReportService {
+	return &varianceReportService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+// movementTotal is the sum of one item's movements of a kind, both within the
+// report period and from its start until now.
+type movementTotal struct {
+	ItemID        string
+	Type          string
+	ReferenceType string
+	InPeriod      float64
+	SinceStart    float64
+}
+
+// GetUsageVariance reports, per item, the usage the ledger shows for the
+// period against the usage sales and production explain:
+//
+//	actual usage = opening + purchases + transfers in - transfers out + produced - closing
+//	variance     = actual usage - theoretical usage - waste
+//
+// Opening and closing are the book stock at the period's edges, which equals
+// the counted stock when a stock count was taken there.
+func (s *varianceReportService) GetUsageVariance(c *fiber.Ctx, params *validation.QueryUsageVariance) (*response.UsageVarianceReport, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, err
+	}
+	if params.EndDate < params.StartDate {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "end_date must not be before start_date")
+	}
+
+	db := s.DB.WithContext(c.Context())
+
+	itemQuery := db.Where("deleted_at IS NULL")
+	if params.BranchID != "" {
+		itemQuery = itemQuery.Where("branch_id = ?", params.BranchID)
+	}
+	var items []model.Item
+	if err := itemQuery.Order("code ASC").Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	lines := make(map[string]*response.UsageVarianceLine, len(items))
+	itemsByID := make(map[string]*model.Item, len(items))
+	for i := range items {
+		item := &items[i]
+		itemsByID[item.ID.String()] = item
+		lines[item.ID.String()] = &response.UsageVarianceLine{
+			ItemID:   item.ID.String(),
+			BranchID: item.BranchID,
+			ItemCode: item.Code,
+			ItemName: item.Name,
+			Unit:     item.Unit,
+			Branches: 1,
+			Opening:  item.Stock,
+			Closing:  item.Stock,
+		}
+	}
+
+	if err := s.applyMovements(db, params, lines); err != nil {
+		return nil, err
+	}
+	excluded, err := s.applySales(db, params, itemsByID, lines)
+	if err != nil {
+		return nil, err
+	}
+	excludedProduction, err := s.applyProduction(db, itemsByID, lines)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyCounts(db, params, lines); err != nil {
+		return nil, err
+	}
+
+	report := &response.UsageVarianceReport{
+		BranchID:  params.BranchID,
+		StartDate: params.StartDate,
+		EndDate:   params.EndDate,
+		Lines:     []response.UsageVarianceLine{},
+		Branches:  []response.UsageVarianceBranch{},
+		Excluded:  append(excluded, excludedProduction...),
+	}
+
+	branchTotals := map[string]*response.UsageVarianceBranch{}
+	var perItem []response.UsageVarianceLine
+	for i := range items {
+		item := &items[i]
+		line := lines[item.ID.String()]
+
+		line.ActualUsage = line.Opening + line.Purchases + line.TransfersIn - line.TransfersOut + line.Produced - line.Closing
+		line.Variance = line.ActualUsage - line.TheoreticalUsage - line.Waste
+		line.ActualValue = roundMoney(line.ActualUsage * item.UnitCost)
+		line.TheoreticalValue = roundMoney(line.TheoreticalUsage * item.UnitCost)
+		line.WasteValue = roundMoney(line.Waste * item.UnitCost)
+		line.VarianceValue = roundMoney(line.Variance * item.UnitCost)
+
+		if params.ItemID != "" && params.ItemID != line.ItemID {
+			continue
+		}
+		perItem = append(perItem, *line)
+
+		totals, ok := branchTotals[item.BranchID]
+		if !ok {
+			totals = &response.UsageVarianceBranch{BranchID: item.BranchID}
+			branchTotals[item.BranchID] = totals
+		}
+		totals.ActualValue += line.ActualValue
+		totals.TheoreticalValue += line.TheoreticalValue
+		totals.WasteValue += line.WasteValue
+		totals.VarianceValue += line.VarianceValue
+	}
+
+	if params.BranchID == "" {
+		perItem = rollUpVarianceLines(perItem)
+	}
+	for i := range perItem {
+		line := &perItem[i]
+		if line.TheoreticalUsage > 0 {
+			percent := roundMoney(line.Variance / line.TheoreticalUsage * 100)
+			line.VariancePercent = &percent
+		}
+
+		report.ActualValue += line.ActualValue
+		report.TheoreticalValue += line.TheoreticalValue
+		report.WasteValue += line.WasteValue
+		report.VarianceValue += line.VarianceValue
+	}
+	report.Lines = append(report.Lines, perItem...)
+
+	if len(branchTotals) > 0 {
+		branchIDs := make([]string, 0, len(branchTotals))
+		for id := range branchTotals {
+			branchIDs = append(branchIDs, id)
+		}
+
+		var branches []model.Branch
+		if err := db.Select("id", "name").Where("id IN ?", branchIDs).Order("name ASC").Find(&branches).Error; err != nil {
+			return nil, err
+		}
+		for _, branch := range branches {
+			totals := branchTotals[branch.ID.String()]
+			totals.BranchName = branch.Name
+			totals.ActualValue = roundMoney(totals.ActualValue)
+			totals.TheoreticalValue = roundMoney(totals.TheoreticalValue)
+			totals.WasteValue = roundMoney(totals.WasteValue)
+			totals.VarianceValue = roundMoney(totals.VarianceValue)
+			report.Branches = append(report.Branches, *totals)
+		}
+	}
+
+	report.ActualValue = roundMoney(report.ActualValue)
+	report.TheoreticalValue = roundMoney(report.TheoreticalValue)
+	report.WasteValue = roundMoney(report.WasteValue)
+	report.VarianceValue = roundMoney(report.VarianceValue)
+
+	return report, nil
+}
+
+// applyMovements walks the book stock back from today to the period's edges
+// and adds up the movements within it by kind.
+func (s *varianceReportService) applyMovements(db *gorm.DB, params *validation.QueryUsageVariance, lines map[string]*response.UsageVarianceLine) error {
+	query := db.Model(&model.ItemTransaction{}).
+		Select("item_id, type, COALESCE(reference_type, '') AS reference_type, "+
+			"SUM(CASE WHEN transaction_date < ?::date + INTERVAL '1 day' THEN amount ELSE 0 END) AS in_period, "+
+			"SUM(amount) AS since_start", params.EndDate).
+		Where("transaction_date >= ? AND deleted_at IS NULL", params.StartDate).
+		Group("item_id, type, COALESCE(reference_type, '')")
+	if params.BranchID != "" {
+		query = query.Where("branch_id = ?", params.BranchID)
+	}
+
+	var totals []movementTotal
+	if err := query.Scan(&totals).Error; err != nil {
+		return err
+	}
+
+	for _, total := range totals {
+		line, ok := lines[total.ItemID]
+		if !ok {
+			continue
+		}
+
+		sign := -1.0
+		if isInboundMovement(total.Type) {
+			sign = 1
+		}
+		line.Opening -= sign * total.SinceStart
+		line.Closing -= sign * (total.SinceStart - total.InPeriod)
+
+		switch total.Type {
+		case "in":
+			// other inbound references put back what a sale or cook took
+			if total.ReferenceType == "" || total.ReferenceType == PurchaseOrderReference {
+				line.Purchases += total.InPeriod
+			}
+		case "transfer_in":
+			line.TransfersIn += total.InPeriod
+		case "transfer_out":
+			line.TransfersOut += total.InPeriod
+		case "cook_in":
+			line.Produced += total.InPeriod
+		case "waste":
+			line.Waste += total.InPeriod
+		case "count_in":
+			line.CountAdjustment += total.InPeriod
+		case "count_out":
+			line.CountAdjustment -= total.InPeriod
+		}
+	}
+
+	return nil
+}
+
+// applySales adds the ingredients of the dishes sold in the period to the
+// theoretical usage, whether or not the sale depleted stock itself.
+func (s *varianceReportService) applySales(db *gorm.DB, params *validation.QueryUsageVariance, itemsByID map[string]*model.Item, lines map[string]*response.UsageVarianceLine) ([]response.ExcludedUsageLine, error) {
+	var sold []struct {
+		RecipeID string
+		Quantity float64
+	}
+	query := db.Model(&model.Sale{}).
+		Select("recipe_id, SUM(quantity) AS quantity").
+		Where("status = ? AND date BETWEEN ? AND ?", "active", params.StartDate, params.EndDate).
+		Group("recipe_id")
+	if params.BranchID != "" {
+		query = query.Where("branch_id = ?", params.BranchID)
+	}
+	if err := query.Scan(&sold).Error; err != nil {
+		return nil, err
+	}
+
+	var excluded []response.ExcludedUsageLine
+	for _, sale := range sold {
+		var recipe model.Recipe
+		if err := db.Preload("Ingredients").First(&recipe, "id = ?", sale.RecipeID).Error; err != nil {
+			return nil, err
+		}
+		excluded = append(excluded, addTheoreticalUsage(&recipe, sale.Quantity, itemsByID, lines)...)
+	}
+
+	return excluded, nil
+}
+
+// applyProduction adds what the half-finished items produced in the period
+// should have taken from their ingredients.
+func (s *varianceReportService) applyProduction(db *gorm.DB, itemsByID map[string]*model.Item, lines map[string]*response.UsageVarianceLine) ([]response.ExcludedUsageLine, error) {
+	var excluded []response.ExcludedUsageLine
+	for id, line := range lines {
+		if line.Produced <= 0 {
+			continue
+		}
+
+		recipe, err := findSubRecipe(db, itemsByID[id])
+		if err != nil {
+			return nil, err
+		}
+		if recipe == nil {
+			continue
+		}
+		if err := db.Where("recipe_id = ?", recipe.ID).Find(&recipe.Ingredients).Error; err != nil {
+			return nil, err
+		}
+		excluded = append(excluded, addTheoreticalUsage(recipe, line.Produced, itemsByID, lines)...)
+	}
+
+	return excluded, nil
+}
+
+func (s *varianceReportService) applyCounts(db *gorm.DB, params *validation.QueryUsageVariance, lines map[string]*response.UsageVarianceLine) error {
+	var counted []struct {
+		ItemID        string
+		LastCountedAt time.Time
+	}
+	query := db.Table("stock_count_lines").
+		Select("stock_count_lines.item_id, MAX(stock_counts.counted_at) AS last_counted_at").
+		Joins("JOIN stock_counts ON stock_counts.id = stock_count_lines.stock_count_id").
+		Where("stock_counts.counted_at < ?::date + INTERVAL '1 day'", params.EndDate).
+		Group("stock_count_lines.item_id")
+	if params.BranchID != "" {
+		query = query.Where("stock_counts.branch_id = ?", params.BranchID)
+	}
+	if err := query.Scan(&counted).Error; err != nil {
+		return err
+	}
+
+	start, err := time.Parse("2006-01-02", params.StartDate)
+	if err != nil {
+		return err
+	}
+	for _, count := range counted {
+		line, ok := lines[count.ItemID]
+		if !ok {
+			continue
+		}
+		countedAt := count.LastCountedAt
+		line.LastCountedAt = &countedAt
+		line.Counted = !countedAt.Before(start)
+	}
+
+	return nil
+}
+
+// addTheoreticalUsage adds what the servings should have taken from each
+// ingredient. Units convert like they do when cooking; a line whose units
+// cannot be converted is left out and returned, so one bad line does not hide
+// the rest of the report.
+func addTheoreticalUsage(recipe *model.Recipe, servings float64, itemsByID map[string]*model.Item, lines map[string]*response.UsageVarianceLine) []response.ExcludedUsageLine {
+	var excluded []response.ExcludedUsageLine
+	for _, ingredient := range recipe.Ingredients {
+		item, ok := itemsByID[ingredient.ItemID]
+		if !ok {
+			continue
+		}
+
+		quantity, err := stockQuantity(ingredient.Quantity*servings/recipeYield(recipe), ingredient.Unit, item)
+		if err != nil {
+			excluded = append(excluded, response.ExcludedUsageLine{
+				RecipeID:   recipe.ID.String(),
+				RecipeName: recipe.Name,
+				ItemID:     ingredient.ItemID,
+				ItemCode:   item.Code,
+				ItemName:   item.Name,
+				Unit:       ingredient.Unit,
+				Reason:     err.Error(),
+			})
+			continue
+		}
+		lines[ingredient.ItemID].TheoreticalUsage += quantity
+	}
+
+	return excluded
+}
+
+// rollUpVarianceLines merges the lines of the same item in different branches,
+// matched by code and unit. Lines keep the order of their first branch.
+func rollUpVarianceLines(perItem []response.UsageVarianceLine) []response.UsageVarianceLine {
+	index := map[string]int{}
+	var rolled []response.UsageVarianceLine
+
+	for _, line := range perItem {
+		key := line.ItemCode + "\x00" + line.Unit
+		i, ok := index[key]
+		if !ok {
+			line.ItemID = ""
+			line.BranchID = ""
+			index[key] = len(rolled)
+			rolled = append(rolled, line)
+			continue
+		}
+
+		total := &rolled[i]
+		total.Branches++
+		total.Opening += line.Opening
+		total.Purchases += line.Purchases
+		total.TransfersIn += line.TransfersIn
+		total.TransfersOut += line.TransfersOut
+		total.Produced += line.Produced
+		total.Closing += line.Closing
+		total.CountAdjustment += line.CountAdjustment
+		total.ActualUsage += line.ActualUsage
+		total.TheoreticalUsage += line.TheoreticalUsage
+		total.Waste += line.Waste
+		total.Variance += line.Variance
+		total.ActualValue += line.ActualValue
+		total.TheoreticalValue += line.TheoreticalValue
+		total.WasteValue += line.WasteValue
+		total.VarianceValue += line.VarianceValue
+		total.Counted = total.Counted || line.Counted
+		if line.LastCountedAt != nil && (total.LastCountedAt == nil || line.LastCountedAt.After(*total.LastCountedAt)) {
+			total.LastCountedAt = line.LastCountedAt
+		}
+	}
+
+	return rolled
+}
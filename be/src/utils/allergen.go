@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"sort"
+	"strings"
+)
+
+// NormalizeAllergens lowercases, trims and de-duplicates allergen names and
+// returns them sorted.
+func NormalizeAllergens(allergens []string) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, allergen := range allergens {
+		allergen = strings.ToLower(strings.TrimSpace(allergen))
+		if allergen == "" || seen[allergen] {
+			continue
+		}
+		seen[allergen] = true
+		result = append(result, allergen)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// SplitAllergens parses the comma separated allergen column of an item.
+func SplitAllergens(value string) []string {
+	return NormalizeAllergens(strings.Split(value, ","))
+}
+
+func JoinAllergens(allergens []string) string {
+	return strings.Join(NormalizeAllergens(allergens), ",")
+}
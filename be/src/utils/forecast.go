@@ -0,0 +1,291 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	ForecastMovingAverage        = "moving_average"
+	ForecastExponentialSmoothing = "exponential_smoothing"
+	ForecastHoltWinters          = "holt_winters"
+)
+
+// ForecastParams tunes the forecasting models. Zero values take the defaults:
+// a 7 day window, alpha 0.3, beta 0.1, gamma 0.3 and a weekly season.
+type ForecastParams struct {
+	Window       int     `json:"window,omitempty"`        // moving average
+	Alpha        float64 `json:"alpha,omitempty"`         // level smoothing
+	Beta         float64 `json:"beta,omitempty"`          // trend smoothing, Holt-Winters
+	Gamma        float64 `json:"gamma,omitempty"`         // seasonal smoothing, Holt-Winters
+	SeasonLength int     `json:"season_length,omitempty"` // days, Holt-Winters
+}
+
+func (p ForecastParams) WithDefaults() ForecastParams {
+	if p.Window <= 0 {
+		p.Window = 7
+	}
+	if p.Alpha <= 0 || p.Alpha > 1 {
+		p.Alpha = 0.3
+	}
+	if p.Beta <= 0 || p.Beta > 1 {
+		p.Beta = 0.1
+	}
+	if p.Gamma <= 0 || p.Gamma > 1 {
+		p.Gamma = 0.3
+	}
+	if p.SeasonLength <= 0 {
+		p.SeasonLength = 7
+	}
+	return p
+}
+
+// Forecast predicts the next horizon values of a daily series with the named
+// model and returns the model it used: Holt-Winters needs two full seasons of
+// history and falls back to exponential smoothing without them. Sales cannot
+// be negative, so neither are the forecasts.
+func Forecast(model string, history []float64, horizon int, params ForecastParams) ([]float64, string, error) {
+	params = params.WithDefaults()
+
+	var values []float64
+	switch model {
+	case ForecastMovingAverage:
+		values = MovingAverage(history, params.Window, horizon)
+	case ForecastExponentialSmoothing:
+		values = ExponentialSmoothing(history, params.Alpha, horizon)
+	case ForecastHoltWinters:
+		if len(history) < 2*params.SeasonLength {
+			model = ForecastExponentialSmoothing
+			values = ExponentialSmoothing(history, params.Alpha, horizon)
+			break
+		}
+		values = HoltWinters(history, params.Alpha, params.Beta, params.Gamma, params.SeasonLength, horizon)
+	default:
+		return nil, "", fmt.Errorf("unknown forecast model '%s'", model)
+	}
+
+	for i := range values {
+		values[i] = math.Max(values[i], 0)
+	}
+
+	return values, model, nil
+}
+
+// MovingAverage forecasts every day as the mean of the last window days.
+func MovingAverage(history []float64, window, horizon int) []float64 {
+	if window > len(history) {
+		window = len(history)
+	}
+
+	mean := 0.0
+	if window > 0 {
+		for _, v := range history[len(history)-window:] {
+			mean += v
+		}
+		mean /= float64(window)
+	}
+
+	return constantForecast(mean, horizon)
+}
+
+// ExponentialSmoothing forecasts every day as the smoothed level of the
+// series, weighting recent days by alpha.
+func ExponentialSmoothing(history []float64, alpha float64, horizon int) []float64 {
+	if len(history) == 0 {
+		return constantForecast(0, horizon)
+	}
+
+	level := history[0]
+	for _, v := range history[1:] {
+		level = alpha*v + (1-alpha)*level
+	}
+
+	return constantForecast(level, horizon)
+}
+
+// HoltWinters is additive triple exponential smoothing: a level, a trend and a
+// seasonal offset per day of the season. The history must cover at least two
+// seasons.
+func HoltWinters(history []float64, alpha, beta, gamma float64, season, horizon int) []float64 {
+	n := len(history)
+	if season <= 0 || n < 2*season {
+		return ExponentialSmoothing(history, alpha, horizon)
+	}
+
+	first, second := mean(history[:season]), mean(history[season:2*season])
+	level := first
+	trend := (second - first) / float64(season)
+
+	seasonals := make([]float64, n)
+	for i := 0; i < season; i++ {
+		seasonals[i] = history[i] - first
+	}
+
+	for t := season; t < n; t++ {
+		previous := level
+		level = alpha*(history[t]-seasonals[t-season]) + (1-alpha)*(level+trend)
+		trend = beta*(level-previous) + (1-beta)*trend
+		seasonals[t] = gamma*(history[t]-level) + (1-gamma)*seasonals[t-season]
+	}
+
+	values := make([]float64, horizon)
+	for h := 1; h <= horizon; h++ {
+		values[h-1] = level + float64(h)*trend + seasonals[n-season+(h-1)%season]
+	}
+
+	return values
+}
+
+func constantForecast(value float64, horizon int) []float64 {
+	values := make([]float64, horizon)
+	for i := range values {
+		values[i] = value
+	}
+	return values
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// ForecastHitTolerance is how far off a forecast may be, relative to the
+// actual sales, and still count as a hit. Within one serving always counts.
+const ForecastHitTolerance = 0.2
+
+// ForecastAccuracy summarises forecasts against actual sales. MAPE leaves out
+// days without sales and is nil when there are none; a positive bias means
+// the forecasts were too high.
+type ForecastAccuracy struct {
+	Points  int
+	MAPE    *float64 // percent
+	MAE     float64
+	Bias    float64
+	HitRate float64 // percent of points that were a hit
+}
+
+// MeasureForecast compares forecasts with the actual values of the same days.
+func MeasureForecast(forecast, actual []float64) ForecastAccuracy {
+	var accuracy ForecastAccuracy
+	var absolute, signed, percentage float64
+	var hits, withSales int
+
+	for i := 0; i < len(forecast) && i < len(actual); i++ {
+		diff := forecast[i] - actual[i]
+		accuracy.Points++
+		absolute += math.Abs(diff)
+		signed += diff
+		if actual[i] > 0 {
+			percentage += math.Abs(diff) / actual[i]
+			withSales++
+		}
+		if math.Abs(diff) <= math.Max(ForecastHitTolerance*actual[i], 1) {
+			hits++
+		}
+	}
+
+	if accuracy.Points == 0 {
+		return accuracy
+	}
+	accuracy.MAE = absolute / float64(accuracy.Points)
+	accuracy.Bias = signed / float64(accuracy.Points)
+	accuracy.HitRate = float64(hits) / float64(accuracy.Points) * 100
+	if withSales > 0 {
+		mape := percentage / float64(withSales) * 100
+		accuracy.MAPE = &mape
+	}
+
+	return accuracy
+}
+
+// Backtest replays the model over the history: the last folds windows of
+// horizon days are each forecast from the days before them, and all those
+// forecasts are measured together. Windows with less than a week to learn
+// from are skipped.
+func Backtest(model string, history []float64, horizon, folds int, params ForecastParams) (ForecastAccuracy, error) {
+	var forecasts, actuals []float64
+
+	for fold := folds; fold >= 1; fold-- {
+		origin := len(history) - fold*horizon
+		if origin < 7 {
+			continue
+		}
+
+		values, _, err := Forecast(model, history[:origin], horizon, params)
+		if err != nil {
+			return ForecastAccuracy{}, err
+		}
+		forecasts = append(forecasts, values...)
+		actuals = append(actuals, history[origin:origin+horizon]...)
+	}
+
+	return MeasureForecast(forecasts, actuals), nil
+}
+
+// CalendarDay is what the events on a day do to sales: they are multiplied
+// by Multiplier (zero counts as one), or there are none when Closed.
+type CalendarDay struct {
+	Multiplier float64
+	Closed     bool
+}
+
+func (d CalendarDay) factor() float64 {
+	if d.Multiplier <= 0 {
+		return 1
+	}
+	return d.Multiplier
+}
+
+// RemoveCalendarEffects turns sales history into what ordinary days would have
+// sold, so events do not leak into the models: event days are divided by
+// their multiplier and closed days take the mean of the last week's open days.
+func RemoveCalendarEffects(history []float64, days []CalendarDay) []float64 {
+	adjusted := make([]float64, len(history))
+	var open []float64
+
+	for i, v := range history {
+		if i >= len(days) {
+			adjusted[i] = v
+			open = append(open, v)
+			continue
+		}
+		if days[i].Closed {
+			recent := open
+			if len(recent) > 7 {
+				recent = recent[len(recent)-7:]
+			}
+			adjusted[i] = mean(recent)
+			continue
+		}
+
+		adjusted[i] = v / days[i].factor()
+		open = append(open, adjusted[i])
+	}
+
+	return adjusted
+}
+
+// ApplyCalendarEffects puts the events of the forecast days back in.
+func ApplyCalendarEffects(forecast []float64, days []CalendarDay) []float64 {
+	adjusted := make([]float64, len(forecast))
+	for i, v := range forecast {
+		adjusted[i] = v
+		if i >= len(days) {
+			continue
+		}
+		if days[i].Closed {
+			adjusted[i] = 0
+			continue
+		}
+		adjusted[i] = v * days[i].factor()
+	}
+
+	return adjusted
+}
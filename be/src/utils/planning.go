@@ -0,0 +1,87 @@
+package utils
+
+const (
+	UrgencyLow      = "low"
+	UrgencyMedium   = "medium"
+	UrgencyHigh     = "high"
+	UrgencyCritical = "critical"
+)
+
+// NetRequirements projects the available quantity day by day: scheduled
+// receipts come in and gross requirements go out. Whatever the projection
+// falls short of on a day is that day's shortfall, to arrive that day, after
+// which the projection starts again from zero.
+func NetRequirements(available float64, gross, receipts []float64) (shortfall, projected []float64) {
+	shortfall = make([]float64, len(gross))
+	projected = make([]float64, len(gross))
+
+	balance := available
+	for day := range gross {
+		if day < len(receipts) {
+			balance += receipts[day]
+		}
+		balance -= gross[day]
+		if balance < -1e-9 {
+			shortfall[day] = -balance
+			balance = 0
+		}
+		projected[day] = balance
+	}
+
+	return shortfall, projected
+}
+
+// OrderDay is the day an order has to go out to arrive on the need day: the
+// lead time before. When that day has passed the order is late and goes out
+// today, day 0.
+func OrderDay(needDay, leadTime int) (int, bool) {
+	day := needDay - leadTime
+	if day < 0 {
+		return 0, true
+	}
+	return day, false
+}
+
+// OrderUrgency is critical when the order is late, high when it has to go out
+// today and medium when within three days.
+func OrderUrgency(late bool, daysUntilOrder int) string {
+	switch {
+	case late:
+		return UrgencyCritical
+	case daysUntilOrder <= 0:
+		return UrgencyHigh
+	case daysUntilOrder <= 3:
+		return UrgencyMedium
+	default:
+		return UrgencyLow
+	}
+}
+
+// SupplierOffer is what a supplier asks for one stock unit of an item.
+type SupplierOffer struct {
+	Preferred  bool
+	StockPrice *float64 // nil without a current price
+}
+
+// ChooseSupplier returns the index of the offer to buy from, or -1 when none
+// has a price: the first preferred one with a price, unless cheapest is set,
+// and otherwise the first of the cheapest.
+func ChooseSupplier(offers []SupplierOffer, cheapest bool) int {
+	cheapestAt, preferredAt := -1, -1
+	for i, offer := range offers {
+		if offer.StockPrice == nil {
+			continue
+		}
+		if offer.Preferred && preferredAt < 0 {
+			preferredAt = i
+		}
+		if cheapestAt < 0 || *offer.StockPrice < *offers[cheapestAt].StockPrice {
+			cheapestAt = i
+		}
+	}
+
+	if !cheapest && preferredAt >= 0 {
+		return preferredAt
+	}
+	return cheapestAt
+}
@@ -0,0 +1,588 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PosSaleLine is one product line of a POS sales export.
+type PosSaleLine struct {
+	Row         int // line of the file, for error reports
+	Date        time.Time
+	ProductCode string
+	ProductName string
+	Variant     string
+	Quantity    float64
+	Price       float64
+	ExternalRef string // identifies the line across uploads of the same data
+	Optional    bool   // modifiers: skipped instead of reported when unmapped
+}
+
+// VariantCode is the product code a variant is mapped under, e.g.
+// "NOO.01 / Large". Lines without a variant use the product code.
+func (l PosSaleLine) VariantCode() string {
+	if l.Variant == "" {
+		return l.ProductCode
+	}
+	return l.ProductCode + " / " + l.Variant
+}
+
+var (
+	quinosCodeColumns     = []string{"code", "product code", "product_code", "item code", "item_code", "kode", "kode produk", "plu"}
+	quinosNameColumns     = []string{"name", "item", "item name", "item_name", "product", "product name", "product_name", "description", "nama", "nama produk"}
+	quinosQuantityColumns = []string{"qty", "quantity", "qty sold", "sold", "jumlah"}
+	quinosPriceColumns    = []string{"net sales", "net", "sales", "total", "amount", "price"}
+)
+
+// ParseQuinosSales reads a Quinos item sales export. The report preamble
+// (store name, period, ...) is skipped up to the header row, category and
+// total rows are ignored and lines of the same product are added up. A Quinos
+// export covers a single day, so lines carry no date.
+func ParseQuinosSales(r io.Reader) ([]PosSaleLine, error) {
+	records, err := ReadLooseCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	header, columns := FindHeaderRow(records, map[string][]string{
+		"code":     quinosCodeColumns,
+		"name":     quinosNameColumns,
+		"quantity": quinosQuantityColumns,
+		"price":    quinosPriceColumns,
+	}, "code", "quantity")
+	if header < 0 {
+		return nil, errors.New("no product code and quantity columns found in the Quinos export")
+	}
+
+	var lines []PosSaleLine
+	index := map[string]int{}
+	for i := header + 1; i < len(records); i++ {
+		record := records[i]
+		code := CSVField(record, columns["code"])
+		if code == "" || isTotalRow(code) || isTotalRow(CSVField(record, columns["name"])) {
+			continue
+		}
+
+		quantity, err := ParseNumber(CSVField(record, columns["quantity"]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid quantity %q", i+1, CSVField(record, columns["quantity"]))
+		}
+		price, _ := ParseNumber(CSVField(record, columns["price"]))
+
+		if j, ok := index[code]; ok {
+			lines[j].Quantity += quantity
+			lines[j].Price += price
+			continue
+		}
+
+		index[code] = len(lines)
+		lines = append(lines, PosSaleLine{
+			Row:         i + 1,
+			ProductCode: code,
+			ProductName: CSVField(record, columns["name"]),
+			Quantity:    quantity,
+			Price:       price,
+		})
+	}
+
+	return lines, nil
+}
+
+var (
+	isellerDateColumns     = []string{"order date", "date", "transaction date", "created date", "sales date", "tanggal"}
+	isellerOrderColumns    = []string{"order no", "order no.", "order number", "order id", "receipt no", "receipt number", "transaction no", "invoice no"}
+	isellerCodeColumns     = []string{"sku", "product sku", "variant sku", "product code", "item code", "code"}
+	isellerNameColumns     = []string{"product name", "product", "item name", "item", "name"}
+	isellerVariantColumns  = []string{"variant", "variant name", "product variant", "variants"}
+	isellerModifierColumns = []string{"modifier", "modifiers", "modifier name", "add-ons", "addons"}
+	isellerQuantityColumns = []string{"quantity", "qty", "qty sold"}
+	isellerPriceColumns    = []string{"total", "net sales", "subtotal", "sub total", "price"}
+)
+
+// ParseIsellerSales reads an iSeller sales export with one row per order
+// line. The file may span several days. A variant keeps the product code and
+// is matched as VariantCode first; every modifier of a row (comma separated,
+// with an optional "x2" or "(2)" count) becomes an optional line of its own.
+// Each line gets an ExternalRef derived from its content, so uploading the
+// same rows again yields the same references.
+func ParseIsellerSales(r io.Reader) ([]PosSaleLine, error) {
+	records, err := ReadLooseCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	header, columns := FindHeaderRow(records, map[string][]string{
+		"date":     isellerDateColumns,
+		"order":    isellerOrderColumns,
+		"code":     isellerCodeColumns,
+		"name":     isellerNameColumns,
+		"variant":  isellerVariantColumns,
+		"modifier": isellerModifierColumns,
+		"quantity": isellerQuantityColumns,
+		"price":    isellerPriceColumns,
+	}, "date", "quantity")
+	if header < 0 || (columns["code"] < 0 && columns["name"] < 0) {
+		return nil, errors.New("no date, product and quantity columns found in the iSeller export")
+	}
+
+	var lines []PosSaleLine
+	seen := map[string]int{}
+	for i := header + 1; i < len(records); i++ {
+		record := records[i]
+		code := CSVField(record, columns["code"])
+		name := CSVField(record, columns["name"])
+		if code == "" {
+			code = name
+		}
+		if code == "" || isTotalRow(code) {
+			continue
+		}
+
+		date, err := ParseFlexibleDate(CSVField(record, columns["date"]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		quantity, err := ParseNumber(CSVField(record, columns["quantity"]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid quantity %q", i+1, CSVField(record, columns["quantity"]))
+		}
+		price, _ := ParseNumber(CSVField(record, columns["price"]))
+
+		// identical rows of one file are told apart by their occurrence
+		key := strings.Join(record, "\x1f")
+		seen[key]++
+		ref := LineRef(date.Format("2006-01-02"), CSVField(record, columns["order"]), key, strconv.Itoa(seen[key]))
+
+		line := PosSaleLine{
+			Row:         i + 1,
+			Date:        date,
+			ProductCode: code,
+			ProductName: name,
+			Variant:     CSVField(record, columns["variant"]),
+			Quantity:    quantity,
+			Price:       price,
+			ExternalRef: ref,
+		}
+		lines = append(lines, line)
+
+		for j, modifier := range splitModifiers(CSVField(record, columns["modifier"])) {
+			lines = append(lines, PosSaleLine{
+				Row:         i + 1,
+				Date:        date,
+				ProductCode: modifier.name,
+				ProductName: modifier.name,
+				Quantity:    quantity * modifier.count,
+				ExternalRef: LineRef(ref, "modifier", strconv.Itoa(j)),
+				Optional:    true,
+			})
+		}
+	}
+
+	return lines, nil
+}
+
+type posModifier struct {
+	name  string
+	count float64
+}
+
+// splitModifiers parses "Extra Egg x2, Less Sugar, Cheese (2)".
+func splitModifiers(value string) []posModifier {
+	var modifiers []posModifier
+	for _, part := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' || r == '|' }) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		modifier := posModifier{name: part, count: 1}
+		if i := strings.LastIndex(part, "("); i > 0 && strings.HasSuffix(part, ")") {
+			if count, err := ParseNumber(part[i+1 : len(part)-1]); err == nil && count > 0 {
+				modifier = posModifier{name: strings.TrimSpace(part[:i]), count: count}
+			}
+		} else if i := strings.LastIndex(strings.ToLower(part), " x"); i > 0 {
+			if count, err := ParseNumber(part[i+2:]); err == nil && count > 0 {
+				modifier = posModifier{name: strings.TrimSpace(part[:i]), count: count}
+			}
+		}
+		modifiers = append(modifiers, modifier)
+	}
+
+	return modifiers
+}
+
+// LineRef derives a stable external reference from the parts that identify a
+// sale line, so importing the same line again is recognised.
+func LineRef(parts ...string) string {
+	sum := sha1.Sum([]byte(strings.Join(parts, "\x1e")))
+	return hex.EncodeToString(sum[:])
+}
+
+var flexibleDateLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"02/01/2006 15:04:05",
+	"02/01/2006 15:04",
+	"02/01/2006",
+	"2/1/2006 15:04",
+	"2/1/2006",
+	"02-01-2006 15:04:05",
+	"02-01-2006",
+	"02 Jan 2006 15:04:05",
+	"02 Jan 2006 15:04",
+	"02 Jan 2006",
+	"2 Jan 2006",
+	"Jan 2, 2006 15:04",
+	"Jan 2, 2006",
+}
+
+// ParseFlexibleDate parses the date of a POS export row and drops the time of
+// day. Numeric dates are read day first.
+func ParseFlexibleDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range flexibleDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+// SalesColumnMapping names the columns of a sales CSV. Empty optional columns
+// are not read; an empty DateFormat accepts the usual formats.
+type SalesColumnMapping struct {
+	DateColumn        string
+	ProductCodeColumn string
+	ProductNameColumn string
+	QuantityColumn    string
+	PriceColumn       string
+	DateFormat        string // e.g. DD/MM/YYYY, see DateLayout
+}
+
+// CSVRowError reports an invalid field of a CSV row.
+type CSVRowError struct {
+	Line    int
+	Field   string
+	Message string
+}
+
+// ParseMappedSales reads a sales CSV whose columns are named by the mapping.
+// The first row naming the date, product code and quantity columns is the
+// header. Every invalid row is reported; lines are only returned when there
+// are no errors.
+func ParseMappedSales(r io.Reader, mapping SalesColumnMapping) ([]PosSaleLine, []CSVRowError, error) {
+	layout := ""
+	if mapping.DateFormat != "" {
+		var err error
+		if layout, err = DateLayout(mapping.DateFormat); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	records, err := ReadLooseCSV(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	aliases := map[string][]string{
+		"date":     {mapping.DateColumn},
+		"code":     {mapping.ProductCodeColumn},
+		"quantity": {mapping.QuantityColumn},
+	}
+	if mapping.ProductNameColumn != "" {
+		aliases["name"] = []string{mapping.ProductNameColumn}
+	}
+	if mapping.PriceColumn != "" {
+		aliases["price"] = []string{mapping.PriceColumn}
+	}
+
+	header, columns := FindHeaderRow(records, aliases, "date", "code", "quantity")
+	if header < 0 {
+		return nil, nil, fmt.Errorf("columns %q, %q and %q not found", mapping.DateColumn, mapping.ProductCodeColumn, mapping.QuantityColumn)
+	}
+	if _, ok := columns["name"]; !ok {
+		columns["name"] = -1
+	}
+	if _, ok := columns["price"]; !ok {
+		columns["price"] = -1
+	}
+
+	var lines []PosSaleLine
+	var rowErrors []CSVRowError
+	for i := header + 1; i < len(records); i++ {
+		record := records[i]
+		if strings.Join(record, "") == "" {
+			continue
+		}
+
+		line := PosSaleLine{
+			Row:         i + 1,
+			ProductCode: CSVField(record, columns["code"]),
+			ProductName: CSVField(record, columns["name"]),
+		}
+		if line.ProductCode == "" {
+			rowErrors = append(rowErrors, CSVRowError{Line: i + 1, Field: mapping.ProductCodeColumn, Message: "product code is required"})
+		}
+
+		value := CSVField(record, columns["date"])
+		if layout != "" {
+			line.Date, err = time.Parse(layout, value)
+		} else {
+			line.Date, err = ParseFlexibleDate(value)
+		}
+		if err != nil {
+			rowErrors = append(rowErrors, CSVRowError{Line: i + 1, Field: mapping.DateColumn, Message: fmt.Sprintf("invalid date %q", value)})
+		}
+
+		value = CSVField(record, columns["quantity"])
+		if line.Quantity, err = ParseNumber(value); err != nil || value == "" {
+			rowErrors = append(rowErrors, CSVRowError{Line: i + 1, Field: mapping.QuantityColumn, Message: fmt.Sprintf("invalid quantity %q", value)})
+		}
+
+		value = CSVField(record, columns["price"])
+		if line.Price, err = ParseNumber(value); err != nil {
+			rowErrors = append(rowErrors, CSVRowError{Line: i + 1, Field: mapping.PriceColumn, Message: fmt.Sprintf("invalid price %q", value)})
+		}
+
+		lines = append(lines, line)
+	}
+
+	if len(rowErrors) > 0 {
+		return nil, rowErrors, nil
+	}
+
+	return lines, nil, nil
+}
+
+var dateFormatTokens = []struct{ token, layout string }{
+	{"YYYY", "2006"},
+	{"YY", "06"},
+	{"MMMM", "January"},
+	{"MMM", "Jan"},
+	{"MM", "01"},
+	{"M", "1"},
+	{"DD", "02"},
+	{"D", "2"},
+	{"HH", "15"},
+	{"hh", "03"},
+	{"mm", "04"},
+	{"ss", "05"},
+}
+
+// DateLayout turns a date format written as DD/MM/YYYY, YYYY-MM-DD HH:mm,
+// D MMM YYYY, ... into a Go time layout.
+func DateLayout(format string) (string, error) {
+	var layout strings.Builder
+	hasDate := false
+	for i := 0; i < len(format); {
+		matched := false
+		for _, t := range dateFormatTokens {
+			if strings.HasPrefix(format[i:], t.token) {
+				layout.WriteString(t.layout)
+				i += len(t.token)
+				matched = true
+				hasDate = hasDate || strings.ContainsAny(t.token, "YMD")
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+
+		if c := format[i]; (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			return "", fmt.Errorf("invalid date format %q", format)
+		}
+		layout.WriteByte(format[i])
+		i++
+	}
+
+	if !hasDate {
+		return "", fmt.Errorf("invalid date format %q", format)
+	}
+
+	return layout.String(), nil
+}
+
+// ReadLooseCSV reads every record of a POS export. The delimiter (comma or
+// semicolon) is guessed from the first lines, a UTF-8 BOM is dropped and rows
+// may have any number of fields.
+func ReadLooseCSV(r io.Reader) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = guessDelimiter(data)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		for i := range record {
+			record[i] = strings.TrimSpace(record[i])
+		}
+	}
+
+	return records, nil
+}
+
+func guessDelimiter(data []byte) rune {
+	commas, semicolons := 0, 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 0; n < 10 && scanner.Scan(); n++ {
+		commas += strings.Count(scanner.Text(), ",")
+		semicolons += strings.Count(scanner.Text(), ";")
+	}
+
+	if semicolons > commas {
+		return ';'
+	}
+	return ','
+}
+
+// FindHeaderRow looks for the first record naming every required column and
+// returns its index with the position of each column it found. Column names
+// are matched case-insensitively against the aliases. It returns -1 when no
+// record qualifies.
+func FindHeaderRow(records [][]string, aliases map[string][]string, required ...string) (int, map[string]int) {
+	for i, record := range records {
+		columns := map[string]int{}
+		for key, names := range aliases {
+			columns[key] = -1
+			for j, field := range record {
+				if containsFold(names, field) {
+					columns[key] = j
+					break
+				}
+			}
+		}
+
+		found := true
+		for _, key := range required {
+			if columns[key] < 0 {
+				found = false
+				break
+			}
+		}
+		if found {
+			return i, columns
+		}
+	}
+
+	return -1, nil
+}
+
+// CSVField returns the field at index, or "" when the record is too short or
+// the column is missing (index < 0).
+func CSVField(record []string, index int) string {
+	if index < 0 || index >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[index])
+}
+
+// ParseNumber parses quantities and amounts as POS systems print them:
+// "1,234.50", "1.234,50", "12,5", "Rp 1.500.000" or "(3)" for negatives. A
+// single dot is always a decimal point.
+func ParseNumber(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	value = strings.TrimPrefix(strings.TrimPrefix(value, "Rp"), "IDR")
+	value = strings.ReplaceAll(strings.TrimSpace(value), " ", "")
+	if value == "" || value == "-" {
+		return 0, nil
+	}
+
+	negative := false
+	if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+		negative = true
+		value = value[1 : len(value)-1]
+	}
+
+	comma, dot := strings.LastIndex(value, ","), strings.LastIndex(value, ".")
+	switch {
+	case comma >= 0 && dot >= 0:
+		// the separator that comes last is the decimal one
+		if comma > dot {
+			value = strings.ReplaceAll(value, ".", "")
+			value = strings.Replace(value, ",", ".", 1)
+		} else {
+			value = strings.ReplaceAll(value, ",", "")
+		}
+	case comma >= 0:
+		if strings.Count(value, ",") == 1 && len(value)-comma-1 != 3 {
+			value = strings.Replace(value, ",", ".", 1)
+		} else {
+			value = strings.ReplaceAll(value, ",", "")
+		}
+	case dot >= 0:
+		if strings.Count(value, ".") > 1 {
+			value = strings.ReplaceAll(value, ".", "")
+		}
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if negative {
+		number = -number
+	}
+
+	return number, nil
+}
+
+// ProductCodes returns the distinct product codes of the lines, variant codes
+// included, sorted.
+func ProductCodes(lines []PosSaleLine) []string {
+	seen := map[string]bool{}
+	codes := []string{}
+	for _, line := range lines {
+		for _, code := range []string{line.ProductCode, line.VariantCode()} {
+			if !seen[code] {
+				seen[code] = true
+				codes = append(codes, code)
+			}
+		}
+	}
+	sort.Strings(codes)
+
+	return codes
+}
+
+func isTotalRow(value string) bool {
+	value = strings.ToLower(strings.TrimSpace(value))
+	return strings.HasPrefix(value, "total") || strings.HasPrefix(value, "sub total") ||
+		strings.HasPrefix(value, "subtotal") || strings.HasPrefix(value, "grand total")
+}
+
+func containsFold(values []string, value string) bool {
+	value = strings.TrimSpace(value)
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
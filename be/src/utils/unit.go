@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+type unitDef struct {
+	dimension string
+	factor    float64 // factor to the base unit of the dimension
+}
+
+// base units: gram (mass), milliliter (volume), piece (count)
+var units = map[string]unitDef{
+	"mg":    {"mass", 0.001},
+	"g":     {"mass", 1},
+	"gr":    {"mass", 1},
+	"gram":  {"mass", 1},
+	"kg":    {"mass", 1000},
+	"ons":   {"mass", 100},
+	"ml":    {"volume", 1},
+	"cc":    {"volume", 1},
+	"l":     {"volume", 1000},
+	"lt":    {"volume", 1000},
+	"liter": {"volume", 1000},
+	"litre": {"volume", 1000},
+	"pcs":   {"count", 1},
+	"pc":    {"count", 1},
+	"piece": {"count", 1},
+	"dozen": {"count", 12},
+	"lusin": {"count", 12},
+}
+
+func NormalizeUnit(unit string) string {
+	return strings.ToLower(strings.TrimSpace(unit))
+}
+
+// ConvertUnit converts qty expressed in `from` into the `to` unit.
+// Unknown units can only be converted to themselves.
+func ConvertUnit(qty float64, from, to string) (float64, error) {
+	from, to = NormalizeUnit(from), NormalizeUnit(to)
+	if from == to {
+		return qty, nil
+	}
+
+	fromDef, okFrom := units[from]
+	toDef, okTo := units[to]
+	if !okFrom || !okTo || fromDef.dimension != toDef.dimension {
+		return 0, fmt.Errorf("cannot convert unit '%s' to '%s'", from, to)
+	}
+
+	return qty * fromDef.factor / toDef.factor, nil
+}
+
+// IsKnownUnit tells whether the unit is one ConvertUnit can convert.
+func IsKnownUnit(unit string) bool {
+	_, ok := units[NormalizeUnit(unit)]
+	return ok
+}
+
+func IsConvertibleUnit(from, to string) bool {
+	_, err := ConvertUnit(1, from, to)
+	return err == nil
+}
+
+// ToBaseUnit expresses qty in the base unit of its dimension (gram,
+// milliliter or piece). Unknown units are their own base unit.
+func ToBaseUnit(qty float64, unit string) float64 {
+	if def, ok := units[NormalizeUnit(unit)]; ok {
+		return qty * def.factor
+	}
+	return qty
+}
+
+// PurchaseUnits is how many whole purchase units to order to get quantity
+// stock units when one purchase unit holds perUnit of them, and at least the
+// minimum order quantity.
+func PurchaseUnits(quantity, perUnit, moq float64) float64 {
+	if perUnit <= 0 {
+		return 0
+	}
+
+	units := math.Ceil(quantity/perUnit - 1e-9)
+	return math.Max(units, moq)
+}
@@ -1,7 +1,11 @@
 package utils
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -32,3 +36,19 @@ func VerifyToken(tokenStr, secret, tokenType string) (string, error) {
 
 	return userID, nil
 }
+
+// SignPayload signs a webhook payload: hex HMAC-SHA256 of "timestamp.body".
+func SignPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyPayload checks a webhook signature, given as the hex digest with an
+// optional "sha256=" prefix, in constant time.
+func VerifyPayload(secret, timestamp string, body []byte, signature string) bool {
+	signature = strings.TrimPrefix(strings.TrimSpace(signature), "sha256=")
+	expected := SignPayload(secret, timestamp, body)
+	return hmac.Equal([]byte(expected), []byte(strings.ToLower(signature)))
+}
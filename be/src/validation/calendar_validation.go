@@ -0,0 +1,53 @@
+package validation
+
+type CreateCalendarEvent struct {
+	BranchID  string  `json:"branch_id" validate:"omitempty,uuid"` // empty for every branch
+	Name      string  `json:"name" validate:"required,max=255"`
+	Type      string  `json:"type" validate:"required,oneof=holiday religious local_event promotion closure"`
+	StartDate string  `json:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate   string  `json:"end_date" validate:"required,datetime=2006-01-02"`
+	Uplift    float64 `json:"uplift" validate:"gte=-100,lte=1000"` // percent
+	Closed    bool    `json:"closed"`                              // always set for closures
+	Note      string  `json:"note"`
+}
+
+type UpdateCalendarEvent struct {
+	Name      *string  `json:"name" validate:"omitempty,max=255"`
+	Type      *string  `json:"type" validate:"omitempty,oneof=holiday religious local_event promotion closure"`
+	StartDate *string  `json:"start_date" validate:"omitempty,datetime=2006-01-02"`
+	EndDate   *string  `json:"end_date" validate:"omitempty,datetime=2006-01-02"`
+	Uplift    *float64 `json:"uplift" validate:"omitempty,gte=-100,lte=1000"`
+	Closed    *bool    `json:"closed"`
+	Note      *string  `json:"note"`
+}
+
+type QueryCalendarEvent struct {
+	// BranchID lists the branch's events and those of every branch; without
+	// it only the latter are listed.
+	BranchID  string `query:"branch_id" validate:"omitempty,uuid"`
+	Type      string `query:"type" validate:"omitempty,oneof=holiday religious local_event promotion closure"`
+	StartDate string `query:"start_date" validate:"omitempty,datetime=2006-01-02"`
+	EndDate   string `query:"end_date" validate:"omitempty,datetime=2006-01-02"`
+	Page      int    `query:"page"`
+	Limit     int    `query:"limit"`
+}
+
+type SetForecastOverride struct {
+	RecipeID string   `json:"recipe_id" validate:"required,uuid"`
+	Date     string   `json:"date" validate:"required,datetime=2006-01-02"`
+	Quantity *float64 `json:"quantity" validate:"required,gte=0"`
+	Reason   string   `json:"reason"`
+}
+
+type RemoveForecastOverride struct {
+	Reason string `query:"reason"`
+}
+
+type QueryForecastOverride struct {
+	BranchID  string `query:"branch_id" validate:"required,uuid"`
+	RecipeID  string `query:"recipe_id" validate:"omitempty,uuid"`
+	StartDate string `query:"start_date" validate:"omitempty,datetime=2006-01-02"`
+	EndDate   string `query:"end_date" validate:"omitempty,datetime=2006-01-02"`
+	Page      int    `query:"page"`
+	Limit     int    `query:"limit"`
+}
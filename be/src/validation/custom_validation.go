@@ -2,6 +2,7 @@ package validation
 
 import (
 	"regexp"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -28,6 +29,17 @@ func AlphanumDash(field validator.FieldLevel) bool {
 	return regex.MatchString(value)
 }
 
+// Phone accepts phone numbers written with spaces, dashes, dots or
+// parentheses, e.g. "+62 812-3456-7890" or "(021) 555 1234": 8 to 15 digits
+// after an optional leading "+".
+func Phone(field validator.FieldLevel) bool {
+	value := strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "").Replace(field.Field().String())
+
+	regex := regexp.MustCompile(`^\+?[0-9]{8,15}$`)
+
+	return regex.MatchString(value)
+}
+
 func (r *TransferItem) Validate() error {
 	validate := validator.New()
 	
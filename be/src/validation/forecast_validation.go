@@ -0,0 +1,44 @@
+package validation
+
+type CreateForecastRun struct {
+	BranchID    string  `json:"branch_id" validate:"required,uuid"`
+	Model       string  `json:"model" validate:"omitempty,oneof=auto moving_average exponential_smoothing holt_winters"` // defaults to auto
+	HistoryDays int     `json:"history_days" validate:"omitempty,min=7,max=730"`                                         // defaults to 56
+	Horizon     int     `json:"horizon" validate:"omitempty,min=1,max=90"`                                               // defaults to 7
+	Window      int     `json:"window" validate:"omitempty,min=1,max=90"`
+	Alpha       float64 `json:"alpha" validate:"omitempty,gt=0,lte=1"`
+	Beta        float64 `json:"beta" validate:"omitempty,gt=0,lte=1"`
+	Gamma       float64 `json:"gamma" validate:"omitempty,gt=0,lte=1"`
+}
+
+type QueryForecastRun struct {
+	BranchID string `query:"branch_id" validate:"required,uuid"`
+	Page     int    `query:"page"`
+	Limit    int    `query:"limit"`
+}
+
+type QueryProcessedForecast struct {
+	BranchID    string `query:"branch_id" validate:"required,uuid"`
+	RecipeCodes string `query:"recipe_codes"`                           // separated by ";" or ","
+	Days        int    `query:"days" validate:"omitempty,min=1,max=90"` // days of actual sales before the forecast, defaults to 7
+}
+
+type QueryForecastAccuracy struct {
+	BranchID  string `query:"branch_id" validate:"required,uuid"`
+	StartDate string `query:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate   string `query:"end_date" validate:"required,datetime=2006-01-02"`
+}
+
+type ForecastBacktest struct {
+	BranchID    string   `json:"branch_id" validate:"required,uuid"`
+	RecipeCodes []string `json:"recipe_codes"`
+	Models      []string `json:"models" validate:"omitempty,dive,oneof=moving_average exponential_smoothing holt_winters"` // defaults to all
+	HistoryDays int      `json:"history_days" validate:"omitempty,min=14,max=730"`                                         // defaults to 84
+	Horizon     int      `json:"horizon" validate:"omitempty,min=1,max=90"`                                                // defaults to 7
+	Folds       int      `json:"folds" validate:"omitempty,min=1,max=52"`                                                  // defaults to 4
+	Window      int      `json:"window" validate:"omitempty,min=1,max=90"`
+	Alpha       float64  `json:"alpha" validate:"omitempty,gt=0,lte=1"`
+	Beta        float64  `json:"beta" validate:"omitempty,gt=0,lte=1"`
+	Gamma       float64  `json:"gamma" validate:"omitempty,gt=0,lte=1"`
+	Apply       bool     `json:"apply"` // save the best model per recipe for auto forecasts
+}
@@ -7,23 +7,45 @@ import (
 )
 
 type CreateItem struct {
-	BranchID string `json:"branch_id" validate:"required,uuid"`
-	Code     string `json:"code" validate:"required"`
-	Name     string `json:"name" validate:"required"`
-	Type     string `json:"type" validate:"required"`
-	Stock    int    `json:"stock" validate:"required,min=0"`
-	Unit     string `json:"unit" validate:"required"`
-	LeadTime int    `json:"lead_time" validate:"required,min=0"`
+	BranchID string  `json:"branch_id" validate:"required,uuid"`
+	Code     string  `json:"code" validate:"required"`
+	Name     string  `json:"name" validate:"required"`
+	Type     string  `json:"type" validate:"required"`
+	Stock    int     `json:"stock" validate:"required,min=0"`
+	Unit     string  `json:"unit" validate:"required"`
+	LeadTime int     `json:"lead_time" validate:"required,min=0"`
+	UnitCost float64 `json:"unit_cost" validate:"omitempty,min=0"`
+	// comma separated, e.g. "gluten,milk"
+	Allergens string         `json:"allergens"`
+	Nutrition *ItemNutrition `json:"nutrition" validate:"omitempty"`
+}
+
+// ItemNutrition is given per base unit of the item's unit (gram, milliliter
+// or piece).
+type ItemNutrition struct {
+	Kcal    float64 `json:"kcal" validate:"min=0"`
+	Protein float64 `json:"protein" validate:"min=0"`
+	Fat     float64 `json:"fat" validate:"min=0"`
+	Carbs   float64 `json:"carbs" validate:"min=0"`
+	Sodium  float64 `json:"sodium" validate:"min=0"`
 }
 
 type UpdateItem struct {
-	BranchID *string `json:"branch_id" validate:"omitempty,uuid"`
-	Code     *string `json:"code" validate:"omitempty"`
-	Name     *string `json:"name" validate:"omitempty"`
-	Type     *string `json:"type" validate:"omitempty"`
-	Stock    *int    `json:"stock" validate:"omitempty,min=0"`
-	Unit     *string `json:"unit" validate:"omitempty"`
-	LeadTime *int    `json:"lead_time" validate:"omitempty,min=0"`
+	BranchID  *string        `json:"branch_id" validate:"omitempty,uuid"`
+	Code      *string        `json:"code" validate:"omitempty"`
+	Name      *string        `json:"name" validate:"omitempty"`
+	Type      *string        `json:"type" validate:"omitempty"`
+	Stock     *int           `json:"stock" validate:"omitempty,min=0"`
+	Unit      *string        `json:"unit" validate:"omitempty"`
+	LeadTime  *int           `json:"lead_time" validate:"omitempty,min=0"`
+	UnitCost  *float64       `json:"unit_cost" validate:"omitempty,min=0"`
+	Allergens *string        `json:"allergens"`
+	Nutrition *ItemNutrition `json:"nutrition" validate:"omitempty"`
+}
+
+type DeleteItem struct {
+	// moves recipe references to this item before deleting
+	ReplacementItemID string `query:"replacement_item_id" validate:"omitempty,uuid"`
 }
 
 type QueryItem struct {
@@ -40,6 +62,8 @@ type TransferItem struct {
 	Amount       float64 `json:"amount" validate:"required,gt=0"`
 	Type         string  `json:"type" validate:"required,oneof=transfer_in transfer_out"`
 	Note         string  `json:"note"`
+	// ReservationID lets the transfer draw on stock reserved for it.
+	ReservationID string `json:"reservation_id" validate:"omitempty,uuid"`
 }
 
 type CreateItemTransaction struct {
@@ -47,10 +71,12 @@ type CreateItemTransaction struct {
 	BranchID        string    `json:"branch_id" validate:"required"`
 	FromBranchID    string    `json:"from_branch_id,omitempty"`
 	ToBranchID      string    `json:"to_branch_id,omitempty"`
-	Type            string    `json:"type" validate:"required,oneof=in out transfer_in transfer_out"`
+	Type            string    `json:"type" validate:"required,oneof=in out waste transfer_in transfer_out"`
 	Amount          float64   `json:"amount" validate:"required,gt=0"`
 	Note            string    `json:"note"`
 	TransactionDate time.Time `json:"transaction_date"`
+	// ReservationID lets an out movement draw on stock reserved for it.
+	ReservationID string `json:"reservation_id" validate:"omitempty,uuid"`
 }
 
 type QueryItemTransaction struct {
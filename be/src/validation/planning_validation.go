@@ -0,0 +1,7 @@
+package validation
+
+type QueryRequirementsPlan struct {
+	BranchID    string `query:"branch_id" validate:"required,uuid"`
+	Horizon     int    `query:"horizon" validate:"omitempty,min=1,max=90"` // days from today, defaults to 14
+	RecipeCodes string `query:"recipe_codes"`                              // separated by ";" or ","; defaults to every recipe
+}
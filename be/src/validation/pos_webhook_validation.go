@@ -0,0 +1,38 @@
+package validation
+
+type CreatePosIntegration struct {
+	BranchID string `json:"branch_id" validate:"required,uuid"`
+	Name     string `json:"name" validate:"required,max=100"`
+	Source   string `json:"source" validate:"required,max=20"`
+}
+
+type UpdatePosIntegration struct {
+	Name   *string `json:"name" validate:"omitempty,max=100"`
+	Active *bool   `json:"active"`
+}
+
+type QueryPosIntegration struct {
+	BranchID string `query:"branch_id" validate:"required,uuid"`
+}
+
+type PosWebhookLine struct {
+	ProductCode string   `json:"product_code" validate:"required,max=100"`
+	ProductName string   `json:"product_name"`
+	Variant     string   `json:"variant"`
+	Modifiers   []string `json:"modifiers"`
+	Quantity    float64  `json:"quantity" validate:"required"`
+	Price       float64  `json:"price"`
+}
+
+type PosWebhookSale struct {
+	EventID    string           `json:"event_id" validate:"required,max=100"`
+	OccurredAt string           `json:"occurred_at" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
+	Lines      []PosWebhookLine `json:"lines" validate:"required,min=1,dive"`
+}
+
+type QueryPosWebhookEvent struct {
+	Status string `query:"status" validate:"omitempty,oneof=pending processing processed failed"`
+	Since  string `query:"since" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Page   int    `query:"page"`
+	Limit  int    `query:"limit"`
+}
@@ -0,0 +1,30 @@
+package validation
+
+type CreateProductionOrder struct {
+	BranchID    string `json:"branch_id" validate:"required,uuid"`
+	RecipeID    string `json:"recipe_id" validate:"required,uuid"`
+	PlannedDate string `json:"planned_date" validate:"required,datetime=2006-01-02"`
+	ServeCount  int    `json:"serve_count" validate:"required,gt=0"`
+	Note        string `json:"note"`
+	CreatedBy   string `json:"created_by" validate:"omitempty,uuid"`
+}
+
+type ProductionOrderActualLine struct {
+	ItemID         string  `json:"item_id" validate:"required,uuid"`
+	ActualQuantity float64 `json:"actual_quantity" validate:"min=0"`
+}
+
+type CompleteProductionOrder struct {
+	ActualServeCount int                         `json:"actual_serve_count" validate:"omitempty,gt=0"`
+	Lines            []ProductionOrderActualLine `json:"lines" validate:"omitempty,dive"`
+	CompletedBy      string                      `json:"completed_by" validate:"omitempty,uuid"`
+}
+
+type QueryProductionOrder struct {
+	BranchID  string `query:"branch_id" validate:"required,uuid"`
+	Status    string `query:"status" validate:"omitempty,oneof=planned released completed cancelled"`
+	StartDate string `query:"start_date" validate:"omitempty,datetime=2006-01-02"`
+	EndDate   string `query:"end_date" validate:"omitempty,datetime=2006-01-02"`
+	Page      int    `query:"page"`
+	Limit     int    `query:"limit"`
+}
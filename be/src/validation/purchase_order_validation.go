@@ -0,0 +1,36 @@
+package validation
+
+type PurchaseOrderLine struct {
+	ItemID   string  `json:"item_id" validate:"required,uuid"`
+	Quantity float64 `json:"quantity" validate:"required,gt=0"` // in the item's stock unit
+	UnitCost float64 `json:"unit_cost" validate:"gte=0"`
+}
+
+type CreatePurchaseOrder struct {
+	BranchID     string              `json:"branch_id" validate:"required,uuid"`
+	SupplierID   string              `json:"supplier_id" validate:"omitempty,uuid"`
+	OrderDate    string              `json:"order_date" validate:"omitempty,datetime=2006-01-02"` // defaults to today
+	ExpectedDate string              `json:"expected_date" validate:"required,datetime=2006-01-02"`
+	Note         string              `json:"note"`
+	Lines        []PurchaseOrderLine `json:"lines" validate:"required,min=1,dive"`
+}
+
+type ReceivePurchaseOrderLine struct {
+	ItemID   string  `json:"item_id" validate:"required,uuid"`
+	Quantity float64 `json:"quantity" validate:"required,gt=0"`
+}
+
+type ReceivePurchaseOrder struct {
+	// Lines received; without lines everything still open is received.
+	Lines []ReceivePurchaseOrderLine `json:"lines" validate:"omitempty,dive"`
+	Note  string                     `json:"note"`
+}
+
+type QueryPurchaseOrder struct {
+	BranchID  string `query:"branch_id" validate:"required,uuid"`
+	Status    string `query:"status" validate:"omitempty,oneof=ordered partially_received received cancelled"`
+	StartDate string `query:"start_date" validate:"omitempty,datetime=2006-01-02"`
+	EndDate   string `query:"end_date" validate:"omitempty,datetime=2006-01-02"`
+	Page      int    `query:"page"`
+	Limit     int    `query:"limit"`
+}
@@ -13,6 +13,8 @@ type CreateRecipe struct {
     Type        string `json:"type" validate:"required"`
     Description string `json:"description"`
     Instruction string `json:"instruction"`
+    Yield       float64 `json:"yield" validate:"omitempty,gt=0"`
+    SaleDepletionMode string `json:"sale_depletion_mode" validate:"omitempty,oneof=deplete_at_sale pre_cooked"` // empty follows the branch
     CreatedBy   string `json:"created_by" validate:"required,uuid"`
     Ingredients []CreateRecipeIngredient `json:"ingredients" validate:"required,dive"`
 }
@@ -23,15 +25,111 @@ type UpdateRecipe struct {
     Type        string `json:"type" validate:"omitempty"`
     Description string `json:"description" validate:"omitempty"`
     Instruction string `json:"instruction" validate:"omitempty"`
-    Ingredients []CreateRecipeIngredient `json:"ingredients" validate:"omitempty, dive"`
+    Yield       float64 `json:"yield" validate:"omitempty,gt=0"`
+    SaleDepletionMode string `json:"sale_depletion_mode" validate:"omitempty,oneof=inherit deplete_at_sale pre_cooked"` // inherit follows the branch again
+    UpdatedBy   string `json:"updated_by" validate:"omitempty,uuid"`
+    ChangeNote  string `json:"change_note" validate:"omitempty"`
+    Ingredients []CreateRecipeIngredient `json:"ingredients" validate:"omitempty,dive"`
+}
+
+type CloneRecipes struct {
+    SourceBranchID     string   `json:"source_branch_id" validate:"required,uuid"`
+    TargetBranchID     string   `json:"target_branch_id" validate:"required,uuid,nefield=SourceBranchID"`
+    RecipeIDs          []string `json:"recipe_ids" validate:"omitempty,dive,uuid"` // empty clones every recipe
+    CreateMissingItems bool     `json:"create_missing_items"`
+    CreatedBy          string   `json:"created_by" validate:"required,uuid"`
+}
+
+type QueryRecipeExport struct {
+    BranchID string `query:"branch_id" validate:"required,uuid"`
+    Format   string `query:"format" validate:"omitempty,oneof=json csv"`
+}
+
+type ImportRecipes struct {
+    BranchID  string `query:"branch_id" validate:"required,uuid"`
+    Format    string `query:"format" validate:"omitempty,oneof=json csv"` // defaults to the file extension
+    DryRun    bool   `query:"dry_run"`
+    CreatedBy string `query:"created_by" validate:"required,uuid"`
 }
 
 type CookRecipe struct {
     ServeCount int `json:"serve_count" validate:"required,gt=0"`
+    // cook against the stock reserved for a catering order or manual hold
+    ReservationSourceType string `json:"reservation_source_type" validate:"required_with=ReservationSourceID,omitempty,oneof=catering_order manual"`
+    ReservationSourceID   string `json:"reservation_source_id" validate:"required_with=ReservationSourceType,omitempty,uuid"`
+    CookedBy              string `json:"cooked_by" validate:"omitempty,uuid"`
+    // none (default), auto: fall back to substitutes by rank, explicit: only
+    // the substitutes listed in Substitutes
+    Substitution string           `json:"substitution" validate:"omitempty,oneof=none auto explicit"`
+    Substitutes  []CookSubstitute `json:"substitutes" validate:"required_if=Substitution explicit,omitempty,dive"`
+}
+
+type CookSubstitute struct {
+    ItemID           string `json:"item_id" validate:"required,uuid"`
+    SubstituteItemID string `json:"substitute_item_id" validate:"required,uuid"`
+}
+
+type RecipeSubstituteInput struct {
+    ItemID           string  `json:"item_id" validate:"required,uuid"`
+    SubstituteItemID string  `json:"substitute_item_id" validate:"required,uuid,nefield=ItemID"`
+    Rank             int     `json:"rank" validate:"omitempty,min=1"`
+    Ratio            float64 `json:"ratio" validate:"omitempty,gt=0"`
+}
+
+type SetRecipeSubstitutes struct {
+    Substitutes []RecipeSubstituteInput `json:"substitutes" validate:"omitempty,dive"`
+}
+
+type ReverseCookRecord struct {
+    ReversedBy string `json:"reversed_by" validate:"omitempty,uuid"`
+    Reason     string `json:"reason" validate:"required"`
+}
+
+type QueryCookRecord struct {
+    BranchID  string `query:"branch_id" validate:"required,uuid"`
+    RecipeID  string `query:"recipe_id" validate:"omitempty,uuid"`
+    Status    string `query:"status" validate:"omitempty,oneof=cooked reversed"`
+    StartDate string `query:"start_date" validate:"omitempty,datetime=2006-01-02"`
+    EndDate   string `query:"end_date" validate:"omitempty,datetime=2006-01-02"`
+    Page      int    `query:"page"`
+    Limit     int    `query:"limit"`
 }
 
 type QueryRecipe struct {
-    BranchID string `query:"branch_id" validate:"required,uuid"`
-    Page     int    `query:"page"`
-    Limit    int    `query:"limit"`
+    BranchID    string `query:"branch_id" validate:"required,uuid"`
+    Page        int    `query:"page"`
+    Limit       int    `query:"limit"`
+    FreeFrom    string `query:"free_from"`    // comma separated allergens
+    WithDietary bool   `query:"with_dietary"` // roll up the allergens and nutrition of each recipe
+}
+
+type SetRecipePrice struct {
+    BranchID     string  `json:"branch_id" validate:"omitempty,uuid"`
+    SellingPrice float64 `json:"selling_price" validate:"min=0"`
+}
+
+type QueryRecipeCost struct {
+    BranchID string `query:"branch_id" validate:"omitempty,uuid"`
+}
+
+type QueryRecipeCostHistory struct {
+    BranchID  string `query:"branch_id" validate:"omitempty,uuid"`
+    StartDate string `query:"start_date" validate:"omitempty,datetime=2006-01-02"`
+    EndDate   string `query:"end_date" validate:"omitempty,datetime=2006-01-02"`
+}
+
+type RestoreRecipeVersion struct {
+    RestoredBy string `json:"restored_by" validate:"omitempty,uuid"`
+    ChangeNote string `json:"change_note" validate:"omitempty"`
+}
+
+type QueryRecipeVersionDiff struct {
+    From int `query:"from" validate:"required,min=1"`
+    To   int `query:"to" validate:"required,min=1"`
+}
+
+type QueryMaxServings struct {
+    BranchID  string `query:"branch_id" validate:"required,uuid"`
+    RecipeIDs string `query:"recipe_ids"` // comma separated, empty means every recipe in the branch
+    Competing bool   `query:"competing"`
 }
@@ -0,0 +1,15 @@
+package validation
+
+// RecommendationForecastDay is a day of the forecast the dashboard shows.
+type RecommendationForecastDay struct {
+	Date string `json:"date"`
+	Type string `json:"type"` // real or forecast
+}
+
+type IngredientRecommendation struct {
+	BranchID           string                      `json:"branch_id" validate:"omitempty,uuid"`                                // defaults to the user's active branch
+	RecipeCodes        []string                    `json:"recipe_codes"`                                                       // defaults to every recipe
+	Horizon            int                         `json:"horizon" validate:"omitempty,min=1,max=90"`                          // defaults to the forecast days of forecast_data, or 7
+	RecommendationType string                      `json:"recommendation_type" validate:"omitempty,oneof=ingredient_purchase"` // only ingredient_purchase so far
+	ForecastData       []RecommendationForecastDay `json:"forecast_data"`                                                      // only the number of forecast days is used
+}
@@ -0,0 +1,61 @@
+package validation
+
+type ImportQuinosSales struct {
+	BranchID string `query:"branch_id" validate:"required,uuid"`
+	Date     string `query:"date" validate:"required,datetime=2006-01-02"`
+	IsForced bool   `query:"is_forced"` // import the mapped lines and skip unmapped products
+}
+
+type ImportIsellerSales struct {
+	BranchID string `query:"branch_id" validate:"required,uuid"`
+	IsForced bool   `query:"is_forced"` // import the mapped lines and skip unmapped products
+}
+
+type PosProductMappingInput struct {
+	ProductCode string `json:"product_code" validate:"required,max=100"` // "<code> / <variant>" maps a single variant
+	ProductName string `json:"product_name" validate:"omitempty,max=255"`
+	RecipeID    string `json:"recipe_id" validate:"required,uuid"`
+}
+
+type SavePosProductMappings struct {
+	BranchID string                   `json:"branch_id" validate:"required,uuid"`
+	Mappings []PosProductMappingInput `json:"mappings" validate:"required,min=1,dive"`
+}
+
+type QueryPosProductMapping struct {
+	BranchID string `query:"branch_id" validate:"required,uuid"`
+	Search   string `query:"search"`
+	Page     int    `query:"page"`
+	Limit    int    `query:"limit"`
+}
+
+type SalesImportTemplate struct {
+	BranchID          string `json:"branch_id" validate:"required,uuid"`
+	Name              string `json:"name" validate:"required,max=100"`
+	DateColumn        string `json:"date_column" validate:"required,max=100"`
+	ProductCodeColumn string `json:"product_code_column" validate:"required,max=100"`
+	ProductNameColumn string `json:"product_name_column" validate:"omitempty,max=100"`
+	QuantityColumn    string `json:"quantity_column" validate:"required,max=100"`
+	PriceColumn       string `json:"price_column" validate:"omitempty,max=100"`
+	DateFormat        string `json:"date_format" validate:"omitempty,max=50"` // e.g. DD/MM/YYYY
+}
+
+type QuerySalesImportTemplate struct {
+	BranchID string `query:"branch_id" validate:"required,uuid"`
+}
+
+// ImportCSVSales picks a saved template, or names the columns inline. Without
+// either the columns of the sample file are used: date, product_code,
+// product_name, quantity and price.
+type ImportCSVSales struct {
+	BranchID          string `query:"branch_id" form:"branch_id" validate:"required,uuid"`
+	TemplateID        string `query:"template_id" form:"template_id" validate:"omitempty,uuid"`
+	DateColumn        string `query:"date_column" form:"date_column"`
+	ProductCodeColumn string `query:"product_code_column" form:"product_code_column"`
+	ProductNameColumn string `query:"product_name_column" form:"product_name_column"`
+	QuantityColumn    string `query:"quantity_column" form:"quantity_column"`
+	PriceColumn       string `query:"price_column" form:"price_column"`
+	DateFormat        string `query:"date_format" form:"date_format" validate:"omitempty,max=50"`
+	SaveTemplateAs    string `query:"save_template_as" form:"save_template_as" validate:"omitempty,max=100"` // saves the inline columns as a template
+	IsForced          bool   `query:"is_forced" form:"is_forced"`
+}
@@ -0,0 +1,33 @@
+package validation
+
+type CreateSale struct {
+	BranchID string `json:"branch_id" validate:"omitempty,uuid"` // defaults to the recipe's branch
+	RecipeID string `json:"recipe_id" validate:"required,uuid"`
+	Quantity int    `json:"quantity" validate:"required,gt=0"`
+	Date     string `json:"date" validate:"required,datetime=2006-01-02"`
+	Note     string `json:"note"`
+}
+
+type CreateSales struct {
+	Sales []CreateSale `validate:"required,min=1,dive"`
+}
+
+type UpdateSale struct {
+	RecipeID *string `json:"recipe_id" validate:"omitempty,uuid"`
+	Quantity *int    `json:"quantity" validate:"omitempty,gt=0"`
+	Date     *string `json:"date" validate:"omitempty,datetime=2006-01-02"`
+	Note     *string `json:"note"`
+}
+
+type VoidSale struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+type QuerySale struct {
+	BranchID      string `query:"branch_id" validate:"omitempty,uuid"`
+	StartDate     string `query:"start_date" validate:"omitempty,datetime=2006-01-02"`
+	EndDate       string `query:"end_date" validate:"omitempty,datetime=2006-01-02"`
+	IncludeVoided bool   `query:"include_voided"`
+	Page          int    `query:"page"`
+	Limit         int    `query:"limit"`
+}
@@ -0,0 +1,30 @@
+package validation
+
+type StockCountLine struct {
+	ItemID   string   `json:"item_id" validate:"required,uuid"`
+	Quantity *float64 `json:"quantity" validate:"required,gte=0"`
+}
+
+type CreateStockCount struct {
+	BranchID  string           `json:"branch_id" validate:"required,uuid"`
+	CountedBy string           `json:"counted_by" validate:"omitempty,uuid"`
+	Note      string           `json:"note"`
+	Lines     []StockCountLine `json:"lines" validate:"required,min=1,dive"`
+}
+
+type QueryStockCount struct {
+	BranchID  string `query:"branch_id" validate:"required,uuid"`
+	StartDate string `query:"start_date" validate:"omitempty,datetime=2006-01-02"`
+	EndDate   string `query:"end_date" validate:"omitempty,datetime=2006-01-02"`
+	Page      int    `query:"page"`
+	Limit     int    `query:"limit"`
+}
+
+type QueryUsageVariance struct {
+	// BranchID limits the report to one branch; without it items are rolled
+	// up across branches by code.
+	BranchID  string `query:"branch_id" validate:"omitempty,uuid"`
+	StartDate string `query:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate   string `query:"end_date" validate:"required,datetime=2006-01-02"`
+	ItemID    string `query:"item_id" validate:"omitempty,uuid"`
+}
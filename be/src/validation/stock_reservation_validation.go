@@ -0,0 +1,22 @@
+package validation
+
+type CreateStockReservation struct {
+	BranchID   string  `json:"branch_id" validate:"required,uuid"`
+	ItemID     string  `json:"item_id" validate:"required,uuid"`
+	Quantity   float64 `json:"quantity" validate:"required,gt=0"`
+	SourceType string  `json:"source_type" validate:"required,oneof=catering_order manual"`
+	SourceID   string  `json:"source_id" validate:"required_if=SourceType catering_order,omitempty,uuid"`
+	ExpiresAt  string  `json:"expires_at"` // RFC3339, optional
+	Note       string  `json:"note"`
+	CreatedBy  string  `json:"created_by" validate:"omitempty,uuid"`
+}
+
+type QueryStockReservation struct {
+	BranchID   string `query:"branch_id" validate:"required,uuid"`
+	ItemID     string `query:"item_id" validate:"omitempty,uuid"`
+	SourceType string `query:"source_type" validate:"omitempty,oneof=production_order catering_order manual"`
+	SourceID   string `query:"source_id" validate:"omitempty,uuid"`
+	Status     string `query:"status" validate:"omitempty,oneof=active expired consumed released"`
+	Page       int    `query:"page"`
+	Limit      int    `query:"limit"`
+}
@@ -0,0 +1,44 @@
+package validation
+
+type CreateSupplierItem struct {
+	ItemID        string  `json:"item_id" validate:"required,uuid"`
+	Price         float64 `json:"price" validate:"required,gt=0"`       // per purchase unit
+	MOQ           float64 `json:"moq" validate:"gte=0"`                 // in purchase units
+	LeadTime      *int    `json:"lead_time" validate:"omitempty,gte=0"` // days, defaults to the item's lead time
+	PurchaseUnit  string  `json:"purchase_unit" validate:"max=50"`      // defaults to the item's unit
+	PackSize      float64 `json:"pack_size" validate:"omitempty,gt=0"`  // defaults to 1
+	PackUnit      string  `json:"pack_unit" validate:"max=50"`          // defaults to the item's unit
+	Preferred     bool    `json:"preferred"`
+	EffectiveFrom string  `json:"effective_from" validate:"omitempty,datetime=2006-01-02"` // of the price, defaults to today
+}
+
+// UpdateSupplierItem changes the supplier item. A price is added to the
+// price history from effective_from, replacing one set for the same day.
+type UpdateSupplierItem struct {
+	Price         *float64 `json:"price" validate:"omitempty,gt=0"`
+	MOQ           *float64 `json:"moq" validate:"omitempty,gte=0"`
+	LeadTime      *int     `json:"lead_time" validate:"omitempty,gte=0"`
+	PurchaseUnit  *string  `json:"purchase_unit" validate:"omitempty,min=1,max=50"`
+	PackSize      *float64 `json:"pack_size" validate:"omitempty,gt=0"`
+	PackUnit      *string  `json:"pack_unit" validate:"omitempty,min=1,max=50"`
+	Preferred     *bool    `json:"preferred"`
+	EffectiveFrom string   `json:"effective_from" validate:"omitempty,datetime=2006-01-02"` // defaults to today
+}
+
+type QuerySupplierItem struct {
+	Page   int    `query:"page"`
+	Limit  int    `query:"limit"`
+	Search string `query:"search"` // item code or name
+	ItemID string `query:"item_id" validate:"omitempty,uuid"`
+	Date   string `query:"date" validate:"omitempty,datetime=2006-01-02"` // of the prices, defaults to today
+}
+
+type QuerySuppliersForItem struct {
+	Search string `query:"search"`                                        // supplier name
+	Date   string `query:"date" validate:"omitempty,datetime=2006-01-02"` // of the prices, defaults to today
+}
+
+type QueryItemSupplier struct {
+	Date     string `query:"date" validate:"omitempty,datetime=2006-01-02"`          // defaults to today
+	Strategy string `query:"strategy" validate:"omitempty,oneof=preferred cheapest"` // defaults to preferred
+}
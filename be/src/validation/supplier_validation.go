@@ -0,0 +1,23 @@
+package validation
+
+type CreateSupplier struct {
+	Name           string `json:"name" validate:"required,max=255"`
+	Slug           string `json:"slug" validate:"omitempty,max=255,alphanumdash"` // defaults to the name
+	Address        string `json:"address"`
+	WhatsappNumber string `json:"whatsapp_number" validate:"omitempty,phone"`
+	PhoneNumber    string `json:"phone_number" validate:"omitempty,phone"`
+}
+
+type UpdateSupplier struct {
+	Name           *string `json:"name" validate:"omitempty,min=1,max=255"`
+	Slug           *string `json:"slug" validate:"omitempty,max=255,alphanumdash"`
+	Address        *string `json:"address"`
+	WhatsappNumber *string `json:"whatsapp_number" validate:"omitempty,phone"`
+	PhoneNumber    *string `json:"phone_number" validate:"omitempty,phone"`
+}
+
+type QuerySupplier struct {
+	Page   int    `query:"page"`
+	Limit  int    `query:"limit"`
+	Search string `query:"search"` // name, slug or phone numbers
+}
@@ -18,6 +18,7 @@ var customMessages = map[string]string{
 	"alphanum": "Field %s must contain only alphanumeric characters",
 	"oneof":    "Invalid value for field %s",
 	"password": "Field %s must contain at least 1 letter and 1 number",
+	"phone":    "Field %s must be a valid phone number",
 }
 
 func CustomErrorMessages(err error) map[string]string {
@@ -66,6 +67,10 @@ func Validator() *validator.Validate {
 		return nil
 	}
 
+	if err := validate.RegisterValidation("phone", Phone); err != nil {
+		return nil
+	}
+
 	return validate
 }
 
@@ -0,0 +1,63 @@
+package fixture
+
+import (
+	"app/src/model"
+
+	"github.com/google/uuid"
+)
+
+var Branch = &model.Branch{
+	ID:                uuid.New(),
+	Name:              "Test Branch",
+	Slug:              "test-branch",
+	SaleDepletionMode: "pre_cooked",
+}
+
+var Flour = &model.Item{
+	ID:       uuid.New(),
+	BranchID: Branch.ID.String(),
+	Code:     "FLOUR",
+	Name:     "Flour",
+	Type:     "raw_material",
+	Unit:     "kg",
+	Stock:    10,
+}
+
+var Sugar = &model.Item{
+	ID:       uuid.New(),
+	BranchID: Branch.ID.String(),
+	Code:     "SUGAR",
+	Name:     "Sugar",
+	Type:     "raw_material",
+	Unit:     "kg",
+	Stock:    5,
+}
+
+// Cake takes 0.5 kg flour and 0.2 kg sugar per serving.
+var Cake = &model.Recipe{
+	ID:             uuid.New(),
+	BranchID:       Branch.ID.String(),
+	Code:           "CAKE",
+	Name:           "Cake",
+	Type:           "finished",
+	Yield:          1,
+	CurrentVersion: 1,
+	CreatedBy:      Admin.ID.String(),
+}
+
+var CakeIngredients = []*model.RecipeIngredient{
+	{
+		BranchID: Branch.ID.String(),
+		RecipeID: Cake.ID.String(),
+		ItemID:   Flour.ID.String(),
+		Quantity: 0.5,
+		Unit:     "kg",
+	},
+	{
+		BranchID: Branch.ID.String(),
+		RecipeID: Cake.ID.String(),
+		ItemID:   Sugar.ID.String(),
+		Quantity: 0.2,
+		Unit:     "kg",
+	},
+}
@@ -15,9 +15,19 @@ import (
 
 func ClearAll(db *gorm.DB) {
 	ClearToken(db)
+	ClearBranches(db)
 	ClearUsers(db)
 }
 
+// ClearBranches removes the branches and, by cascade, their items, recipes,
+// reservations, cook records, sales and stock movements.
+func ClearBranches(db *gorm.DB) {
+	err := db.Where("id is not null").Delete(&model.Branch{}).Error
+	if err != nil {
+		logrus.Fatalf("Failed clear branch data : %+v", err)
+	}
+}
+
 func ClearUsers(db *gorm.DB) {
 	err := db.Where("id is not null").Delete(&model.User{}).Error
 	if err != nil {
@@ -165,3 +175,78 @@ func GetUserByID(db *gorm.DB, id string) (*model.User, error) {
 
 	return user, result.Error
 }
+
+func InsertBranch(db *gorm.DB, branches ...*model.Branch) {
+	for _, branch := range branches {
+		if err := db.Create(branch).Error; err != nil {
+			logrus.Errorf("Failed to create branch: %+v", err)
+		}
+	}
+}
+
+func InsertItem(db *gorm.DB, items ...*model.Item) {
+	for _, item := range items {
+		if err := db.Create(item).Error; err != nil {
+			logrus.Errorf("Failed to create item: %+v", err)
+		}
+	}
+}
+
+func InsertRecipe(db *gorm.DB, recipe *model.Recipe, ingredients ...*model.RecipeIngredient) {
+	if err := db.Omit("Ingredients").Create(recipe).Error; err != nil {
+		logrus.Errorf("Failed to create recipe: %+v", err)
+		return
+	}
+
+	for _, ingredient := range ingredients {
+		if err := db.Create(ingredient).Error; err != nil {
+			logrus.Errorf("Failed to create recipe ingredient: %+v", err)
+		}
+	}
+}
+
+func SetSaleDepletionMode(db *gorm.DB, branchID, mode string) {
+	err := db.Model(&model.Branch{}).Where("id = ?", branchID).Update("sale_depletion_mode", mode).Error
+	if err != nil {
+		logrus.Errorf("Failed to set sale depletion mode: %+v", err)
+	}
+}
+
+func GetItemByID(db *gorm.DB, id string) (*model.Item, error) {
+	item := new(model.Item)
+
+	result := db.First(item, "id = ?", id)
+
+	if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		logrus.Errorf("Failed get item by id: %+v", result.Error)
+	}
+
+	return item, result.Error
+}
+
+func GetReservationsBySource(db *gorm.DB, sourceType, sourceID string) ([]model.StockReservation, error) {
+	var reservations []model.StockReservation
+
+	result := db.Where("source_type = ? AND source_id = ?", sourceType, sourceID).
+		Order("created_at ASC").
+		Find(&reservations)
+
+	return reservations, result.Error
+}
+
+func GetMovementsByReference(db *gorm.DB, referenceType, referenceID string) ([]model.ItemTransaction, error) {
+	var movements []model.ItemTransaction
+
+	result := db.Where("reference_type = ? AND reference_id = ?", referenceType, referenceID).
+		Order("transaction_date ASC").
+		Find(&movements)
+
+	return movements, result.Error
+}
+
+func SoftDeleteItem(db *gorm.DB, id string) {
+	err := db.Model(&model.Item{}).Where("id = ?", id).Update("deleted_at", time.Now()).Error
+	if err != nil {
+		logrus.Errorf("Failed to delete item: %+v", err)
+	}
+}
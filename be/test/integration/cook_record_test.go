@@ -0,0 +1,133 @@
+package integration
+
+import (
+	"app/src/model"
+	"app/src/service"
+	"app/src/validation"
+	"app/test"
+	"app/test/fixture"
+	"app/test/helper"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCookRecordRoutes(t *testing.T) {
+	cookURL := "/v1/recipes/" + fixture.Cake.ID.String() + "/cook"
+	reverse := validation.ReverseCookRecord{Reason: "cooked by mistake"}
+
+	assertStock := func(t *testing.T, flourStock, sugarStock float64) {
+		flour, err := helper.GetItemByID(test.DB, fixture.Flour.ID.String())
+		assert.Nil(t, err)
+		assert.InDelta(t, flourStock, flour.Stock, 1e-9)
+
+		sugar, err := helper.GetItemByID(test.DB, fixture.Sugar.ID.String())
+		assert.Nil(t, err)
+		assert.InDelta(t, sugarStock, sugar.Stock, 1e-9)
+	}
+
+	t.Run("POST /v1/cook-records/:id/reverse", func(t *testing.T) {
+		t.Run("should return 200 and put the ingredients back", func(t *testing.T) {
+			insertStock()
+
+			cooked := new(service.CookRecipeResponse)
+			apiResponse := sendJSON(t, http.MethodPost, cookURL, validation.CookRecipe{ServeCount: 4}, cooked)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assertStock(t, 8, 4.2)
+
+			record := new(model.CookRecord)
+			apiResponse = sendJSON(t, http.MethodPost, "/v1/cook-records/"+cooked.CookRecordID+"/reverse", reverse, record)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, "reversed", record.Status)
+			assert.Equal(t, reverse.Reason, record.ReverseReason)
+			assertStock(t, 10, 5)
+		})
+
+		t.Run("should return 400 error if the cook is already reversed", func(t *testing.T) {
+			insertStock()
+
+			cooked := new(service.CookRecipeResponse)
+			apiResponse := sendJSON(t, http.MethodPost, cookURL, validation.CookRecipe{ServeCount: 4}, cooked)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			reverseURL := "/v1/cook-records/" + cooked.CookRecordID + "/reverse"
+			apiResponse = sendJSON(t, http.MethodPost, reverseURL, reverse, nil)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			apiResponse = sendJSON(t, http.MethodPost, reverseURL, reverse, nil)
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+			assertStock(t, 10, 5)
+		})
+
+		t.Run("should reserve the stock again for the order it was cooked for", func(t *testing.T) {
+			insertStock()
+			sourceID := uuid.New().String()
+
+			apiResponse := sendJSON(t, http.MethodPost, "/v1/stock-reservations", validation.CreateStockReservation{
+				BranchID:   fixture.Branch.ID.String(),
+				ItemID:     fixture.Flour.ID.String(),
+				Quantity:   3,
+				SourceType: "catering_order",
+				SourceID:   sourceID,
+			}, nil)
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+
+			cooked := new(service.CookRecipeResponse)
+			apiResponse = sendJSON(t, http.MethodPost, cookURL, validation.CookRecipe{
+				ServeCount:            4,
+				ReservationSourceType: "catering_order",
+				ReservationSourceID:   sourceID,
+			}, cooked)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			reservations, err := helper.GetReservationsBySource(test.DB, "catering_order", sourceID)
+			assert.Nil(t, err)
+			assert.Len(t, reservations, 1)
+			assert.Equal(t, 1.0, reservations[0].Quantity)
+
+			apiResponse = sendJSON(t, http.MethodPost, "/v1/cook-records/"+cooked.CookRecordID+"/reverse", reverse, nil)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			reservations, err = helper.GetReservationsBySource(test.DB, "catering_order", sourceID)
+			assert.Nil(t, err)
+			assert.Len(t, reservations, 1)
+			assert.Equal(t, "active", reservations[0].Status)
+			assert.Equal(t, 3.0, reservations[0].Quantity)
+			assertStock(t, 10, 5)
+		})
+
+		t.Run("should follow a fully consumed reservation with a new one", func(t *testing.T) {
+			insertStock()
+			sourceID := uuid.New().String()
+
+			apiResponse := sendJSON(t, http.MethodPost, "/v1/stock-reservations", validation.CreateStockReservation{
+				BranchID:   fixture.Branch.ID.String(),
+				ItemID:     fixture.Flour.ID.String(),
+				Quantity:   2,
+				SourceType: "catering_order",
+				SourceID:   sourceID,
+			}, nil)
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+
+			cooked := new(service.CookRecipeResponse)
+			apiResponse = sendJSON(t, http.MethodPost, cookURL, validation.CookRecipe{
+				ServeCount:            4,
+				ReservationSourceType: "catering_order",
+				ReservationSourceID:   sourceID,
+			}, cooked)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			apiResponse = sendJSON(t, http.MethodPost, "/v1/cook-records/"+cooked.CookRecordID+"/reverse", reverse, nil)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			reservations, err := helper.GetReservationsBySource(test.DB, "catering_order", sourceID)
+			assert.Nil(t, err)
+			assert.Len(t, reservations, 2)
+			assert.Equal(t, "consumed", reservations[0].Status)
+			assert.Equal(t, "active", reservations[1].Status)
+			assert.Equal(t, 2.0, reservations[1].Quantity)
+		})
+	})
+}
@@ -0,0 +1,137 @@
+package integration
+
+import (
+	"app/src/model"
+	"app/src/validation"
+	"app/test"
+	"app/test/fixture"
+	"app/test/helper"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaleDepletionRoutes(t *testing.T) {
+	sellCake := func(quantity int) validation.CreateSale {
+		return validation.CreateSale{
+			RecipeID: fixture.Cake.ID.String(),
+			Quantity: quantity,
+			Date:     "2026-10-18",
+		}
+	}
+
+	assertStock := func(t *testing.T, flourStock, sugarStock float64) {
+		flour, err := helper.GetItemByID(test.DB, fixture.Flour.ID.String())
+		assert.Nil(t, err)
+		assert.InDelta(t, flourStock, flour.Stock, 1e-9)
+
+		sugar, err := helper.GetItemByID(test.DB, fixture.Sugar.ID.String())
+		assert.Nil(t, err)
+		assert.InDelta(t, sugarStock, sugar.Stock, 1e-9)
+	}
+
+	createSale := func(t *testing.T, quantity int) *model.Sale {
+		var sales []model.Sale
+		apiResponse := sendJSON(t, http.MethodPost, "/v1/sales", sellCake(quantity), &sales)
+		assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+		assert.Len(t, sales, 1)
+		if len(sales) == 0 {
+			t.FailNow()
+		}
+
+		return &sales[0]
+	}
+
+	t.Run("POST /v1/sales", func(t *testing.T) {
+		t.Run("should not deplete a pre-cooked recipe", func(t *testing.T) {
+			insertStock()
+
+			sale := createSale(t, 4)
+			assert.False(t, sale.Depleted)
+			assertStock(t, 10, 5)
+		})
+
+		t.Run("should deplete the ingredients when the branch depletes at sale", func(t *testing.T) {
+			insertStock()
+			helper.SetSaleDepletionMode(test.DB, fixture.Branch.ID.String(), "deplete_at_sale")
+
+			sale := createSale(t, 4)
+			assert.True(t, sale.Depleted)
+			assert.Empty(t, sale.DepletionNote)
+			assertStock(t, 8, 4.2)
+
+			movements, err := helper.GetMovementsByReference(test.DB, "sale", sale.ID.String())
+			assert.Nil(t, err)
+			assert.Len(t, movements, 2)
+		})
+
+		t.Run("should deplete what it can and note a deleted ingredient", func(t *testing.T) {
+			insertStock()
+			helper.SetSaleDepletionMode(test.DB, fixture.Branch.ID.String(), "deplete_at_sale")
+			helper.SoftDeleteItem(test.DB, fixture.Sugar.ID.String())
+
+			sale := createSale(t, 4)
+			assert.True(t, sale.Depleted)
+			assert.Contains(t, sale.DepletionNote, fixture.Sugar.ID.String())
+			assertStock(t, 8, 5)
+		})
+	})
+
+	t.Run("PUT /v1/sales/:id", func(t *testing.T) {
+		t.Run("should deplete the new quantity instead of the old one", func(t *testing.T) {
+			insertStock()
+			helper.SetSaleDepletionMode(test.DB, fixture.Branch.ID.String(), "deplete_at_sale")
+
+			sale := createSale(t, 4)
+			quantity := 2
+
+			updated := new(model.Sale)
+			apiResponse := sendJSON(t, http.MethodPut, "/v1/sales/"+sale.ID.String(), validation.UpdateSale{Quantity: &quantity}, updated)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, 2, updated.Quantity)
+			assert.True(t, updated.Depleted)
+			assertStock(t, 9, 4.6)
+		})
+	})
+
+	t.Run("POST /v1/sales/:id/void", func(t *testing.T) {
+		t.Run("should put back the ingredients of the sale", func(t *testing.T) {
+			insertStock()
+			helper.SetSaleDepletionMode(test.DB, fixture.Branch.ID.String(), "deplete_at_sale")
+
+			sale := createSale(t, 4)
+
+			voided := new(model.Sale)
+			apiResponse := sendJSON(t, http.MethodPost, "/v1/sales/"+sale.ID.String()+"/void", validation.VoidSale{Reason: "wrong order"}, voided)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, "void", voided.Status)
+			assert.False(t, voided.Depleted)
+			assertStock(t, 10, 5)
+		})
+
+		t.Run("should put back an edited sale only once", func(t *testing.T) {
+			insertStock()
+			helper.SetSaleDepletionMode(test.DB, fixture.Branch.ID.String(), "deplete_at_sale")
+
+			sale := createSale(t, 4)
+			for _, quantity := range []int{6, 2} {
+				quantity := quantity
+				apiResponse := sendJSON(t, http.MethodPut, "/v1/sales/"+sale.ID.String(), validation.UpdateSale{Quantity: &quantity}, nil)
+				assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			}
+			assertStock(t, 9, 4.6)
+
+			apiResponse := sendJSON(t, http.MethodPost, "/v1/sales/"+sale.ID.String()+"/void", validation.VoidSale{Reason: "wrong order"}, nil)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assertStock(t, 10, 5)
+		})
+
+		t.Run("should return 404 error if the sale does not exist", func(t *testing.T) {
+			insertStock()
+
+			apiResponse := sendJSON(t, http.MethodPost, "/v1/sales/"+fixture.Cake.ID.String()+"/void", validation.VoidSale{Reason: "wrong order"}, nil)
+			assert.Equal(t, http.StatusNotFound, apiResponse.StatusCode)
+		})
+	})
+}
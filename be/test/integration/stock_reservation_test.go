@@ -0,0 +1,174 @@
+package integration
+
+import (
+	"app/src/model"
+	"app/src/validation"
+	"app/test"
+	"app/test/fixture"
+	"app/test/helper"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// sendJSON sends the body as JSON to the route and decodes the "data" field
+// of the response into data, when given.
+func sendJSON(t *testing.T, method, target string, body interface{}, data interface{}) *http.Response {
+	bodyJSON, err := json.Marshal(body)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(method, target, strings.NewReader(string(bodyJSON)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+
+	apiResponse, err := test.App.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(apiResponse.Body)
+	assert.Nil(t, err)
+
+	if data != nil && apiResponse.StatusCode < http.StatusBadRequest {
+		err = json.Unmarshal(bytes, &struct {
+			Data interface{} `json:"data"`
+		}{Data: data})
+		assert.Nil(t, err)
+	}
+
+	return apiResponse
+}
+
+func insertStock() {
+	helper.ClearAll(test.DB)
+	helper.InsertUser(test.DB, fixture.Admin)
+	helper.InsertBranch(test.DB, fixture.Branch)
+	helper.InsertItem(test.DB, fixture.Flour, fixture.Sugar)
+	helper.InsertRecipe(test.DB, fixture.Cake, fixture.CakeIngredients...)
+}
+
+func TestStockReservationRoutes(t *testing.T) {
+	sourceID := uuid.New().String()
+	reserveFlour := func(quantity float64) validation.CreateStockReservation {
+		return validation.CreateStockReservation{
+			BranchID:   fixture.Branch.ID.String(),
+			ItemID:     fixture.Flour.ID.String(),
+			Quantity:   quantity,
+			SourceType: "catering_order",
+			SourceID:   sourceID,
+		}
+	}
+	takeFlour := func(amount float64, reservationID string) validation.CreateItemTransaction {
+		return validation.CreateItemTransaction{
+			ItemID:        fixture.Flour.ID,
+			BranchID:      fixture.Branch.ID.String(),
+			Type:          "out",
+			Amount:        amount,
+			ReservationID: reservationID,
+		}
+	}
+	takeFlourURL := "/v1/items/" + fixture.Flour.ID.String() + "/transactions"
+
+	t.Run("POST /v1/stock-reservations", func(t *testing.T) {
+		t.Run("should return 201 and hold the stock from other movements", func(t *testing.T) {
+			insertStock()
+
+			reservation := new(model.StockReservation)
+			apiResponse := sendJSON(t, http.MethodPost, "/v1/stock-reservations", reserveFlour(8), reservation)
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+			assert.Equal(t, "active", reservation.Status)
+			assert.Equal(t, 8.0, reservation.Quantity)
+
+			apiResponse = sendJSON(t, http.MethodPost, takeFlourURL, takeFlour(5, ""), nil)
+			assert.NotEqual(t, http.StatusCreated, apiResponse.StatusCode)
+
+			flour, err := helper.GetItemByID(test.DB, fixture.Flour.ID.String())
+			assert.Nil(t, err)
+			assert.Equal(t, 10.0, flour.Stock)
+		})
+
+		t.Run("should return 400 error if the stock is already reserved", func(t *testing.T) {
+			insertStock()
+
+			apiResponse := sendJSON(t, http.MethodPost, "/v1/stock-reservations", reserveFlour(8), nil)
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+
+			apiResponse = sendJSON(t, http.MethodPost, "/v1/stock-reservations", reserveFlour(3), nil)
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+
+			reservations, err := helper.GetReservationsBySource(test.DB, "catering_order", sourceID)
+			assert.Nil(t, err)
+			assert.Len(t, reservations, 1)
+		})
+
+		t.Run("should return 404 error if the item is not in the branch", func(t *testing.T) {
+			insertStock()
+
+			request := reserveFlour(1)
+			request.ItemID = uuid.New().String()
+
+			apiResponse := sendJSON(t, http.MethodPost, "/v1/stock-reservations", request, nil)
+			assert.Equal(t, http.StatusNotFound, apiResponse.StatusCode)
+		})
+	})
+
+	t.Run("POST /v1/items/:item_id/transactions", func(t *testing.T) {
+		t.Run("should draw an out movement on its reservation", func(t *testing.T) {
+			insertStock()
+
+			reservation := new(model.StockReservation)
+			apiResponse := sendJSON(t, http.MethodPost, "/v1/stock-reservations", reserveFlour(8), reservation)
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+
+			apiResponse = sendJSON(t, http.MethodPost, takeFlourURL, takeFlour(5, reservation.ID.String()), nil)
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+
+			flour, err := helper.GetItemByID(test.DB, fixture.Flour.ID.String())
+			assert.Nil(t, err)
+			assert.Equal(t, 5.0, flour.Stock)
+
+			reservations, err := helper.GetReservationsBySource(test.DB, "catering_order", sourceID)
+			assert.Nil(t, err)
+			assert.Len(t, reservations, 1)
+			assert.Equal(t, "active", reservations[0].Status)
+			assert.Equal(t, 3.0, reservations[0].Quantity)
+		})
+	})
+
+	t.Run("POST /v1/stock-reservations/:id/release", func(t *testing.T) {
+		t.Run("should return 200 and free the stock", func(t *testing.T) {
+			insertStock()
+
+			reservation := new(model.StockReservation)
+			apiResponse := sendJSON(t, http.MethodPost, "/v1/stock-reservations", reserveFlour(8), reservation)
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+
+			released := new(model.StockReservation)
+			apiResponse = sendJSON(t, http.MethodPost, "/v1/stock-reservations/"+reservation.ID.String()+"/release", nil, released)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, "released", released.Status)
+
+			apiResponse = sendJSON(t, http.MethodPost, takeFlourURL, takeFlour(9, ""), nil)
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 400 error if the reservation is already released", func(t *testing.T) {
+			insertStock()
+
+			reservation := new(model.StockReservation)
+			apiResponse := sendJSON(t, http.MethodPost, "/v1/stock-reservations", reserveFlour(8), reservation)
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+
+			releaseURL := "/v1/stock-reservations/" + reservation.ID.String() + "/release"
+			apiResponse = sendJSON(t, http.MethodPost, releaseURL, nil, nil)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			apiResponse = sendJSON(t, http.MethodPost, releaseURL, nil, nil)
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+		})
+	})
+}
@@ -0,0 +1,53 @@
+package model_test
+
+import (
+	"app/src/validation"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSupplierModel(t *testing.T) {
+	t.Run("Create supplier validation", func(t *testing.T) {
+		newSupplier := validation.CreateSupplier{
+			Name:           "PT Beras Nusantara",
+			Slug:           "pt-beras-nusantara",
+			WhatsappNumber: "+62 812-3456-7890",
+			PhoneNumber:    "(021) 555 1234",
+		}
+
+		t.Run("should correctly validate a valid supplier", func(t *testing.T) {
+			err := validate.Struct(newSupplier)
+			assert.NoError(t, err)
+		})
+
+		t.Run("should correctly validate a supplier without phone numbers", func(t *testing.T) {
+			supplier := newSupplier
+			supplier.WhatsappNumber = ""
+			supplier.PhoneNumber = ""
+			err := validate.Struct(supplier)
+			assert.NoError(t, err)
+		})
+
+		t.Run("should throw a validation error if a phone number has letters", func(t *testing.T) {
+			supplier := newSupplier
+			supplier.PhoneNumber = "0812-ABCD-7890"
+			err := validate.Struct(supplier)
+			assert.Error(t, err)
+		})
+
+		t.Run("should throw a validation error if a phone number is too short", func(t *testing.T) {
+			supplier := newSupplier
+			supplier.WhatsappNumber = "12345"
+			err := validate.Struct(supplier)
+			assert.Error(t, err)
+		})
+
+		t.Run("should throw a validation error if slug has spaces", func(t *testing.T) {
+			supplier := newSupplier
+			supplier.Slug = "pt beras"
+			err := validate.Struct(supplier)
+			assert.Error(t, err)
+		})
+	})
+}
@@ -0,0 +1,112 @@
+package utils_test
+
+import (
+	"app/src/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMovingAverage(t *testing.T) {
+	values := utils.MovingAverage([]float64{100, 1, 2, 3}, 3, 2)
+	assert.Equal(t, []float64{2, 2}, values)
+
+	values = utils.MovingAverage([]float64{4, 6}, 7, 1)
+	assert.Equal(t, []float64{5}, values)
+
+	assert.Equal(t, []float64{0, 0}, utils.MovingAverage(nil, 7, 2))
+}
+
+func TestExponentialSmoothing(t *testing.T) {
+	values := utils.ExponentialSmoothing([]float64{10, 20}, 0.5, 3)
+	assert.Len(t, values, 3)
+	for _, v := range values {
+		assert.InDelta(t, 15, v, 1e-9)
+	}
+}
+
+func TestHoltWinters(t *testing.T) {
+	week := []float64{10, 12, 14, 16, 30, 40, 20}
+	var history []float64
+	for i := 0; i < 4; i++ {
+		history = append(history, week...)
+	}
+
+	t.Run("should repeat a stable weekly pattern", func(t *testing.T) {
+		values := utils.HoltWinters(history, 0.3, 0.1, 0.3, 7, 7)
+		assert.Len(t, values, 7)
+		for i, v := range values {
+			assert.InDelta(t, week[i], v, 1e-6)
+		}
+	})
+
+	t.Run("should fall back to exponential smoothing on a short history", func(t *testing.T) {
+		values, model, err := utils.Forecast(utils.ForecastHoltWinters, week, 3, utils.ForecastParams{})
+		assert.NoError(t, err)
+		assert.Equal(t, utils.ForecastExponentialSmoothing, model)
+		assert.Len(t, values, 3)
+	})
+}
+
+func TestForecast(t *testing.T) {
+	t.Run("should never forecast negative sales", func(t *testing.T) {
+		history := []float64{40, 35, 30, 25, 20, 15, 10, 9, 6, 4, 3, 1, 0, 0}
+		values, model, err := utils.Forecast(utils.ForecastHoltWinters, history, 14, utils.ForecastParams{})
+		assert.NoError(t, err)
+		assert.Equal(t, utils.ForecastHoltWinters, model)
+		for _, v := range values {
+			assert.GreaterOrEqual(t, v, 0.0)
+		}
+	})
+
+	t.Run("should throw an error on an unknown model", func(t *testing.T) {
+		_, _, err := utils.Forecast("arima", []float64{1}, 1, utils.ForecastParams{})
+		assert.Error(t, err)
+	})
+}
+
+func TestMeasureForecast(t *testing.T) {
+	accuracy := utils.MeasureForecast([]float64{12, 8, 3, 0}, []float64{10, 10, 0, 0})
+	assert.Equal(t, 4, accuracy.Points)
+	assert.InDelta(t, 7.0/4, accuracy.MAE, 1e-9)
+	assert.InDelta(t, 3.0/4, accuracy.Bias, 1e-9)
+	assert.InDelta(t, 75, accuracy.HitRate, 1e-9)
+	assert.NotNil(t, accuracy.MAPE)
+	assert.InDelta(t, 20, *accuracy.MAPE, 1e-9)
+
+	assert.Nil(t, utils.MeasureForecast([]float64{1}, []float64{0}).MAPE)
+}
+
+func TestBacktest(t *testing.T) {
+	week := []float64{10, 12, 14, 16, 30, 40, 20}
+	var history []float64
+	for i := 0; i < 6; i++ {
+		history = append(history, week...)
+	}
+
+	seasonal, err := utils.Backtest(utils.ForecastHoltWinters, history, 7, 3, utils.ForecastParams{})
+	assert.NoError(t, err)
+	assert.Equal(t, 21, seasonal.Points)
+
+	flat, err := utils.Backtest(utils.ForecastMovingAverage, history, 7, 3, utils.ForecastParams{})
+	assert.NoError(t, err)
+	assert.Less(t, *seasonal.MAPE, *flat.MAPE)
+
+	short, err := utils.Backtest(utils.ForecastMovingAverage, week, 7, 3, utils.ForecastParams{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, short.Points)
+}
+
+func TestCalendarEffects(t *testing.T) {
+	days := []utils.CalendarDay{{}, {Multiplier: 2}, {Closed: true}, {Multiplier: 0.5}}
+
+	t.Run("should normalise event and closed days", func(t *testing.T) {
+		adjusted := utils.RemoveCalendarEffects([]float64{10, 40, 0, 5, 7}, days)
+		assert.Equal(t, []float64{10, 20, 15, 10, 7}, adjusted)
+	})
+
+	t.Run("should put the events back in", func(t *testing.T) {
+		adjusted := utils.ApplyCalendarEffects([]float64{10, 10, 10, 10, 10}, days)
+		assert.Equal(t, []float64{10, 20, 0, 5, 10}, adjusted)
+	})
+}
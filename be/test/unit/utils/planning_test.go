@@ -0,0 +1,154 @@
+package utils_test
+
+import (
+	"app/src/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetRequirements(t *testing.T) {
+	tests := []struct {
+		name      string
+		available float64
+		gross     []float64
+		receipts  []float64
+		shortfall []float64
+		projected []float64
+	}{
+		{
+			name:      "should draw the available stock down without a shortfall",
+			available: 10,
+			gross:     []float64{3, 3, 3},
+			receipts:  []float64{0, 0, 0},
+			shortfall: []float64{0, 0, 0},
+			projected: []float64{7, 4, 1},
+		},
+		{
+			name:      "should plan only what is missing and start again from zero",
+			available: 5,
+			gross:     []float64{3, 3, 3},
+			receipts:  []float64{0, 0, 0},
+			shortfall: []float64{0, 1, 3},
+			projected: []float64{2, 0, 0},
+		},
+		{
+			name:      "should count scheduled receipts on the day they arrive",
+			available: 0,
+			gross:     []float64{2, 2, 2},
+			receipts:  []float64{0, 5, 0},
+			shortfall: []float64{2, 0, 0},
+			projected: []float64{0, 3, 1},
+		},
+		{
+			name:      "should treat negative available stock as already short",
+			available: -4,
+			gross:     []float64{1, 0},
+			receipts:  []float64{0, 0},
+			shortfall: []float64{5, 0},
+			projected: []float64{0, 0},
+		},
+		{
+			name:      "should accept missing receipts",
+			available: 1,
+			gross:     []float64{2},
+			shortfall: []float64{1},
+			projected: []float64{0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shortfall, projected := utils.NetRequirements(tt.available, tt.gross, tt.receipts)
+			assert.InDeltaSlice(t, tt.shortfall, shortfall, 1e-9)
+			assert.InDeltaSlice(t, tt.projected, projected, 1e-9)
+		})
+	}
+}
+
+func TestOrderDay(t *testing.T) {
+	tests := []struct {
+		name     string
+		needDay  int
+		leadTime int
+		day      int
+		late     bool
+	}{
+		{name: "should order the lead time before", needDay: 5, leadTime: 2, day: 3},
+		{name: "should order today when the lead time ends today", needDay: 2, leadTime: 2, day: 0},
+		{name: "should order today and be late when the lead time has passed", needDay: 1, leadTime: 3, day: 0, late: true},
+		{name: "should order on the need day without a lead time", needDay: 4, day: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			day, late := utils.OrderDay(tt.needDay, tt.leadTime)
+			assert.Equal(t, tt.day, day)
+			assert.Equal(t, tt.late, late)
+		})
+	}
+}
+
+func TestOrderUrgency(t *testing.T) {
+	tests := []struct {
+		name           string
+		late           bool
+		daysUntilOrder int
+		urgency        string
+	}{
+		{name: "should be critical when late", late: true, urgency: utils.UrgencyCritical},
+		{name: "should be high when ordering today", daysUntilOrder: 0, urgency: utils.UrgencyHigh},
+		{name: "should be medium within three days", daysUntilOrder: 3, urgency: utils.UrgencyMedium},
+		{name: "should be low after three days", daysUntilOrder: 4, urgency: utils.UrgencyLow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.urgency, utils.OrderUrgency(tt.late, tt.daysUntilOrder))
+		})
+	}
+}
+
+func TestChooseSupplier(t *testing.T) {
+	price := func(value float64) *float64 { return &value }
+
+	tests := []struct {
+		name     string
+		offers   []utils.SupplierOffer
+		cheapest bool
+		chosen   int
+	}{
+		{
+			name:   "should choose the preferred supplier over a cheaper one",
+			offers: []utils.SupplierOffer{{StockPrice: price(8)}, {Preferred: true, StockPrice: price(10)}},
+			chosen: 1,
+		},
+		{
+			name:     "should choose the cheapest supplier when asked to",
+			offers:   []utils.SupplierOffer{{Preferred: true, StockPrice: price(10)}, {StockPrice: price(8)}},
+			cheapest: true,
+			chosen:   1,
+		},
+		{
+			name:   "should pass over a preferred supplier without a price",
+			offers: []utils.SupplierOffer{{Preferred: true}, {StockPrice: price(12)}, {StockPrice: price(9)}},
+			chosen: 2,
+		},
+		{
+			name:   "should keep the first of equally cheap suppliers",
+			offers: []utils.SupplierOffer{{StockPrice: price(5)}, {StockPrice: price(5)}},
+			chosen: 0,
+		},
+		{
+			name:   "should choose none without prices",
+			offers: []utils.SupplierOffer{{Preferred: true}, {}},
+			chosen: -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.chosen, utils.ChooseSupplier(tt.offers, tt.cheapest))
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package utils_test
+
+import (
+	"app/src/utils"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNumber(t *testing.T) {
+	cases := map[string]float64{
+		"12":           12,
+		"12.50":        12.5,
+		"12,5":         12.5,
+		"1,234":        1234,
+		"1,234.50":     1234.5,
+		"1.234,50":     1234.5,
+		"Rp 1.500.000": 1500000,
+		"(3)":          -3,
+		"":             0,
+	}
+
+	for input, expected := range cases {
+		number, err := utils.ParseNumber(input)
+		assert.NoError(t, err, input)
+		assert.InDelta(t, expected, number, 1e-9, input)
+	}
+
+	_, err := utils.ParseNumber("abc")
+	assert.Error(t, err)
+}
+
+func TestParseQuinosSales(t *testing.T) {
+	t.Run("should skip the preamble and total rows and add up products", func(t *testing.T) {
+		export := "Item Sales Report\n" +
+			"Outlet,Sarana Kemang\n" +
+			"\n" +
+			"Code,Item Name,Category,Qty,Net Sales\n" +
+			"NOO.01,Mala Noodle Soup (Beef),Noodle,11,\"605,000\"\n" +
+			"NOO.02,Mala Noodle Soup (Chicken),Noodle,5,\"250,000\"\n" +
+			",Sub Total Noodle,,16,\"855,000\"\n" +
+			"NOO.01,Mala Noodle Soup (Beef),Noodle,1,\"55,000\"\n" +
+			"Grand Total,,,17,\"910,000\"\n"
+
+		lines, err := utils.ParseQuinosSales(strings.NewReader(export))
+		assert.NoError(t, err)
+		assert.Len(t, lines, 2)
+		assert.Equal(t, "NOO.01", lines[0].ProductCode)
+		assert.Equal(t, "Mala Noodle Soup (Beef)", lines[0].ProductName)
+		assert.InDelta(t, 12, lines[0].Quantity, 1e-9)
+		assert.InDelta(t, 660000, lines[0].Price, 1e-9)
+		assert.InDelta(t, 5, lines[1].Quantity, 1e-9)
+	})
+
+	t.Run("should read semicolon separated exports", func(t *testing.T) {
+		lines, err := utils.ParseQuinosSales(strings.NewReader("\xef\xbb\xbfKode;Nama;Jumlah\nMOD.02;ICE CUBE;1\n"))
+		assert.NoError(t, err)
+		assert.Len(t, lines, 1)
+		assert.Equal(t, "MOD.02", lines[0].ProductCode)
+	})
+
+	t.Run("should throw an error without a header row", func(t *testing.T) {
+		_, err := utils.ParseQuinosSales(strings.NewReader("a,b,c\n1,2,3\n"))
+		assert.Error(t, err)
+	})
+}
+
+func TestParseIsellerSales(t *testing.T) {
+	export := "Order Date,Order No,SKU,Product Name,Variant,Modifiers,Quantity,Total\n" +
+		"01/04/2025 12:10,INV-001,NOO.01,Mala Noodle Soup,Large,\"Extra Egg x2, Less Spicy\",1,\"65,000\"\n" +
+		"01/04/2025 12:10,INV-001,NOO.01,Mala Noodle Soup,Large,,1,\"65,000\"\n" +
+		"02/04/2025 09:00,INV-002,MOD.02,Ice Cube,,,3,0\n"
+
+	t.Run("should read days, variants and modifiers", func(t *testing.T) {
+		lines, err := utils.ParseIsellerSales(strings.NewReader(export))
+		assert.NoError(t, err)
+		assert.Len(t, lines, 5)
+
+		assert.Equal(t, "2025-04-01", lines[0].Date.Format("2006-01-02"))
+		assert.Equal(t, "NOO.01 / Large", lines[0].VariantCode())
+		assert.False(t, lines[0].Optional)
+
+		assert.Equal(t, "Extra Egg", lines[1].ProductCode)
+		assert.InDelta(t, 2, lines[1].Quantity, 1e-9)
+		assert.True(t, lines[1].Optional)
+		assert.Equal(t, "Less Spicy", lines[2].ProductCode)
+
+		assert.Equal(t, "2025-04-02", lines[4].Date.Format("2006-01-02"))
+		assert.Equal(t, "MOD.02", lines[4].VariantCode())
+	})
+
+	t.Run("should give the same references to the same rows", func(t *testing.T) {
+		first, err := utils.ParseIsellerSales(strings.NewReader(export))
+		assert.NoError(t, err)
+		second, err := utils.ParseIsellerSales(strings.NewReader(export))
+		assert.NoError(t, err)
+
+		refs := map[string]bool{}
+		for i := range first {
+			assert.Equal(t, first[i].ExternalRef, second[i].ExternalRef)
+			refs[first[i].ExternalRef] = true
+		}
+		assert.Len(t, refs, len(first))
+	})
+
+	t.Run("should throw an error on an invalid date", func(t *testing.T) {
+		_, err := utils.ParseIsellerSales(strings.NewReader("Date,SKU,Qty\nyesterday,NOO.01,1\n"))
+		assert.Error(t, err)
+	})
+}
+
+func TestDateLayout(t *testing.T) {
+	cases := map[string]string{
+		"YYYY-MM-DD":       "2006-01-02",
+		"DD/MM/YYYY":       "02/01/2006",
+		"D MMM YYYY":       "2 Jan 2006",
+		"DD-MM-YY HH:mm":   "02-01-06 15:04",
+		"MM/DD/YYYY hh:mm": "01/02/2006 03:04",
+	}
+
+	for format, expected := range cases {
+		layout, err := utils.DateLayout(format)
+		assert.NoError(t, err, format)
+		assert.Equal(t, expected, layout, format)
+	}
+
+	_, err := utils.DateLayout("tomorrow")
+	assert.Error(t, err)
+}
+
+func TestParseMappedSales(t *testing.T) {
+	mapping := utils.SalesColumnMapping{
+		DateColumn:        "Tanggal",
+		ProductCodeColumn: "Kode",
+		QuantityColumn:    "Qty",
+		PriceColumn:       "Harga",
+		DateFormat:        "DD/MM/YYYY",
+	}
+
+	t.Run("should read the mapped columns", func(t *testing.T) {
+		lines, rowErrors, err := utils.ParseMappedSales(strings.NewReader("Kode,Qty,Harga,Tanggal\nNOO.01,4,\"20,000\",01/04/2025\n,,,\n"), mapping)
+		assert.NoError(t, err)
+		assert.Empty(t, rowErrors)
+		assert.Len(t, lines, 1)
+		assert.Equal(t, "2025-04-01", lines[0].Date.Format("2006-01-02"))
+		assert.InDelta(t, 4, lines[0].Quantity, 1e-9)
+		assert.InDelta(t, 20000, lines[0].Price, 1e-9)
+	})
+
+	t.Run("should report every invalid row", func(t *testing.T) {
+		lines, rowErrors, err := utils.ParseMappedSales(strings.NewReader("Tanggal,Kode,Qty\n2025-04-01,NOO.01,4\n01/04/2025,,x\n"), mapping)
+		assert.NoError(t, err)
+		assert.Nil(t, lines)
+		assert.Len(t, rowErrors, 3)
+		assert.Equal(t, 2, rowErrors[0].Line)
+		assert.Equal(t, "Tanggal", rowErrors[0].Field)
+	})
+
+	t.Run("should throw an error when a column is missing", func(t *testing.T) {
+		_, _, err := utils.ParseMappedSales(strings.NewReader("date,code,quantity\n"), mapping)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,88 @@
+package utils_test
+
+import (
+	"app/src/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertUnit(t *testing.T) {
+	t.Run("should return the same quantity for the same unit", func(t *testing.T) {
+		qty, err := utils.ConvertUnit(2.5, "KG", "kg")
+		assert.NoError(t, err)
+		assert.InDelta(t, 2.5, qty, 1e-9)
+	})
+
+	t.Run("should convert between mass units", func(t *testing.T) {
+		qty, err := utils.ConvertUnit(250, "g", "kg")
+		assert.NoError(t, err)
+		assert.InDelta(t, 0.25, qty, 1e-9)
+	})
+
+	t.Run("should convert between volume units", func(t *testing.T) {
+		qty, err := utils.ConvertUnit(1.5, "liter", "ml")
+		assert.NoError(t, err)
+		assert.InDelta(t, 1500, qty, 1e-9)
+	})
+
+	t.Run("should throw an error when dimensions differ", func(t *testing.T) {
+		_, err := utils.ConvertUnit(1, "kg", "ml")
+		assert.Error(t, err)
+	})
+
+	t.Run("should throw an error for unknown units", func(t *testing.T) {
+		_, err := utils.ConvertUnit(1, "box", "pcs")
+		assert.Error(t, err)
+	})
+}
+
+func TestIsKnownUnit(t *testing.T) {
+	t.Run("should know the convertible units whatever their case", func(t *testing.T) {
+		assert.True(t, utils.IsKnownUnit(" Kg"))
+		assert.True(t, utils.IsKnownUnit("pcs"))
+	})
+
+	t.Run("should not know other units", func(t *testing.T) {
+		assert.False(t, utils.IsKnownUnit("box"))
+		assert.False(t, utils.IsKnownUnit(""))
+	})
+}
+
+func TestToBaseUnit(t *testing.T) {
+	t.Run("should convert to the base unit of the dimension", func(t *testing.T) {
+		assert.InDelta(t, 1500, utils.ToBaseUnit(1.5, "kg"), 1e-9)
+		assert.InDelta(t, 250, utils.ToBaseUnit(0.25, "L"), 1e-9)
+		assert.InDelta(t, 24, utils.ToBaseUnit(2, "dozen"), 1e-9)
+	})
+
+	t.Run("should keep unknown units as they are", func(t *testing.T) {
+		assert.InDelta(t, 3, utils.ToBaseUnit(3, "box"), 1e-9)
+	})
+}
+
+func TestNormalizeAllergens(t *testing.T) {
+	t.Run("should lowercase, de-duplicate and sort", func(t *testing.T) {
+		assert.Equal(t, []string{"gluten", "milk"}, utils.NormalizeAllergens([]string{" Milk", "gluten", "MILK", ""}))
+	})
+
+	t.Run("should parse the item column", func(t *testing.T) {
+		assert.Equal(t, []string{"egg", "peanut"}, utils.SplitAllergens("peanut, egg"))
+		assert.Equal(t, []string{}, utils.SplitAllergens(""))
+	})
+}
+
+func TestPurchaseUnits(t *testing.T) {
+	t.Run("should round up to whole purchase units", func(t *testing.T) {
+		assert.InDelta(t, 3, utils.PurchaseUnits(60000, 25000, 0), 1e-9)
+		assert.InDelta(t, 2, utils.PurchaseUnits(50000, 25000, 0), 1e-9)
+	})
+
+	t.Run("should order at least the minimum order quantity", func(t *testing.T) {
+		assert.InDelta(t, 5, utils.PurchaseUnits(1000, 25000, 5), 1e-9)
+	})
+
+	t.Run("should return zero without a pack size", func(t *testing.T) {
+		assert.InDelta(t, 0, utils.PurchaseUnits(1000, 0, 5), 1e-9)
+	})
+}
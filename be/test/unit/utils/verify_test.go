@@ -0,0 +1,25 @@
+package utils_test
+
+import (
+	"app/src/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyPayload(t *testing.T) {
+	body := []byte(`{"event_id":"evt-1"}`)
+	signature := utils.SignPayload("secret", "1760781600", body)
+
+	t.Run("should accept a valid signature with or without prefix", func(t *testing.T) {
+		assert.True(t, utils.VerifyPayload("secret", "1760781600", body, signature))
+		assert.True(t, utils.VerifyPayload("secret", "1760781600", body, "sha256="+signature))
+	})
+
+	t.Run("should reject a tampered payload, timestamp or secret", func(t *testing.T) {
+		assert.False(t, utils.VerifyPayload("secret", "1760781600", []byte(`{"event_id":"evt-2"}`), signature))
+		assert.False(t, utils.VerifyPayload("secret", "1760781601", body, signature))
+		assert.False(t, utils.VerifyPayload("other", "1760781600", body, signature))
+		assert.False(t, utils.VerifyPayload("secret", "1760781600", body, ""))
+	})
+}